@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/deploy"
+	"sbi-deployment/internal/utils"
+)
+
+// runForceRollback rolls back a release and confirms its health during an incident
+func runForceRollback(profile string, configFiles []string, args []string) {
+	cfg, err := config.ParseConfig(profile, configFiles...)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	fs := flag.NewFlagSet("force-rollback", flag.ExitOnError)
+	release := fs.String("release", cfg.ReleaseName, "Release to roll back")
+	namespace := fs.String("namespace", cfg.Namespace, "Namespace of the release")
+	revision := fs.Int("revision", 0, "Revision to roll back to (default: previous revision)")
+	fs.Parse(args)
+
+	if *release == "" {
+		log.Fatalf("force-rollback requires --release or RELEASE_NAME in config")
+	}
+	cfg.Namespace = *namespace
+	if err := cfg.DefaultNamespace(); err != nil {
+		log.Fatalf("force-rollback: %v; pass --namespace", err)
+	}
+	if !utils.IsDNSLabel(cfg.Namespace) {
+		log.Fatalf("force-rollback requires a concrete namespace, got %q; pass --namespace", cfg.Namespace)
+	}
+
+	deployer := deploy.New(cfg, false)
+	current, err := deployer.ForceRollback(*release, cfg.Namespace, *revision)
+	if err != nil {
+		log.Fatalf("Force rollback failed: %v", err)
+	}
+
+	fmt.Printf("Release %s is healthy at revision %d\n", *release, current)
+}
+
+// runDoctor checks the local environment and exits non-zero if any critical check fails
+func runDoctor(profile string, configFiles []string) {
+	cfg, err := config.ParseConfig(profile, configFiles...)
+	if err != nil {
+		fmt.Printf("✗ configuration (critical): %v\n", err)
+		fmt.Println("    hint: create the config file or pass --config")
+		os.Exit(1)
+	}
+
+	if !deploy.New(cfg, false).Doctor(os.Stdout) {
+		os.Exit(1)
+	}
+}
+
+// runRollbackAll rolls back every release in a namespace and reports per-release results
+func runRollbackAll(profile string, configFiles []string, args []string) {
+	cfg, err := config.ParseConfig(profile, configFiles...)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	fs := flag.NewFlagSet("rollback-all", flag.ExitOnError)
+	namespace := fs.String("namespace", cfg.Namespace, "Namespace whose releases are rolled back")
+	fs.Parse(args)
+
+	cfg.Namespace = *namespace
+	if err := cfg.DefaultNamespace(); err != nil {
+		log.Fatalf("rollback-all: %v; pass --namespace", err)
+	}
+	if !utils.IsDNSLabel(cfg.Namespace) {
+		log.Fatalf("rollback-all requires a concrete namespace, got %q; pass --namespace", cfg.Namespace)
+	}
+
+	results, err := deploy.New(cfg, false).RollbackAll(cfg.Namespace)
+	if err != nil {
+		log.Fatalf("Rollback failed: %v", err)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			fmt.Printf("✗ %s: %v\n", r.Release, r.Err)
+		} else {
+			fmt.Printf("✓ %s: now at revision %d\n", r.Release, r.Revision)
+		}
+	}
+	fmt.Printf("Rolled back %d of %d releases in %s\n", len(results)-failed, len(results), cfg.Namespace)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// runConfigDiff prints a field-by-field diff between two config files with secrets redacted
+func runConfigDiff(profile string, args []string) {
+	if len(args) != 2 {
+		log.Fatalf("usage: sbi-deploy config-diff <old.conf> <new.conf>")
+	}
+
+	oldCfg, err := config.ParseConfig(profile, args[0])
+	if err != nil {
+		log.Fatalf("Failed to load %s: %v", args[0], err)
+	}
+	newCfg, err := config.ParseConfig(profile, args[1])
+	if err != nil {
+		log.Fatalf("Failed to load %s: %v", args[1], err)
+	}
+
+	diffs := config.Diff(oldCfg, newCfg)
+	if len(diffs) == 0 {
+		fmt.Println("No configuration differences")
+		return
+	}
+	for _, d := range diffs {
+		fmt.Printf("%s: %q -> %q\n", d.Name, d.Old, d.New)
+	}
+}
+
+// runConfigChecksum records the checksum of the reviewed configuration next to the config file
+func runConfigChecksum(profile string, configFiles []string) {
+	cfg, err := config.LoadConfig(profile, configFiles...)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	path := config.ChecksumPath(configFiles[len(configFiles)-1])
+	if err := cfg.WriteChecksum(path); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Recorded config checksum %s in %s\n", cfg.Checksum(), path)
+}
+
+// runInit writes a commented configuration template
+func runInit(configFile string, args []string) {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	force := fs.Bool("force", false, "Overwrite an existing config file")
+	fs.Parse(args)
+
+	path := configFile
+	if fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+
+	if err := config.WriteTemplate(path, *force); err != nil {
+		log.Fatalf("Init failed: %v", err)
+	}
+	fmt.Printf("Wrote configuration template to %s\n", path)
+}
+
+// runCanary deploys a reduced-size canary release and optionally promotes it to the main release
+func runCanary(deployer *deploy.Deployer, imageTag, imageName string, credentials *config.Credentials, args []string) {
+	fs := flag.NewFlagSet("canary", flag.ExitOnError)
+	replicas := fs.Int("replicas", 1, "replicaCount of the <release>-canary release")
+	weight := fs.Int("canary-weight", 0, "Traffic percentage passed as canary.weight for charts with weighted canaries (0 = unset)")
+	promote := fs.Bool("promote", false, "Deploy the main release with the same tag once the canary is healthy")
+	fs.Parse(args)
+
+	summary, err := deployer.Canary(imageTag, imageName, credentials, deploy.CanaryOptions{
+		Replicas: *replicas,
+		Weight:   *weight,
+		Promote:  *promote,
+	})
+	if err != nil {
+		log.Fatalf("Canary failed: %v", err)
+	}
+	fmt.Printf("Release %s deployed at tag %s\n", summary.ReleaseName, summary.TargetTag)
+}
+
+// runBlueGreen deploys the inactive color and switches the blue/green Service to it
+func runBlueGreen(deployer *deploy.Deployer, imageTag, imageName string, credentials *config.Credentials) {
+	summary, err := deployer.BlueGreen(imageTag, imageName, credentials)
+	if err != nil {
+		log.Fatalf("Blue/green deploy failed: %v", err)
+	}
+	fmt.Printf("Release %s deployed at tag %s\n", summary.ReleaseName, summary.TargetTag)
+}
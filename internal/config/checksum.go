@@ -0,0 +1,64 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrChecksumMismatch means the configuration changed since its checksum was last recorded
+var ErrChecksumMismatch = errors.New("configuration does not match its recorded checksum")
+
+// ChecksumPath returns where the reviewed checksum of configFile is stored, e.g. deployment.conf.sha256
+func ChecksumPath(configFile string) string {
+	return configFile + ".sha256"
+}
+
+// Checksum returns the SHA-256 of the effective configuration, independent of which files it was
+// merged from. Secrets are hashed redacted, so rotating them is not reported as drift.
+func (cfg *Config) Checksum() string {
+	hash := sha256.New()
+	for _, f := range cfg.Fields() {
+		if f.Name == "ConfigFile" || f.Name == "ConfigFiles" {
+			continue
+		}
+		fmt.Fprintf(hash, "%s=%s\n", f.Name, f.Value)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// WriteChecksum records the configuration's checksum at path
+func (cfg *Config) WriteChecksum(path string) error {
+	if err := os.WriteFile(path, []byte(cfg.Checksum()+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write config checksum: %w", err)
+	}
+	return nil
+}
+
+// VerifyChecksum compares the configuration with the checksum recorded at path; found is
+// false when nothing has been recorded yet
+func (cfg *Config) VerifyChecksum(path string) (found bool, err error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read config checksum: %w", err)
+	}
+	recorded := strings.TrimSpace(string(data))
+	if actual := cfg.Checksum(); actual != recorded {
+		return true, fmt.Errorf("%w %s (%s, recorded %s); review the change and run 'sbi-deploy config-checksum'", ErrChecksumMismatch, path, actual[:12], shortChecksum(recorded))
+	}
+	return true, nil
+}
+
+// shortChecksum abbreviates a checksum for messages, tolerating a malformed file
+func shortChecksum(sum string) string {
+	if len(sum) > 12 {
+		return sum[:12]
+	}
+	return sum
+}
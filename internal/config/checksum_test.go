@@ -0,0 +1,53 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChecksum(t *testing.T) {
+	a := &Config{ConfigFile: "a.conf", Namespace: "prod", ServerSecret: "hunter1"}
+	b := &Config{ConfigFile: "b.conf", ConfigFiles: []string{"base.conf", "b.conf"}, Namespace: "prod", ServerSecret: "hunter2"}
+	if a.Checksum() != b.Checksum() {
+		t.Errorf("Checksum() differs for the same settings from other files or with a rotated secret")
+	}
+	b.Namespace = "staging"
+	if a.Checksum() == b.Checksum() {
+		t.Errorf("Checksum() is unchanged after NAMESPACE changed")
+	}
+	if sum := a.Checksum(); len(sum) != 64 {
+		t.Errorf("Checksum() = %q, want a hex SHA-256", sum)
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deployment.conf.sha256")
+	cfg := &Config{Namespace: "prod"}
+	if found, err := cfg.VerifyChecksum(path); found || err != nil {
+		t.Errorf("VerifyChecksum(unrecorded) = %v, %v, want not found", found, err)
+	}
+	if err := cfg.WriteChecksum(path); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := cfg.VerifyChecksum(path); !found || err != nil {
+		t.Errorf("VerifyChecksum(recorded) = %v, %v", found, err)
+	}
+	cfg.Namespace = "staging"
+	if _, err := cfg.VerifyChecksum(path); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("VerifyChecksum(drifted) error = %v, want ErrChecksumMismatch", err)
+	}
+	if err := os.WriteFile(path, []byte("abc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.VerifyChecksum(path); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("VerifyChecksum(malformed) error = %v, want ErrChecksumMismatch", err)
+	}
+}
+
+func TestChecksumPath(t *testing.T) {
+	if got := ChecksumPath("deploy/deployment.conf"); got != "deploy/deployment.conf.sha256" {
+		t.Errorf("ChecksumPath() = %q", got)
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ComposeImages returns the image of each service in a Docker Compose file, keyed by service.
+// Only the block-style services.<name>.image layout is read; services without an image
+// (build-only) are left out.
+func ComposeImages(data []byte) map[string]string {
+	images := map[string]string{}
+	inServices := false
+	serviceIndent, fieldIndent := -1, -1
+	service := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		key, value, _ := strings.Cut(trimmed, ":")
+		key = strings.Trim(key, `"'`)
+
+		if indent == 0 {
+			inServices = key == "services"
+			serviceIndent, fieldIndent, service = -1, -1, ""
+			continue
+		}
+		if !inServices {
+			continue
+		}
+		if serviceIndent == -1 {
+			serviceIndent = indent
+		}
+		switch {
+		case indent == serviceIndent:
+			service, fieldIndent = key, -1
+		case service != "":
+			// Only the service's own image key counts, not e.g. a nested environment value
+			if fieldIndent == -1 {
+				fieldIndent = indent
+			}
+			if indent == fieldIndent && key == "image" {
+				images[service] = strings.Trim(strings.TrimSpace(value), `"'`)
+			}
+		}
+	}
+	return images
+}
+
+// ComposeImage picks the image of service from a compose file; with no service given the
+// file must define exactly one image
+func ComposeImage(data []byte, service string) (string, error) {
+	images := ComposeImages(data)
+	if service != "" {
+		image, ok := images[service]
+		if !ok {
+			return "", fmt.Errorf("compose service %q not found or has no image", service)
+		}
+		return image, nil
+	}
+	if len(images) != 1 {
+		services := make([]string, 0, len(images))
+		for name := range images {
+			services = append(services, name)
+		}
+		sort.Strings(services)
+		return "", fmt.Errorf("compose file has %d services with images (%s); set COMPOSE_SERVICE", len(images), strings.Join(services, ", "))
+	}
+	for _, image := range images {
+		return image, nil
+	}
+	return "", nil
+}
+
+// SplitComposeImage splits nexus.example.com/team/web:1.0 into its registry
+// (nexus.example.com/team) and image name (web); the tag is dropped in favor of --tag
+func SplitComposeImage(image string) (registry, name string) {
+	if at := strings.Index(image, "@"); at >= 0 {
+		image = image[:at]
+	}
+	slash := strings.LastIndex(image, "/")
+	if colon := strings.LastIndex(image, ":"); colon > slash {
+		image = image[:colon]
+	}
+	if slash < 0 {
+		return "", image
+	}
+	return image[:slash], image[slash+1:]
+}
+
+// applyComposeFile fills NEXUS_REGISTRY (when unset) and the image name from COMPOSE_FILE
+func (c *Config) applyComposeFile() error {
+	data, err := os.ReadFile(c.ComposeFile)
+	if err != nil {
+		return fmt.Errorf("failed to read compose file: %w", err)
+	}
+	image, err := ComposeImage(data, c.ComposeService)
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.ComposeFile, err)
+	}
+	registry, name := SplitComposeImage(image)
+	if c.NexusRegistry == "" {
+		if registry == "" {
+			return fmt.Errorf("%s: image %q names no registry; set NEXUS_REGISTRY", c.ComposeFile, image)
+		}
+		c.NexusRegistry = registry
+	}
+	c.ImageName = name
+	return nil
+}
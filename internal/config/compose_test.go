@@ -0,0 +1,80 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const composeFile = `version: "3.8"
+services:
+  web:
+    image: "nexus.example.com/team/web:1.0"
+    environment:
+      image: not-this-one
+  migrate:
+    build: .
+  worker:
+    image: nexus.example.com/team/worker@sha256:abc
+volumes:
+  data:
+    image: ignored
+`
+
+func TestComposeImages(t *testing.T) {
+	want := map[string]string{"web": "nexus.example.com/team/web:1.0", "worker": "nexus.example.com/team/worker@sha256:abc"}
+	if got := ComposeImages([]byte(composeFile)); !maps.Equal(got, want) {
+		t.Errorf("ComposeImages() = %v, want %v", got, want)
+	}
+}
+
+func TestComposeImage(t *testing.T) {
+	if image, err := ComposeImage([]byte(composeFile), "worker"); err != nil || image != "nexus.example.com/team/worker@sha256:abc" {
+		t.Errorf("ComposeImage(worker) = %q, %v", image, err)
+	}
+	if _, err := ComposeImage([]byte(composeFile), "migrate"); err == nil {
+		t.Errorf("ComposeImage(migrate) passed for a build-only service")
+	}
+	if _, err := ComposeImage([]byte(composeFile), ""); err == nil || !strings.Contains(err.Error(), "(web, worker); set COMPOSE_SERVICE") {
+		t.Errorf("ComposeImage() error = %v, want the services listed", err)
+	}
+	single := "services:\n  web:\n    image: web:1.0\n"
+	if image, err := ComposeImage([]byte(single), ""); err != nil || image != "web:1.0" {
+		t.Errorf("ComposeImage(single) = %q, %v", image, err)
+	}
+}
+
+func TestSplitComposeImage(t *testing.T) {
+	tests := []struct{ image, registry, name string }{
+		{"nexus.example.com/team/web:1.0", "nexus.example.com/team", "web"},
+		{"nexus.example.com:8443/web@sha256:abc", "nexus.example.com:8443", "web"},
+		{"nexus.example.com:8443/web", "nexus.example.com:8443", "web"},
+		{"web:1.0", "", "web"},
+	}
+	for _, tt := range tests {
+		if registry, name := SplitComposeImage(tt.image); registry != tt.registry || name != tt.name {
+			t.Errorf("SplitComposeImage(%q) = %q, %q, want %q, %q", tt.image, registry, name, tt.registry, tt.name)
+		}
+	}
+}
+
+func TestComposeFileFillsImageAndRegistry(t *testing.T) {
+	compose := filepath.Join(t.TempDir(), "docker-compose.yml")
+	if err := os.WriteFile(compose, []byte(composeFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig("", writeConfig(t, "HARBOR_REGISTRY=harbor.example.com\nHELM_CHART_PATH=./chart\nNAMESPACE=dev\nCOMPOSE_FILE="+compose+"\nCOMPOSE_SERVICE=web\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ImageName != "web" || cfg.NexusRegistry != "nexus.example.com/team" {
+		t.Errorf("image = %q, registry = %q, want web from nexus.example.com/team", cfg.ImageName, cfg.NexusRegistry)
+	}
+
+	cfg, err = loadConfig(t, "COMPOSE_FILE="+compose+"\nCOMPOSE_SERVICE=worker\n")
+	if err != nil || cfg.NexusRegistry != "nexus.example.com" || cfg.ImageName != "worker" {
+		t.Errorf("NEXUS_REGISTRY = %q, image = %q, %v; want the configured registry kept", cfg.NexusRegistry, cfg.ImageName, err)
+	}
+}
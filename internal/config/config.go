@@ -3,24 +3,265 @@ package config
 import (
 	"bufio"
 	"fmt"
+	"math"
+	"net"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
+
+	"sbi-deployment/internal/logging"
+	"sbi-deployment/internal/notify"
 )
 
 // Config represents the deployment configuration
 type Config struct {
-	NexusRegistry   string
-	HarborRegistry  string
-	HelmChartPath   string
-	ReleaseName     string
-	Namespace       string
-	Timeout         int
-	EnableRollback  bool
-	EnableCleanup   bool
-	ImageName       string
+	NexusRegistry  string
+	HarborRegistry string
+	// DR mirrors that receive every pushed image too; helm deploys from HARBOR_REGISTRY
+	HarborRegistries []string
+	HelmChartPath    string
+	ReleaseName      string
+	Namespace        string
+	Timeout          int
+	Wait             bool
+	EnableRollback   bool
+	EnableCleanup    bool
+	ImageName        string
+	ChartVersion     string
+	ServerSecret     string
+	ImageTagKey      string
+	ImageDigestKey   string
+	KubeCLI          string
+
+	// Run helm with --debug and log its output at debug level
+	HelmDebug bool
+
+	// Skip cluster TLS verification for helm and kubectl (dev only); refused for ConfirmNamespaces
+	SkipTLSVerify     bool
+	ConfirmNamespaces []string
+
+	// Kubeconfig files of every cluster the release is rolled out to, at most MaxParallelClusters at a time
+	Kubeconfigs         []string
+	MaxParallelClusters int
+
+	// Text expected in successful rollout status output; the exit code is what decides
+	RolloutSuccessText string
+
+	// Run kubectl rollout restart after a healthy deploy, for config changes that don't roll pods
+	RolloutRestart bool
+
+	// host:port dependencies that must accept TCP connections before deploying
+	WaitForTCP        []string
+	WaitForTCPTimeout int
+
+	// Registries (nexus, harbor) to prompt credentials for; derived from the sync mode when empty
+	RequiredCredentials []string
+
+	// Let helm create the release namespace (--create-namespace), or fail when it is missing
+	HelmCreateNamespace      bool
+	RequireExistingNamespace bool
+	// Fail instead of falling back to the default namespace when NAMESPACE is unset
+	RequireNamespace bool
+
+	// Build the image locally from BuildContext instead of pulling it from Nexus
+	BuildContext string
+	Dockerfile   string
+	BuildArgs    map[string]string
+
+	// Write the current and new release manifests to ManifestDir before upgrading
+	CaptureManifests bool
+	ManifestDir      string
+
+	// Skip the deploy (and optionally the sync) when the live release already runs the tag
+	SkipUnchanged     bool
+	SkipUnchangedSync bool
+
+	// Append a record of each deploy to this file (.md for a Markdown table, JSON lines otherwise)
+	DeployLogFile string
+
+	// Chat notification after each deploy; NotifyFormat is slack, teams, mattermost, or raw
+	NotifyWebhookURL string
+	NotifyFormat     string
+
+	// GPG-encrypted credentials file decrypted in memory; GPG_PASSPHRASE or gpg-agent unlocks it
+	CredentialsGPGFile string
+
+	// Vault credential source; the token is read from VAULT_TOKEN
+	VaultAddr       string
+	VaultSecretPath string
+
+	// kubernetes.io/dockerconfigjson secret holding the Harbor login (namespace defaults to NAMESPACE)
+	HarborPullSecret          string
+	HarborPullSecretNamespace string
+
+	// Chart/image drift check
+	CheckChartImage       bool
+	StrictChartImageMatch bool
+
+	// Release label (RELEASE_OWNER_LABEL, default owner) that must equal EXPECTED_RELEASE_OWNER before an upgrade
+	ReleaseOwnerLabel    string
+	ExpectedReleaseOwner string
+
+	// Environment variable to annotation mappings recorded on the release's deployment after each upgrade
+	CIAnnotations map[string]string
+
+	// Run helm lint with the deploy's values before the upgrade
+	LintChart bool
+	// Print a kubectl diff of the rendered chart against the cluster before the upgrade
+	KubectlDiff bool
+
+	// kubernetes.io/dockerconfigjson secret created from the Harbor credentials and set as imagePullSecrets[0].name
+	CreatePullSecret string
+
+	// <configmap>/<key> in the release namespace holding base values passed to helm with --values
+	ValuesConfigMap string
+
+	// Script whose stdout is a YAML values document passed to helm with --values
+	ValuesScript string
+
+	// Also push the image with a UTC deploy-time tag rendered from TIMESTAMP_TAG_FORMAT (a Go time layout)
+	TimestampTag       bool
+	TimestampTagFormat string
+
+	// Docker Compose file whose services.<COMPOSE_SERVICE>.image supplies ImageName and,
+	// when unset, NEXUS_REGISTRY
+	ComposeFile    string
+	ComposeService string
+
+	// Platform (os/arch, e.g. linux/amd64) passed as --platform when pulling and building
+	DockerPlatform string
+
+	// Image label (e.g. org.opencontainers.image.version) that must equal the deployed tag
+	TagLabel string
+
+	// Pin the deploy to the pushed digest: "digest" sets IMAGE_DIGEST_KEY, "tag" sets the tag to <tag>@<digest>
+	DeployByDigest string
+
+	// Also set <tag key parent>.repository to the Harbor target repository on deploy
+	SetImageRepository bool
+	CleanFailedPods    bool
+	RunHelmTest        bool
+
+	// Run `helm dependency build` for local charts that declare subcharts
+	BuildDependencies bool
+
+	// StatsD endpoint (host:port) for deploy duration and success/failure counters
+	StatsDAddr   string
+	StatsDPrefix string
+	StatsDTags   bool
+
+	// HTTP probe run after the rollout check; fails the deploy unless the expected status
+	// (and body substring, when set) is seen before SmokeTestTimeout seconds
+	SmokeTestURL          string
+	SmokeTestExpectStatus int
+	SmokeTestExpectBody   string
+	SmokeTestTimeout      int
+
+	// Service switched between <release>-blue and <release>-green by the blue-green subcommand
+	BlueGreenService     string
+	BlueGreenSelectorKey string
+
+	// Run independent pre-flight checks concurrently
+	ParallelPreflight bool
+
+	// Pull remote charts while the image sync runs
+	ParallelPhases bool
+
+	// Safety guard: abort unless kubectl targets this context and/or cluster server URL
+	ExpectedContext string
+	ExpectedCluster string
+
+	// Extra helm --set values; command line values take precedence over HELM_SET
+	SetValues map[string]string
+
+	// Explicit image name to chart path overrides, consulted before deriving the chart path
+	ImageChartMap map[string]string
+
+	// Roll back releases stuck in a pending-install/upgrade/rollback state before deploying
+	AutoRecoverPending bool
+
+	// Force-delete stuck pods and retry once when a failure rollback outlasts ROLLBACK_GRACE_PERIOD (seconds)
+	ForceRollbackRecovery bool
+	RollbackGracePeriod   int
+
+	// Proxy settings injected into spawned docker, helm, kubectl, and curl commands and used for registry API calls
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+
+	// Per-attempt timeout (seconds) and retries for the helm/kubectl downloads in --setup
+	DownloadTimeout int
+	DownloadRetries int
+
+	// Image sync options
+	SkipSync             bool
+	TagSuffix            string
+	VerifyTargetImage    bool
+	PreserveManifestList bool
+	ContainerRuntime     string
+	SyncRetries          int
+	SyncTimeout          int
+	ScanBeforePush       bool
+	ScannerCommand       string
+	ScanSeverity         string
+	SignImage            bool
+	CosignKey            string
+
+	// Paths the configuration was merged from, in order; ConfigFile is the last of them
+	ConfigFile  string
+	ConfigFiles []string
+
+	// Treat unknown keys as errors; carries over to files merged later
+	StrictConfig bool
+
+	// Fail instead of warning when the config differs from its recorded <config>.sha256
+	StrictConfigChecksum bool
+
+	// Selected [profile] section (set from the command line)
+	Profile string
+
+	// Log how each derived value was computed (set from the command line)
+	Explain bool
+
+	// Target environment name such as dev or prod (set from the command line)
+	Environment string
+
+	// Audit log destination; when set, commands are recorded instead of executed
+	AuditFile string
+
+	// File that receives the pushed image digest (set from the command line)
+	DigestOutFile string
+
+	// File that receives DEPLOYED_*=value lines after a deploy (set from the command line)
+	OutputEnvFile string
+
+	// Stream the release's pod logs for this long after a healthy rollout (set from the command line)
+	WatchLogs time.Duration
+
+	// Post-deploy health watch (set from the command line)
+	WatchWindow   time.Duration
+	WatchInterval time.Duration
 }
 
+// valuePathPattern matches a dotted helm value path such as app.image.tag
+var valuePathPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+// tagPattern matches a valid image tag
+var tagPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
+// platformPattern matches an os/arch[/variant] platform such as linux/arm64/v8
+var platformPattern = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9_]+(/[a-z0-9]+)?$`)
+
+// annotationKeyPattern matches a Kubernetes annotation key with an optional DNS prefix
+var annotationKeyPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9.-]*[a-z0-9])?/)?[A-Za-z0-9]([A-Za-z0-9._-]{0,61}[A-Za-z0-9])?$`)
+
+// resourceNamePattern matches a Kubernetes object name (DNS-1123 subdomain)
+var resourceNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]{0,251}[a-z0-9])?$`)
+
 // Credentials holds registry authentication information
 type Credentials struct {
 	NexusUsername  string
@@ -29,13 +270,122 @@ type Credentials struct {
 	HarborPassword string
 }
 
-// LoadConfig reads configuration from the deployment.conf file
-func LoadConfig(configFile string) (*Config, error) {
+// HarborMirrors returns the HARBOR_REGISTRIES entries other than the primary HARBOR_REGISTRY
+func (cfg *Config) HarborMirrors() []string {
+	var mirrors []string
+	seen := map[string]bool{cfg.HarborRegistry: true}
+	for _, registry := range cfg.HarborRegistries {
+		if !seen[registry] {
+			seen[registry] = true
+			mirrors = append(mirrors, registry)
+		}
+	}
+	return mirrors
+}
+
+// DefaultNamespace makes the fallback to the default namespace explicit instead of passing an
+// empty namespace to helm and kubectl; REQUIRE_NAMESPACE turns a missing NAMESPACE into an error.
+// Call it once command line overrides of the namespace have been applied.
+func (cfg *Config) DefaultNamespace() error {
+	if cfg.Namespace != "" {
+		return nil
+	}
+	if cfg.RequireNamespace {
+		return fmt.Errorf("NAMESPACE is required (REQUIRE_NAMESPACE is set)")
+	}
+	logging.Warnf("NAMESPACE is not set, deploying to the default namespace")
+	cfg.Namespace = "default"
+	return nil
+}
+
+// LoadConfig reads and validates configuration from one or more deployment.conf files,
+// later files overriding keys set by earlier ones. A non-empty profile applies the matching
+// [profile] sections over the top-level keys.
+func LoadConfig(profile string, configFiles ...string) (*Config, error) {
+	cfg, err := ParseConfig(profile, configFiles...)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ParseConfig reads and merges configuration without validating it, for commands that only need a few settings
+func ParseConfig(profile string, configFiles ...string) (*Config, error) {
+	if len(configFiles) == 0 {
+		return nil, fmt.Errorf("no config file given")
+	}
+
 	cfg := &Config{
 		Timeout:        300,
+		Wait:           true,
 		EnableRollback: true,
 		EnableCleanup:  true,
+		ImageTagKey:    "image.tag",
+		ImageDigestKey: "image.digest",
+		SyncRetries:    2,
+
+		ParallelPreflight: true,
+		VerifyTargetImage: true,
+		Profile:           profile,
+
+		RolloutSuccessText: "successfully rolled out",
+		WaitForTCPTimeout:  60,
+
+		RollbackGracePeriod: 120,
+
+		CIAnnotations: map[string]string{
+			"CI_RUN_URL":    "sbi-deployment/ci-run-url",
+			"GITHUB_RUN_ID": "sbi-deployment/github-run-id",
+			"BUILD_URL":     "sbi-deployment/build-url",
+		},
+
+		MaxParallelClusters: 1,
+
+		BlueGreenSelectorKey: "app.kubernetes.io/instance",
+
+		StatsDPrefix: "sbi",
+
+		SmokeTestExpectStatus: 200,
+		SmokeTestTimeout:      60,
+
+		DownloadTimeout: 300,
+		DownloadRetries: 2,
+
+		ReleaseOwnerLabel: "owner",
+
+		TimestampTagFormat: "deployed-20060102-1504",
+	}
+	found := false
+	for _, configFile := range configFiles {
+		sections, err := cfg.parseFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		found = found || sections[profile]
+	}
+	if profile != "" && !found {
+		return nil, fmt.Errorf("profile [%s] not found in %s", profile, strings.Join(configFiles, ", "))
+	}
+	// Without HARBOR_REGISTRY the first HARBOR_REGISTRIES entry is the primary
+	if cfg.HarborRegistry == "" && len(cfg.HarborRegistries) > 0 {
+		cfg.HarborRegistry = cfg.HarborRegistries[0]
 	}
+	if cfg.ComposeFile != "" {
+		if err := cfg.applyComposeFile(); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// parseFile applies the top-level keys of one config file and those of the [cfg.Profile]
+// section on top of cfg, returning the names of the sections it contains
+func (cfg *Config) parseFile(configFile string) (map[string]bool, error) {
+	cfg.ConfigFile = configFile
+	cfg.ConfigFiles = append(cfg.ConfigFiles, configFile)
 
 	file, err := os.Open(configFile)
 	if err != nil {
@@ -43,6 +393,10 @@ func LoadConfig(configFile string) (*Config, error) {
 	}
 	defer file.Close()
 
+	var unknownKeys []string
+	sections := map[string]bool{}
+	section := ""
+
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -50,25 +404,50 @@ func LoadConfig(configFile string) (*Config, error) {
 			continue
 		}
 
+		// [name] starts a profile section; keys of other profiles are skipped
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			sections[section] = true
+			continue
+		}
+		if section != "" && section != cfg.Profile {
+			continue
+		}
+
 		parts := strings.SplitN(line, "=", 2)
 		if len(parts) != 2 {
 			continue
 		}
 
 		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+		value := expandEnv(key, strings.TrimSpace(parts[1]))
 
 		switch key {
 		case "NEXUS_REGISTRY":
 			cfg.NexusRegistry = value
 		case "HARBOR_REGISTRY":
 			cfg.HarborRegistry = value
+		case "HARBOR_REGISTRIES":
+			cfg.HarborRegistries = nil
+			for _, registry := range strings.Split(value, ",") {
+				if registry = strings.TrimSpace(registry); registry != "" {
+					cfg.HarborRegistries = append(cfg.HarborRegistries, registry)
+				}
+			}
+		case "COMPOSE_FILE":
+			cfg.ComposeFile = value
+		case "COMPOSE_SERVICE":
+			cfg.ComposeService = value
 		case "HELM_CHART_PATH":
 			cfg.HelmChartPath = value
+		case "CHART_VERSION":
+			cfg.ChartVersion = value
 		case "RELEASE_NAME":
 			cfg.ReleaseName = value
 		case "NAMESPACE":
 			cfg.Namespace = value
+		case "WAIT":
+			cfg.Wait = strings.ToLower(value) == "true"
 		case "TIMEOUT":
 			if timeout, err := strconv.Atoi(value); err == nil {
 				cfg.Timeout = timeout
@@ -77,6 +456,243 @@ func LoadConfig(configFile string) (*Config, error) {
 			cfg.EnableRollback = strings.ToLower(value) == "true"
 		case "ENABLE_CLEANUP":
 			cfg.EnableCleanup = strings.ToLower(value) == "true"
+		case "PRESERVE_MANIFEST_LIST":
+			cfg.PreserveManifestList = strings.ToLower(value) == "true"
+		case "IMAGE_TAG_KEY":
+			cfg.ImageTagKey = value
+		case "STATSD_ADDR":
+			cfg.StatsDAddr = value
+		case "STATSD_PREFIX":
+			cfg.StatsDPrefix = value
+		case "STATSD_TAGS":
+			cfg.StatsDTags = strings.ToLower(value) == "true"
+		case "SMOKE_TEST_URL":
+			cfg.SmokeTestURL = value
+		case "SMOKE_TEST_EXPECT_STATUS":
+			if status, err := strconv.Atoi(value); err == nil {
+				cfg.SmokeTestExpectStatus = status
+			}
+		case "SMOKE_TEST_EXPECT_BODY":
+			cfg.SmokeTestExpectBody = value
+		case "SMOKE_TEST_TIMEOUT":
+			if timeout, err := strconv.Atoi(value); err == nil {
+				cfg.SmokeTestTimeout = timeout
+			}
+		case "BLUE_GREEN_SERVICE":
+			cfg.BlueGreenService = value
+		case "BLUE_GREEN_SELECTOR_KEY":
+			cfg.BlueGreenSelectorKey = value
+		case "HARBOR_PULL_SECRET":
+			cfg.HarborPullSecret = value
+		case "HARBOR_PULL_SECRET_NAMESPACE":
+			cfg.HarborPullSecretNamespace = value
+		case "RELEASE_OWNER_LABEL":
+			cfg.ReleaseOwnerLabel = value
+		case "EXPECTED_RELEASE_OWNER":
+			cfg.ExpectedReleaseOwner = value
+		case "KUBECTL_DIFF":
+			cfg.KubectlDiff = strings.ToLower(value) == "true"
+		case "LINT_CHART":
+			cfg.LintChart = strings.ToLower(value) == "true"
+		case "CREATE_PULL_SECRET":
+			cfg.CreatePullSecret = value
+		case "VALUES_CONFIGMAP":
+			cfg.ValuesConfigMap = value
+		case "VALUES_SCRIPT":
+			cfg.ValuesScript = value
+		case "TIMESTAMP_TAG":
+			cfg.TimestampTag = strings.ToLower(value) == "true"
+		case "TIMESTAMP_TAG_FORMAT":
+			cfg.TimestampTagFormat = value
+		case "DOCKER_PLATFORM":
+			cfg.DockerPlatform = value
+		case "TAG_LABEL":
+			cfg.TagLabel = value
+		case "DEPLOY_BY_DIGEST":
+			switch mode := strings.ToLower(value); mode {
+			case "true":
+				cfg.DeployByDigest = "digest"
+			case "false":
+				cfg.DeployByDigest = ""
+			default:
+				cfg.DeployByDigest = mode
+			}
+		case "SET_IMAGE_REPOSITORY":
+			cfg.SetImageRepository = strings.ToLower(value) == "true"
+		case "BUILD_DEPENDENCIES":
+			cfg.BuildDependencies = strings.ToLower(value) == "true"
+		case "CLEAN_FAILED_PODS":
+			cfg.CleanFailedPods = strings.ToLower(value) == "true"
+		case "CONTAINER_RUNTIME":
+			cfg.ContainerRuntime = value
+		case "SCAN_BEFORE_PUSH":
+			cfg.ScanBeforePush = strings.ToLower(value) == "true"
+		case "SCANNER_COMMAND":
+			cfg.ScannerCommand = value
+		case "SCAN_SEVERITY":
+			cfg.ScanSeverity = value
+		case "AUTO_RECOVER_PENDING":
+			cfg.AutoRecoverPending = strings.ToLower(value) == "true"
+		case "FORCE_ROLLBACK_RECOVERY":
+			cfg.ForceRollbackRecovery = strings.ToLower(value) == "true"
+		case "ROLLBACK_GRACE_PERIOD":
+			if seconds, err := strconv.Atoi(value); err == nil {
+				cfg.RollbackGracePeriod = seconds
+			}
+		case "CI_ANNOTATIONS":
+			annotations, err := parseKeyValueList(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CI_ANNOTATIONS: %w", err)
+			}
+			cfg.CIAnnotations = annotations
+		case "HELM_SET":
+			setValues, err := parseKeyValueList(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid HELM_SET: %w", err)
+			}
+			cfg.SetValues = setValues
+		case "IMAGE_CHART_MAP":
+			chartMap, err := parseKeyValueList(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid IMAGE_CHART_MAP: %w", err)
+			}
+			cfg.ImageChartMap = chartMap
+		case "RUN_HELM_TEST":
+			cfg.RunHelmTest = strings.ToLower(value) == "true"
+		case "PARALLEL_PREFLIGHT":
+			cfg.ParallelPreflight = strings.ToLower(value) == "true"
+		case "HTTP_PROXY":
+			cfg.HTTPProxy = value
+		case "HTTPS_PROXY":
+			cfg.HTTPSProxy = value
+		case "NO_PROXY":
+			cfg.NoProxy = value
+		case "DOWNLOAD_TIMEOUT":
+			if timeout, err := strconv.Atoi(value); err == nil {
+				cfg.DownloadTimeout = timeout
+			}
+		case "DOWNLOAD_RETRIES":
+			if retries, err := strconv.Atoi(value); err == nil {
+				cfg.DownloadRetries = retries
+			}
+		case "SIGN_IMAGE":
+			cfg.SignImage = strings.ToLower(value) == "true"
+		case "COSIGN_KEY":
+			cfg.CosignKey = value
+		case "SYNC_RETRIES":
+			if retries, err := strconv.Atoi(value); err == nil && retries >= 0 {
+				cfg.SyncRetries = retries
+			}
+		case "SYNC_TIMEOUT":
+			if timeout, err := strconv.Atoi(value); err == nil {
+				cfg.SyncTimeout = timeout
+			}
+		case "VERIFY_TARGET_IMAGE":
+			cfg.VerifyTargetImage = strings.ToLower(value) == "true"
+		case "TAG_SUFFIX":
+			cfg.TagSuffix = value
+		case "EXPECTED_CONTEXT":
+			cfg.ExpectedContext = value
+		case "EXPECTED_CLUSTER":
+			cfg.ExpectedCluster = value
+		case "PARALLEL_PHASES":
+			cfg.ParallelPhases = strings.ToLower(value) == "true"
+		case "IMAGE_DIGEST_KEY":
+			cfg.ImageDigestKey = value
+		case "REQUIRED_CREDENTIALS":
+			cfg.RequiredCredentials = nil
+			for _, registry := range strings.Split(value, ",") {
+				if registry = strings.ToLower(strings.TrimSpace(registry)); registry != "" {
+					cfg.RequiredCredentials = append(cfg.RequiredCredentials, registry)
+				}
+			}
+		case "WAIT_FOR_TCP":
+			cfg.WaitForTCP = nil
+			for _, addr := range strings.Split(value, ",") {
+				if addr = strings.TrimSpace(addr); addr != "" {
+					cfg.WaitForTCP = append(cfg.WaitForTCP, addr)
+				}
+			}
+		case "HELM_DEBUG":
+			cfg.HelmDebug = strings.ToLower(value) == "true"
+		case "SKIP_TLS_VERIFY":
+			cfg.SkipTLSVerify = strings.ToLower(value) == "true"
+		case "CONFIRM_NAMESPACES":
+			cfg.ConfirmNamespaces = nil
+			for _, namespace := range strings.Split(value, ",") {
+				if namespace = strings.TrimSpace(namespace); namespace != "" {
+					cfg.ConfirmNamespaces = append(cfg.ConfirmNamespaces, namespace)
+				}
+			}
+		case "KUBECONFIGS":
+			cfg.Kubeconfigs = nil
+			for _, path := range strings.Split(value, ",") {
+				if path = strings.TrimSpace(path); path != "" {
+					cfg.Kubeconfigs = append(cfg.Kubeconfigs, path)
+				}
+			}
+		case "MAX_PARALLEL_CLUSTERS":
+			if n, err := strconv.Atoi(value); err == nil {
+				cfg.MaxParallelClusters = n
+			}
+		case "WAIT_FOR_TCP_TIMEOUT":
+			if timeout, err := strconv.Atoi(value); err == nil {
+				cfg.WaitForTCPTimeout = timeout
+			}
+		case "HELM_CREATE_NAMESPACE":
+			cfg.HelmCreateNamespace = strings.ToLower(value) == "true"
+		case "REQUIRE_NAMESPACE":
+			cfg.RequireNamespace = strings.ToLower(value) == "true"
+		case "REQUIRE_EXISTING_NAMESPACE":
+			cfg.RequireExistingNamespace = strings.ToLower(value) == "true"
+		case "BUILD_CONTEXT":
+			cfg.BuildContext = value
+		case "DOCKERFILE":
+			cfg.Dockerfile = value
+		case "BUILD_ARGS":
+			buildArgs, err := parseKeyValueList(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid BUILD_ARGS: %w", err)
+			}
+			cfg.BuildArgs = buildArgs
+		case "CAPTURE_MANIFESTS":
+			cfg.CaptureManifests = strings.ToLower(value) == "true"
+		case "MANIFEST_DIR":
+			cfg.ManifestDir = value
+		case "SKIP_UNCHANGED":
+			cfg.SkipUnchanged = strings.ToLower(value) == "true"
+		case "SKIP_UNCHANGED_SYNC":
+			cfg.SkipUnchangedSync = strings.ToLower(value) == "true"
+		case "DEPLOY_LOG_FILE":
+			cfg.DeployLogFile = value
+		case "NOTIFY_WEBHOOK_URL":
+			cfg.NotifyWebhookURL = value
+		case "NOTIFY_FORMAT":
+			cfg.NotifyFormat = value
+		case "CREDENTIALS_GPG_FILE":
+			cfg.CredentialsGPGFile = value
+		case "VAULT_ADDR":
+			cfg.VaultAddr = value
+		case "VAULT_SECRET_PATH":
+			cfg.VaultSecretPath = value
+		case "CHECK_CHART_IMAGE":
+			cfg.CheckChartImage = strings.ToLower(value) == "true"
+		case "STRICT_CHART_IMAGE_MATCH":
+			cfg.StrictChartImageMatch = strings.ToLower(value) == "true"
+		case "ROLLOUT_RESTART":
+			cfg.RolloutRestart = strings.ToLower(value) == "true"
+		case "ROLLOUT_SUCCESS_TEXT":
+			cfg.RolloutSuccessText = value
+		case "KUBE_CLI":
+			cfg.KubeCLI = value
+		case "SERVER_SECRET":
+			cfg.ServerSecret = value
+		case "STRICT_CONFIG_CHECKSUM":
+			cfg.StrictConfigChecksum = strings.ToLower(value) == "true"
+		case "STRICT_CONFIG":
+			cfg.StrictConfig = strings.ToLower(value) == "true"
+		default:
+			unknownKeys = append(unknownKeys, key)
 		}
 	}
 
@@ -84,13 +700,203 @@ func LoadConfig(configFile string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Validate required fields
-	if cfg.NexusRegistry == "" {
-		return nil, fmt.Errorf("NEXUS_REGISTRY is required")
+	// Unknown keys are usually typos; STRICT_CONFIG turns the warning into an error
+	if len(unknownKeys) > 0 {
+		if cfg.StrictConfig {
+			return nil, fmt.Errorf("unknown config keys in %s: %s", configFile, strings.Join(unknownKeys, ", "))
+		}
+		logging.Warnf("ignoring unknown config keys in %s: %s", configFile, strings.Join(unknownKeys, ", "))
+	}
+
+	return sections, nil
+}
+
+// Validate checks that required fields are present and values are well-formed
+func (cfg *Config) Validate() error {
+	if cfg.NexusRegistry == "" && cfg.BuildContext == "" {
+		return fmt.Errorf("NEXUS_REGISTRY is required")
 	}
 	if cfg.HarborRegistry == "" {
-		return nil, fmt.Errorf("HARBOR_REGISTRY is required")
+		return fmt.Errorf("HARBOR_REGISTRY is required")
+	}
+	if cfg.ContainerRuntime != "" && !isSupportedRuntime(cfg.ContainerRuntime) {
+		return fmt.Errorf("CONTAINER_RUNTIME must be one of docker, podman, nerdctl, got %q", cfg.ContainerRuntime)
+	}
+	for _, registry := range cfg.RequiredCredentials {
+		if registry != "nexus" && registry != "harbor" {
+			return fmt.Errorf("REQUIRED_CREDENTIALS entries must be nexus or harbor, got %q", registry)
+		}
+	}
+	for _, addr := range cfg.WaitForTCP {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return fmt.Errorf("invalid WAIT_FOR_TCP entry %q: %w", addr, err)
+		}
+	}
+	if cfg.NotifyFormat != "" {
+		if _, err := notify.Payload(cfg.NotifyFormat, notify.Event{}); err != nil {
+			return fmt.Errorf("invalid NOTIFY_FORMAT: %w", err)
+		}
+	}
+	if cfg.BuildContext != "" && cfg.PreserveManifestList {
+		return fmt.Errorf("BUILD_CONTEXT cannot be combined with PRESERVE_MANIFEST_LIST")
+	}
+	if cfg.ValuesConfigMap != "" {
+		if name, key, found := strings.Cut(cfg.ValuesConfigMap, "/"); !found || name == "" || key == "" {
+			return fmt.Errorf("VALUES_CONFIGMAP must be <configmap>/<key>, got %q", cfg.ValuesConfigMap)
+		}
+		if len(cfg.Kubeconfigs) > 0 {
+			return fmt.Errorf("VALUES_CONFIGMAP cannot be combined with KUBECONFIGS (the configmap would be read from one cluster only)")
+		}
+	}
+	for name, key := range cfg.CIAnnotations {
+		if !annotationKeyPattern.MatchString(key) {
+			return fmt.Errorf("CI_ANNOTATIONS maps %s to %q, which is not a valid annotation key", name, key)
+		}
+	}
+	if cfg.ForceRollbackRecovery && cfg.RollbackGracePeriod <= 0 {
+		return fmt.Errorf("FORCE_ROLLBACK_RECOVERY requires a positive ROLLBACK_GRACE_PERIOD, got %d", cfg.RollbackGracePeriod)
+	}
+	if cfg.ExpectedReleaseOwner != "" && cfg.ReleaseOwnerLabel == "" {
+		return fmt.Errorf("EXPECTED_RELEASE_OWNER requires RELEASE_OWNER_LABEL")
 	}
+	if cfg.CreatePullSecret != "" {
+		if !resourceNamePattern.MatchString(cfg.CreatePullSecret) {
+			return fmt.Errorf("CREATE_PULL_SECRET %q is not a valid secret name", cfg.CreatePullSecret)
+		}
+		if len(cfg.RequiredCredentials) > 0 && !slices.Contains(cfg.RequiredCredentials, "harbor") {
+			return fmt.Errorf("CREATE_PULL_SECRET needs Harbor credentials; add harbor to REQUIRED_CREDENTIALS")
+		}
+	}
+	if cfg.TimestampTag {
+		if tag := time.Now().UTC().Format(cfg.TimestampTagFormat); !tagPattern.MatchString(tag) {
+			return fmt.Errorf("TIMESTAMP_TAG_FORMAT %q renders %q, which is not a valid image tag", cfg.TimestampTagFormat, tag)
+		}
+	}
+	if cfg.DockerPlatform != "" && !platformPattern.MatchString(cfg.DockerPlatform) {
+		return fmt.Errorf("DOCKER_PLATFORM must look like os/arch (e.g. linux/amd64), got %q", cfg.DockerPlatform)
+	}
+	if cfg.TagLabel != "" && cfg.PreserveManifestList {
+		return fmt.Errorf("TAG_LABEL cannot be verified with PRESERVE_MANIFEST_LIST (the image is never pulled)")
+	}
+	if cfg.HelmCreateNamespace && cfg.RequireExistingNamespace {
+		return fmt.Errorf("HELM_CREATE_NAMESPACE and REQUIRE_EXISTING_NAMESPACE cannot both be enabled")
+	}
+	if cfg.KubeCLI != "" && cfg.KubeCLI != "kubectl" && cfg.KubeCLI != "oc" {
+		return fmt.Errorf("KUBE_CLI must be kubectl or oc, got %q", cfg.KubeCLI)
+	}
+	if cfg.StatsDAddr != "" {
+		if _, _, err := net.SplitHostPort(cfg.StatsDAddr); err != nil {
+			return fmt.Errorf("invalid STATSD_ADDR %q: %w", cfg.StatsDAddr, err)
+		}
+	}
+	if cfg.SmokeTestURL != "" {
+		if u, err := url.Parse(cfg.SmokeTestURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("SMOKE_TEST_URL must be an http(s) URL, got %q", cfg.SmokeTestURL)
+		}
+	}
+	if cfg.HarborPullSecret != "" && cfg.HarborPullSecretNamespace == "" && strings.Contains(cfg.Namespace, "{{") {
+		return fmt.Errorf("HARBOR_PULL_SECRET_NAMESPACE is required when NAMESPACE is a template")
+	}
+	if cfg.DeployByDigest != "" && cfg.DeployByDigest != "digest" && cfg.DeployByDigest != "tag" {
+		return fmt.Errorf("DEPLOY_BY_DIGEST must be true, false, digest or tag, got %q", cfg.DeployByDigest)
+	}
+	if cfg.MaxParallelClusters < 1 {
+		return fmt.Errorf("MAX_PARALLEL_CLUSTERS must be at least 1, got %d", cfg.MaxParallelClusters)
+	}
+	if !valuePathPattern.MatchString(cfg.ImageTagKey) {
+		return fmt.Errorf("IMAGE_TAG_KEY must be a dotted value path like image.tag, got %q", cfg.ImageTagKey)
+	}
+	if !valuePathPattern.MatchString(cfg.ImageDigestKey) {
+		return fmt.Errorf("IMAGE_DIGEST_KEY must be a dotted value path like image.digest, got %q", cfg.ImageDigestKey)
+	}
+	return nil
+}
 
-	return cfg, nil
-}
\ No newline at end of file
+// isSupportedRuntime reports whether runtime is a container CLI the docker client can drive
+func isSupportedRuntime(runtime string) bool {
+	switch runtime {
+	case "docker", "podman", "nerdctl":
+		return true
+	}
+	return false
+}
+
+// expandEnv expands ${VAR} and $VAR references from the environment; $$ yields a literal $
+func expandEnv(key, value string) string {
+	return os.Expand(value, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			logging.Warnf("%s references unset environment variable %s", key, name)
+		}
+		return v
+	})
+}
+
+// parseKeyValueList parses a comma-separated list of key=value pairs
+func parseKeyValueList(value string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("expected key=value, got %q", pair)
+		}
+		result[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return result, nil
+}
+
+// ScaleTimeouts multiplies the helm, sync, dependency wait, and health watch timeouts for slow clusters,
+// rounding second-based values up so helm still receives a whole number of seconds
+func (cfg *Config) ScaleTimeouts(multiplier float64) error {
+	if multiplier <= 0 {
+		return fmt.Errorf("timeout multiplier must be positive, got %g", multiplier)
+	}
+	scaleSeconds := func(seconds int) int {
+		return int(math.Ceil(float64(seconds) * multiplier))
+	}
+	cfg.Timeout = scaleSeconds(cfg.Timeout)
+	cfg.SyncTimeout = scaleSeconds(cfg.SyncTimeout)
+	cfg.WaitForTCPTimeout = scaleSeconds(cfg.WaitForTCPTimeout)
+	cfg.SmokeTestTimeout = scaleSeconds(cfg.SmokeTestTimeout)
+	cfg.RollbackGracePeriod = scaleSeconds(cfg.RollbackGracePeriod)
+	cfg.WatchWindow = time.Duration(float64(cfg.WatchWindow) * multiplier)
+	return nil
+}
+
+// IsConfirmNamespace reports whether namespace is listed in CONFIRM_NAMESPACES
+func (cfg *Config) IsConfirmNamespace(namespace string) bool {
+	for _, protected := range cfg.ConfirmNamespaces {
+		if protected == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckSkipTLSVerify refuses SKIP_TLS_VERIFY for a namespace listed in CONFIRM_NAMESPACES
+func (cfg *Config) CheckSkipTLSVerify(namespace string) error {
+	if cfg.SkipTLSVerify && cfg.IsConfirmNamespace(namespace) {
+		return fmt.Errorf("SKIP_TLS_VERIFY is not allowed for namespace %q (listed in CONFIRM_NAMESPACES)", namespace)
+	}
+	return nil
+}
+
+// MergeSetValues overlays overrides onto the configured helm set values, overrides winning on conflicts
+func (cfg *Config) MergeSetValues(overrides map[string]string) {
+	if len(overrides) == 0 {
+		return
+	}
+	if cfg.SetValues == nil {
+		cfg.SetValues = make(map[string]string)
+	}
+	for key, value := range overrides {
+		cfg.SetValues[key] = value
+	}
+}
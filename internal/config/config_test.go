@@ -0,0 +1,464 @@
+package config
+
+import (
+	"bytes"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes content to a deployment.conf in a temp dir and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "deployment.conf")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// minimalConfig holds the keys every deployment.conf needs to validate
+const minimalConfig = "NEXUS_REGISTRY=nexus.example.com\nHARBOR_REGISTRY=harbor.example.com\nHELM_CHART_PATH=./chart\nNAMESPACE=dev\n"
+
+// loadConfig loads minimalConfig followed by the extra lines
+func loadConfig(t *testing.T, extra string) (*Config, error) {
+	t.Helper()
+	return LoadConfig("", writeConfig(t, minimalConfig+extra))
+}
+
+func TestImageTagKey(t *testing.T) {
+	cfg, err := loadConfig(t, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ImageTagKey != "image.tag" {
+		t.Errorf("default ImageTagKey = %q, want image.tag", cfg.ImageTagKey)
+	}
+
+	if cfg, err = loadConfig(t, "IMAGE_TAG_KEY=app.image.tag\n"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ImageTagKey != "app.image.tag" {
+		t.Errorf("ImageTagKey = %q, want app.image.tag", cfg.ImageTagKey)
+	}
+
+	if _, err := loadConfig(t, "IMAGE_TAG_KEY=image tag\n"); err == nil {
+		t.Errorf("LoadConfig() accepted IMAGE_TAG_KEY with a space")
+	}
+}
+
+func TestContainerRuntime(t *testing.T) {
+	for _, runtime := range []string{"docker", "podman", "nerdctl"} {
+		cfg, err := loadConfig(t, "CONTAINER_RUNTIME="+runtime+"\n")
+		if err != nil {
+			t.Fatalf("CONTAINER_RUNTIME=%s: %v", runtime, err)
+		}
+		if cfg.ContainerRuntime != runtime {
+			t.Errorf("ContainerRuntime = %q, want %q", cfg.ContainerRuntime, runtime)
+		}
+	}
+	if _, err := loadConfig(t, "CONTAINER_RUNTIME=lxc\n"); err == nil {
+		t.Errorf("LoadConfig() accepted CONTAINER_RUNTIME=lxc")
+	}
+}
+
+func TestDefaultNamespace(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       Config
+		want      string
+		wantError bool
+	}{
+		{name: "set", cfg: Config{Namespace: "prod"}, want: "prod"},
+		{name: "unset", cfg: Config{}, want: "default"},
+		{name: "set and required", cfg: Config{Namespace: "prod", RequireNamespace: true}, want: "prod"},
+		{name: "unset and required", cfg: Config{RequireNamespace: true}, wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.DefaultNamespace()
+			if (err != nil) != tt.wantError {
+				t.Fatalf("DefaultNamespace() error = %v, wantError %v", err, tt.wantError)
+			}
+			if !tt.wantError && tt.cfg.Namespace != tt.want {
+				t.Errorf("Namespace = %q, want %q", tt.cfg.Namespace, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigLeavesNamespaceForOverrides(t *testing.T) {
+	path := writeConfig(t, "NEXUS_REGISTRY=nexus.example.com\nHARBOR_REGISTRY=harbor.example.com\nHELM_CHART_PATH=./chart\nREQUIRE_NAMESPACE=true\n")
+	cfg, err := LoadConfig("", path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v; a flag may still set the namespace", err)
+	}
+	if cfg.Namespace != "" {
+		t.Errorf("Namespace = %q, want it unset until overrides are applied", cfg.Namespace)
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("APP", "web")
+	t.Setenv("UNSET_FOR_TEST", "")
+	os.Unsetenv("UNSET_FOR_TEST")
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"${APP}-svc", "web-svc"},
+		{"$APP", "web"},
+		{"cost$$", "cost$"},
+		{"pre-${UNSET_FOR_TEST}-post", "pre--post"},
+		{"plain", "plain"},
+	}
+	for _, tt := range tests {
+		if got := expandEnv("RELEASE_NAME", tt.value); got != tt.want {
+			t.Errorf("expandEnv(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigExpandsEnv(t *testing.T) {
+	t.Setenv("APP", "web")
+	cfg, err := loadConfig(t, "RELEASE_NAME=${APP}-svc\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ReleaseName != "web-svc" {
+		t.Errorf("ReleaseName = %q, want web-svc", cfg.ReleaseName)
+	}
+}
+
+func TestParseKeyValueList(t *testing.T) {
+	got, err := parseKeyValueList(" api = ./charts/api ,worker=oci://harbor.example.com/charts/worker,, ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"api": "./charts/api", "worker": "oci://harbor.example.com/charts/worker"}
+	if !maps.Equal(got, want) {
+		t.Errorf("parseKeyValueList() = %v, want %v", got, want)
+	}
+	for _, bad := range []string{"api", "=./charts/api"} {
+		if _, err := parseKeyValueList(bad); err == nil {
+			t.Errorf("parseKeyValueList(%q) accepted a pair without a key", bad)
+		}
+	}
+}
+
+func TestImageChartMap(t *testing.T) {
+	cfg, err := loadConfig(t, "IMAGE_CHART_MAP=api=./charts/api,worker=./charts/worker\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ImageChartMap["worker"] != "./charts/worker" {
+		t.Errorf("ImageChartMap = %v, want worker mapped to ./charts/worker", cfg.ImageChartMap)
+	}
+	if _, err := loadConfig(t, "IMAGE_CHART_MAP=api\n"); err == nil {
+		t.Errorf("LoadConfig() accepted an IMAGE_CHART_MAP entry without a chart")
+	}
+}
+
+func TestMergeSetValues(t *testing.T) {
+	cfg := &Config{}
+	cfg.MergeSetValues(nil)
+	if cfg.SetValues != nil {
+		t.Errorf("SetValues = %v, want nil without overrides", cfg.SetValues)
+	}
+
+	cfg.SetValues = map[string]string{"replicaCount": "2", "image.pullPolicy": "Always"}
+	cfg.MergeSetValues(map[string]string{"replicaCount": "3", "debug": "true"})
+	want := map[string]string{"replicaCount": "3", "image.pullPolicy": "Always", "debug": "true"}
+	if !maps.Equal(cfg.SetValues, want) {
+		t.Errorf("SetValues = %v, want %v", cfg.SetValues, want)
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	if _, err := loadConfig(t, "HELM_TIMOUT=10m\n"); err != nil {
+		t.Errorf("LoadConfig() rejected an unknown key without STRICT_CONFIG: %v", err)
+	}
+
+	_, err := loadConfig(t, "STRICT_CONFIG=true\nHELM_TIMOUT=10m\nREPLICAS=3\n")
+	if err == nil || !strings.Contains(err.Error(), "HELM_TIMOUT, REPLICAS") {
+		t.Errorf("LoadConfig() error = %v, want the unknown keys listed", err)
+	}
+}
+
+func TestKubeCLIValidation(t *testing.T) {
+	for _, cli := range []string{"kubectl", "oc"} {
+		if _, err := loadConfig(t, "KUBE_CLI="+cli+"\n"); err != nil {
+			t.Errorf("KUBE_CLI=%s rejected: %v", cli, err)
+		}
+	}
+	if _, err := loadConfig(t, "KUBE_CLI=kubeadm\n"); err == nil || !strings.Contains(err.Error(), "KUBE_CLI") {
+		t.Errorf("KUBE_CLI=kubeadm error = %v, want it rejected", err)
+	}
+}
+
+func TestCreateNamespaceConflictsWithExistingNamespace(t *testing.T) {
+	cfg, err := loadConfig(t, "HELM_CREATE_NAMESPACE=true\n")
+	if err != nil || !cfg.HelmCreateNamespace {
+		t.Fatalf("HELM_CREATE_NAMESPACE=true not enabled: %v", err)
+	}
+	if _, err := loadConfig(t, "HELM_CREATE_NAMESPACE=true\nREQUIRE_EXISTING_NAMESPACE=true\n"); err == nil {
+		t.Errorf("LoadConfig() accepted HELM_CREATE_NAMESPACE with REQUIRE_EXISTING_NAMESPACE")
+	}
+}
+
+func TestLoadConfigMergesFiles(t *testing.T) {
+	base := writeConfig(t, minimalConfig+"TIMEOUT=300\nRELEASE_NAME=web\n")
+	override := writeConfig(t, "NAMESPACE=prod\nTIMEOUT=600\n")
+	cfg, err := LoadConfig("", base, override)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Namespace != "prod" || cfg.Timeout != 600 || cfg.ReleaseName != "web" {
+		t.Errorf("merged config = namespace %q, timeout %d, release %q; want later files to win", cfg.Namespace, cfg.Timeout, cfg.ReleaseName)
+	}
+	if !slices.Equal(cfg.ConfigFiles, []string{base, override}) {
+		t.Errorf("ConfigFiles = %q", cfg.ConfigFiles)
+	}
+	if _, err := LoadConfig(""); err == nil {
+		t.Errorf("LoadConfig() passed without a config file")
+	}
+}
+
+func TestScaleTimeouts(t *testing.T) {
+	cfg := &Config{Timeout: 300, SyncTimeout: 101, WatchWindow: 2 * time.Minute}
+	if err := cfg.ScaleTimeouts(1.5); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Timeout != 450 || cfg.SyncTimeout != 152 || cfg.WaitForTCPTimeout != 0 || cfg.WatchWindow != 3*time.Minute {
+		t.Errorf("scaled = timeout %d, sync %d, tcp %d, watch %s", cfg.Timeout, cfg.SyncTimeout, cfg.WaitForTCPTimeout, cfg.WatchWindow)
+	}
+	for _, multiplier := range []float64{0, -2} {
+		if err := cfg.ScaleTimeouts(multiplier); err == nil {
+			t.Errorf("ScaleTimeouts(%g) passed", multiplier)
+		}
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	path := writeConfig(t, minimalConfig+`TIMEOUT=300
+
+[staging]
+NAMESPACE=staging
+TIMEOUT=120
+
+[prod]
+NAMESPACE=prod
+`)
+	tests := []struct {
+		profile   string
+		namespace string
+		timeout   int
+	}{
+		{"", "dev", 300},
+		{"staging", "staging", 120},
+		{"prod", "prod", 300},
+	}
+	for _, tt := range tests {
+		cfg, err := LoadConfig(tt.profile, path)
+		if err != nil {
+			t.Fatalf("LoadConfig(%q) error = %v", tt.profile, err)
+		}
+		if cfg.Namespace != tt.namespace || cfg.Timeout != tt.timeout {
+			t.Errorf("profile %q = namespace %q, timeout %d; want %q, %d", tt.profile, cfg.Namespace, cfg.Timeout, tt.namespace, tt.timeout)
+		}
+	}
+
+	if _, err := LoadConfig("qa", path); err == nil || !strings.Contains(err.Error(), "profile [qa] not found") {
+		t.Errorf("LoadConfig(qa) error = %v, want a missing profile", err)
+	}
+}
+
+func TestCheckSkipTLSVerify(t *testing.T) {
+	cfg := &Config{SkipTLSVerify: true, ConfirmNamespaces: []string{"prod"}}
+	if err := cfg.CheckSkipTLSVerify("staging"); err != nil {
+		t.Errorf("CheckSkipTLSVerify(staging) error = %v", err)
+	}
+	if err := cfg.CheckSkipTLSVerify("prod"); err == nil {
+		t.Errorf("CheckSkipTLSVerify(prod) passed for a CONFIRM_NAMESPACES namespace")
+	}
+	cfg.SkipTLSVerify = false
+	if err := cfg.CheckSkipTLSVerify("prod"); err != nil {
+		t.Errorf("CheckSkipTLSVerify(prod) without SKIP_TLS_VERIFY error = %v", err)
+	}
+}
+
+func TestTemplatedNamespaceNeedsPullSecretNamespace(t *testing.T) {
+	extra := "NAMESPACE={{ image_name }}\nHARBOR_PULL_SECRET=harbor-pull\n"
+	if _, err := loadConfig(t, extra); err == nil || !strings.Contains(err.Error(), "HARBOR_PULL_SECRET_NAMESPACE") {
+		t.Errorf("LoadConfig() error = %v, want HARBOR_PULL_SECRET_NAMESPACE required", err)
+	}
+	if _, err := loadConfig(t, extra+"HARBOR_PULL_SECRET_NAMESPACE=ci\n"); err != nil {
+		t.Errorf("LoadConfig() with HARBOR_PULL_SECRET_NAMESPACE error = %v", err)
+	}
+}
+
+func TestDeployByDigest(t *testing.T) {
+	tests := map[string]string{"true": "digest", "false": "", "Digest": "digest", "tag": "tag"}
+	for value, want := range tests {
+		cfg, err := loadConfig(t, "DEPLOY_BY_DIGEST="+value+"\n")
+		if err != nil || cfg.DeployByDigest != want {
+			t.Errorf("DEPLOY_BY_DIGEST=%s = %q, %v; want %q", value, cfg.DeployByDigest, err, want)
+		}
+	}
+	if _, err := loadConfig(t, "DEPLOY_BY_DIGEST=always\n"); err == nil {
+		t.Errorf("DEPLOY_BY_DIGEST=always accepted")
+	}
+}
+
+func TestTagLabelNeedsPulledImage(t *testing.T) {
+	if _, err := loadConfig(t, "TAG_LABEL=org.opencontainers.image.version\n"); err != nil {
+		t.Errorf("TAG_LABEL alone = %v", err)
+	}
+	if _, err := loadConfig(t, "TAG_LABEL=org.opencontainers.image.version\nPRESERVE_MANIFEST_LIST=true\n"); err == nil {
+		t.Errorf("TAG_LABEL with PRESERVE_MANIFEST_LIST accepted")
+	}
+}
+
+func TestDockerPlatform(t *testing.T) {
+	for _, platform := range []string{"linux/amd64", "linux/arm64/v8"} {
+		if _, err := loadConfig(t, "DOCKER_PLATFORM="+platform+"\n"); err != nil {
+			t.Errorf("DOCKER_PLATFORM=%s = %v", platform, err)
+		}
+	}
+	for _, platform := range []string{"amd64", "linux/amd64 --push", "Linux/AMD64"} {
+		if _, err := loadConfig(t, "DOCKER_PLATFORM="+platform+"\n"); err == nil {
+			t.Errorf("DOCKER_PLATFORM=%s accepted", platform)
+		}
+	}
+}
+
+func TestReleaseOwner(t *testing.T) {
+	cfg, err := loadConfig(t, "EXPECTED_RELEASE_OWNER=payments\n")
+	if err != nil || cfg.ReleaseOwnerLabel != "owner" {
+		t.Errorf("RELEASE_OWNER_LABEL = %q, %v, want the owner default", cfg.ReleaseOwnerLabel, err)
+	}
+	if _, err := loadConfig(t, "EXPECTED_RELEASE_OWNER=payments\nRELEASE_OWNER_LABEL=\n"); err == nil {
+		t.Errorf("EXPECTED_RELEASE_OWNER without RELEASE_OWNER_LABEL accepted")
+	}
+}
+
+func TestRolloutRestart(t *testing.T) {
+	for value, want := range map[string]bool{"true": true, "TRUE": true, "false": false, "yes": false} {
+		cfg, err := loadConfig(t, "ROLLOUT_RESTART="+value+"\n")
+		if err != nil || cfg.RolloutRestart != want {
+			t.Errorf("ROLLOUT_RESTART=%s = %v, %v; want %v", value, cfg.RolloutRestart, err, want)
+		}
+	}
+}
+
+func TestRequiredCredentials(t *testing.T) {
+	cfg, err := loadConfig(t, "REQUIRED_CREDENTIALS= Harbor, nexus ,\n")
+	if err != nil || !slices.Equal(cfg.RequiredCredentials, []string{"harbor", "nexus"}) {
+		t.Errorf("REQUIRED_CREDENTIALS = %q, %v", cfg.RequiredCredentials, err)
+	}
+	if _, err := loadConfig(t, "REQUIRED_CREDENTIALS=harbor,quay\n"); err == nil {
+		t.Errorf("REQUIRED_CREDENTIALS=harbor,quay accepted")
+	}
+}
+
+func TestValuesConfigMap(t *testing.T) {
+	if _, err := loadConfig(t, "VALUES_CONFIGMAP=base-values/values.yaml\n"); err != nil {
+		t.Errorf("VALUES_CONFIGMAP=base-values/values.yaml = %v", err)
+	}
+	if _, err := loadConfig(t, "VALUES_CONFIGMAP=base-values\n"); err == nil {
+		t.Errorf("VALUES_CONFIGMAP without a key accepted")
+	}
+}
+
+func TestTimestampTagFormat(t *testing.T) {
+	cfg, err := loadConfig(t, "TIMESTAMP_TAG=true\n")
+	if err != nil || !cfg.TimestampTag || cfg.TimestampTagFormat != "deployed-20060102-1504" {
+		t.Errorf("TIMESTAMP_TAG = %v, %q, %v", cfg.TimestampTag, cfg.TimestampTagFormat, err)
+	}
+	if _, err := loadConfig(t, "TIMESTAMP_TAG=true\nTIMESTAMP_TAG_FORMAT=2006-01-02 15:04\n"); err == nil {
+		t.Errorf("TIMESTAMP_TAG_FORMAT with a space accepted")
+	}
+}
+
+func TestWait(t *testing.T) {
+	if cfg, err := loadConfig(t, ""); err != nil || !cfg.Wait {
+		t.Errorf("WAIT default = %v, %v, want true", cfg.Wait, err)
+	}
+	if cfg, err := loadConfig(t, "WAIT=false\n"); err != nil || cfg.Wait {
+		t.Errorf("WAIT=false = %v, %v", cfg.Wait, err)
+	}
+}
+
+func TestHarborRegistries(t *testing.T) {
+	cfg, err := LoadConfig("", writeConfig(t, "NEXUS_REGISTRY=nexus.example.com\nHELM_CHART_PATH=./chart\nNAMESPACE=dev\nHARBOR_REGISTRIES=harbor.dc1.example.com, harbor.dc2.example.com,harbor.dc1.example.com\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.HarborRegistry != "harbor.dc1.example.com" {
+		t.Errorf("HarborRegistry = %q, want the first HARBOR_REGISTRIES entry", cfg.HarborRegistry)
+	}
+	if mirrors := cfg.HarborMirrors(); !slices.Equal(mirrors, []string{"harbor.dc2.example.com"}) {
+		t.Errorf("HarborMirrors() = %q, want the other registries once", mirrors)
+	}
+}
+
+func TestCreatePullSecret(t *testing.T) {
+	if _, err := loadConfig(t, "CREATE_PULL_SECRET=harbor-pull\n"); err != nil {
+		t.Errorf("CREATE_PULL_SECRET=harbor-pull = %v", err)
+	}
+	if _, err := loadConfig(t, "CREATE_PULL_SECRET=Harbor_Pull\n"); err == nil {
+		t.Errorf("CREATE_PULL_SECRET=Harbor_Pull accepted")
+	}
+	if _, err := loadConfig(t, "CREATE_PULL_SECRET=harbor-pull\nREQUIRED_CREDENTIALS=nexus\n"); err == nil {
+		t.Errorf("CREATE_PULL_SECRET without Harbor credentials accepted")
+	}
+}
+
+func TestForceRollbackRecovery(t *testing.T) {
+	cfg, err := loadConfig(t, "FORCE_ROLLBACK_RECOVERY=true\n")
+	if err != nil || !cfg.ForceRollbackRecovery || cfg.RollbackGracePeriod != 120 {
+		t.Errorf("FORCE_ROLLBACK_RECOVERY = %v, grace %d, %v", cfg.ForceRollbackRecovery, cfg.RollbackGracePeriod, err)
+	}
+	if _, err := loadConfig(t, "FORCE_ROLLBACK_RECOVERY=true\nROLLBACK_GRACE_PERIOD=0\n"); err == nil {
+		t.Errorf("FORCE_ROLLBACK_RECOVERY with no grace period accepted")
+	}
+}
+
+func TestDefaultNamespaceWarns(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	cfg := &Config{}
+	if err := cfg.DefaultNamespace(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "NAMESPACE is not set, deploying to the default namespace") {
+		t.Errorf("log = %q, want a warning about the default namespace", buf.String())
+	}
+	buf.Reset()
+	cfg.Namespace = "prod"
+	if err := cfg.DefaultNamespace(); err != nil || buf.Len() != 0 {
+		t.Errorf("DefaultNamespace(prod) = %v, logged %q; want it silent", err, buf.String())
+	}
+}
+
+func TestCIAnnotations(t *testing.T) {
+	cfg, err := loadConfig(t, "")
+	if err != nil || cfg.CIAnnotations["GITHUB_RUN_ID"] != "sbi-deployment/github-run-id" {
+		t.Errorf("CI_ANNOTATIONS default = %v, %v", cfg.CIAnnotations, err)
+	}
+	cfg, err = loadConfig(t, "CI_ANNOTATIONS=PIPELINE_ID=example.com/pipeline-id\n")
+	if want := map[string]string{"PIPELINE_ID": "example.com/pipeline-id"}; err != nil || !maps.Equal(cfg.CIAnnotations, want) {
+		t.Errorf("CI_ANNOTATIONS = %v, %v, want %v", cfg.CIAnnotations, err, want)
+	}
+	if _, err := loadConfig(t, "CI_ANNOTATIONS=PIPELINE_ID=Example.com/pipeline id\n"); err == nil {
+		t.Errorf("CI_ANNOTATIONS with an invalid annotation key accepted")
+	}
+}
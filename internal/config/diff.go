@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// secretFields are redacted whenever configuration is printed
+var secretFields = map[string]bool{
+	"ServerSecret": true,
+	// Chat webhook URLs carry their token in the path
+	"NotifyWebhookURL": true,
+}
+
+// urlFields may embed credentials in their userinfo
+var urlFields = map[string]bool{
+	"HTTPProxy":  true,
+	"HTTPSProxy": true,
+}
+
+// Field is a printable configuration field
+type Field struct {
+	Name  string
+	Value string
+	raw   string
+}
+
+// FieldDiff describes a field whose value differs between two configurations
+type FieldDiff struct {
+	Name string
+	Old  string
+	New  string
+}
+
+// Fields returns every configuration field with its printable value, secrets redacted
+func (cfg *Config) Fields() []Field {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+
+	fields := make([]Field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		raw := fmt.Sprint(v.Field(i).Interface())
+		fields = append(fields, Field{Name: name, Value: redact(name, raw), raw: raw})
+	}
+	return fields
+}
+
+// String renders the configuration one field per line with secrets redacted
+func (cfg *Config) String() string {
+	var b strings.Builder
+	for _, f := range cfg.Fields() {
+		fmt.Fprintf(&b, "%s=%s\n", f.Name, f.Value)
+	}
+	return b.String()
+}
+
+// Diff returns the fields that differ between two configurations, ignoring where they were loaded from
+func Diff(a, b *Config) []FieldDiff {
+	oldFields := a.Fields()
+	newFields := b.Fields()
+
+	var diffs []FieldDiff
+	for i := range oldFields {
+		if oldFields[i].Name == "ConfigFile" || oldFields[i].Name == "ConfigFiles" || oldFields[i].raw == newFields[i].raw {
+			continue
+		}
+		diff := FieldDiff{Name: oldFields[i].Name, Old: oldFields[i].Value, New: newFields[i].Value}
+		if diff.Old == diff.New {
+			// Both sides redact identically, so say that the secret changed
+			diff.New += " (changed)"
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+// redact hides secret values and credentials embedded in URLs
+func redact(name, value string) string {
+	if value == "" {
+		return value
+	}
+	if secretFields[name] {
+		return "<redacted>"
+	}
+	if urlFields[name] {
+		if u, err := url.Parse(value); err == nil && u.User != nil {
+			u.User = url.User("<redacted>")
+			return u.String()
+		}
+	}
+	return value
+}
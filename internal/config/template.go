@@ -0,0 +1,216 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// template is the commented deployment.conf written by the init subcommand.
+// Keep it in sync with the keys handled by ParseConfig.
+const template = `# SBI deployment configuration
+# Values may reference environment variables as ${VAR}; use $$ for a literal $.
+
+# --- Registries (required) ---
+NEXUS_REGISTRY=nexus.example.com
+HARBOR_REGISTRY=harbor.example.com
+# Also push every image to these registries with the Harbor credentials; helm still
+# deploys from HARBOR_REGISTRY, which defaults to the first entry when unset
+# HARBOR_REGISTRIES=harbor.example.com,harbor-dr.example.com
+# Take the image name (and NEXUS_REGISTRY, if unset) from a compose service's image;
+# COMPOSE_SERVICE may be left empty when only one service has an image
+# COMPOSE_FILE=docker-compose.yml
+# COMPOSE_SERVICE=web
+
+# --- Helm release ---
+# Chart directory, repo/chart reference, or oci:// URL. {{ image_name }} is substituted.
+HELM_CHART_PATH=./helm-charts/app
+RELEASE_NAME=app
+# {{ env }} (from --env) and {{ image_name }} are substituted, e.g. {{ env }}-apps
+NAMESPACE=default
+# Fail when NAMESPACE is unset instead of warning and deploying to default
+REQUIRE_NAMESPACE=false
+TIMEOUT=300
+# Wait for the rollout (helm --wait --atomic plus health checks); false submits the
+# upgrade and returns immediately
+WAIT=true
+ENABLE_ROLLBACK=true
+ENABLE_CLEANUP=true
+IMAGE_TAG_KEY=image.tag
+IMAGE_DIGEST_KEY=image.digest
+# Pin the chart version for repository/OCI charts
+# CHART_VERSION=
+# Extra --set values as comma-separated key=value pairs
+# HELM_SET=replicaCount=2
+# Explicit image=chart overrides
+# IMAGE_CHART_MAP=frontend=./charts/web
+# Pass --create-namespace to helm upgrade so helm creates a missing namespace
+HELM_CREATE_NAMESPACE=false
+# Abort in pre-flight when NAMESPACE does not exist (guards against typos)
+REQUIRE_EXISTING_NAMESPACE=false
+# Write <release>-current.yaml and <release>-new.yaml to MANIFEST_DIR before upgrading
+CAPTURE_MANIFESTS=false
+# MANIFEST_DIR=./manifests
+# Skip the deploy when the live release already has the requested tag
+# (SKIP_UNCHANGED_SYNC also skips the image sync)
+SKIP_UNCHANGED=false
+SKIP_UNCHANGED_SYNC=false
+RUN_HELM_TEST=false
+# GET this URL after the rollout check until it answers with the expected status
+# (and contains SMOKE_TEST_EXPECT_BODY, when set) or SMOKE_TEST_TIMEOUT seconds pass
+# SMOKE_TEST_URL=https://my-app.example.com/healthz
+SMOKE_TEST_EXPECT_STATUS=200
+# SMOKE_TEST_EXPECT_BODY=ok
+SMOKE_TEST_TIMEOUT=60
+CLEAN_FAILED_PODS=false
+# Run helm dependency build first when a local chart declares dependencies
+BUILD_DEPENDENCIES=false
+# Service the blue-green subcommand points at <release>-blue or <release>-green; the
+# active color is tracked in its sbi-deployment/active-color annotation
+# BLUE_GREEN_SERVICE=my-app
+BLUE_GREEN_SELECTOR_KEY=app.kubernetes.io/instance
+AUTO_RECOVER_PENDING=false
+# Last resort: if the rollback after a failed deploy is still running after
+# ROLLBACK_GRACE_PERIOD seconds, force-delete the release's stuck pods and retry it once
+FORCE_ROLLBACK_RECOVERY=false
+ROLLBACK_GRACE_PERIOD=120
+
+# --- Image sync ---
+# Build the image locally and push it to Harbor instead of pulling from Nexus
+# BUILD_CONTEXT=.
+# DOCKERFILE=./Dockerfile
+# BUILD_ARGS=VERSION=1.2.3,COMMIT=abc123
+# docker, podman, or nerdctl (default: first one found in PATH)
+# CONTAINER_RUNTIME=
+SYNC_RETRIES=2
+# Overall time cap for the sync phase in seconds (0 = none)
+SYNC_TIMEOUT=0
+PRESERVE_MANIFEST_LIST=false
+VERIFY_TARGET_IMAGE=true
+# Appended to the pushed tag, e.g. -{{ git_sha }}
+# TAG_SUFFIX=
+SCAN_BEFORE_PUSH=false
+SCANNER_COMMAND=trivy
+SCAN_SEVERITY=CRITICAL
+SIGN_IMAGE=false
+# Leave unset for keyless (OIDC) signing
+# COSIGN_KEY=
+PARALLEL_PHASES=false
+
+# --- Cluster ---
+# kubectl or oc (OpenShift)
+KUBE_CLI=kubectl
+# Rollout success is decided by the exit code; this text only triggers a warning when
+# missing (change it for non-English kubectl locales, or leave empty to disable)
+ROLLOUT_SUCCESS_TEXT=successfully rolled out
+# Restart the deployment after a healthy rollout so pods pick up changed ConfigMaps/Secrets
+ROLLOUT_RESTART=false
+# Run helm with --debug; its output is logged at --log-level=debug
+HELM_DEBUG=false
+# Don't verify the cluster's TLS certificate (dev only; refused for CONFIRM_NAMESPACES)
+SKIP_TLS_VERIFY=false
+# Protected namespaces, e.g. production
+# CONFIRM_NAMESPACES=production
+# Roll the release out to several clusters (one kubeconfig each) after a single image sync;
+# MAX_PARALLEL_CLUSTERS bounds how many deploy at once (1 = one after another)
+# KUBECONFIGS=/etc/kube/dc1.yaml,/etc/kube/dc2.yaml
+MAX_PARALLEL_CLUSTERS=1
+
+# Registries to prompt credentials for (nexus,harbor); by default skip-sync deploys and
+# local builds are not prompted for Nexus
+# REQUIRED_CREDENTIALS=harbor
+
+# --- Safety checks ---
+PARALLEL_PREFLIGHT=true
+# EXPECTED_CONTEXT=
+# EXPECTED_CLUSTER=
+STRICT_CONFIG=false
+# Fail instead of warning when this file no longer matches its recorded checksum
+# (<config>.sha256, written by the config-checksum command)
+STRICT_CONFIG_CHECKSUM=false
+# Wait in pre-flight until these host:port dependencies accept connections
+# WAIT_FOR_TCP=db.internal:5432,cache.internal:6379
+WAIT_FOR_TCP_TIMEOUT=60
+# Compare the chart's image.repository with the Harbor target; warn on drift, fail if strict
+CHECK_CHART_IMAGE=false
+STRICT_CHART_IMAGE_MATCH=false
+# Refuse to upgrade a release whose RELEASE_OWNER_LABEL differs from this owner; new
+# releases get the label (needs helm 3.13+ for release labels)
+# EXPECTED_RELEASE_OWNER=team-payments
+RELEASE_OWNER_LABEL=owner
+# Print a kubectl diff of the rendered chart against the cluster before the upgrade
+KUBECTL_DIFF=false
+# Environment variables recorded as annotations on the release's workloads after each
+# upgrade (ENV_VAR=annotation-key); unset variables are skipped, an empty value disables it
+# CI_ANNOTATIONS=CI_RUN_URL=sbi-deployment/ci-run-url,GITHUB_RUN_ID=sbi-deployment/github-run-id,BUILD_URL=sbi-deployment/build-url
+# Run helm lint on local charts with the deploy's values and abort on lint errors
+LINT_CHART=false
+# Create or update this kubernetes.io/dockerconfigjson secret from the Harbor credentials
+# before each deploy and pass it to helm as imagePullSecrets[0].name
+# CREATE_PULL_SECRET=harbor-pull
+# Base values from a ConfigMap key in the release namespace (<configmap>/<key>),
+# passed to helm with --values before VALUES_SCRIPT output
+# VALUES_CONFIGMAP=base-values/values.yaml
+# Script that prints a YAML values document, passed to helm with --values
+# VALUES_SCRIPT=./scripts/values.sh
+# Also push a UTC deploy-time tag such as deployed-20240115-1230 (format is a Go time layout)
+TIMESTAMP_TAG=false
+TIMESTAMP_TAG_FORMAT=deployed-20060102-1504
+# Pull and build for this platform, e.g. linux/amd64 on ARM runners
+# DOCKER_PLATFORM=linux/amd64
+# Fail unless the pulled image's label equals --tag (provenance check)
+# TAG_LABEL=org.opencontainers.image.version
+# Fail unless the pushed Harbor digest is known and deploy by it: true/digest sets
+# IMAGE_DIGEST_KEY, tag sets IMAGE_TAG_KEY to <tag>@<digest> for charts without a digest value
+DEPLOY_BY_DIGEST=false
+# Set image.repository (next to IMAGE_TAG_KEY) to the Harbor target repository on deploy
+SET_IMAGE_REPOSITORY=false
+
+# --- Network ---
+# HTTP_PROXY=
+# HTTPS_PROXY=
+# NO_PROXY=
+# Per-attempt timeout (seconds) and retries for the helm/kubectl downloads in --setup
+DOWNLOAD_TIMEOUT=300
+DOWNLOAD_RETRIES=2
+
+# --- Credentials ---
+# GPG-encrypted file of NEXUS_USERNAME=... lines, decrypted in memory with gpg.
+# Unlocked by gpg-agent or the GPG_PASSPHRASE environment variable.
+# CREDENTIALS_GPG_FILE=./credentials.gpg
+# Read registry credentials from Vault (fields nexus_username, nexus_password,
+# harbor_username, harbor_password) when not set in the environment.
+# The token is read from VAULT_TOKEN; VAULT_ADDR falls back to the environment.
+# VAULT_ADDR=https://vault.example.com:8200
+# VAULT_SECRET_PATH=secret/data/sbi/registry
+# Read the Harbor login from an existing dockerconfigjson imagePullSecret in the cluster
+# HARBOR_PULL_SECRET=harbor-pull
+# HARBOR_PULL_SECRET_NAMESPACE=
+
+# --- Notifications ---
+# Send <prefix>.deploy.duration (ms) and <prefix>.deploy.success/failure counters over UDP;
+# STATSD_TAGS adds DogStatsD namespace/release tags
+# STATSD_ADDR=127.0.0.1:8125
+STATSD_PREFIX=sbi
+STATSD_TAGS=false
+# Append each deploy (timestamp, user, release, namespace, tag, result) to a local log;
+# DEPLOYMENTS.md gets a Markdown table, any other name gets JSON lines
+# DEPLOY_LOG_FILE=./deployments.jsonl
+# Post the outcome of each deploy to a chat webhook
+# NOTIFY_WEBHOOK_URL=https://hooks.slack.com/services/...
+# slack, teams, mattermost, or raw (the event as JSON)
+# NOTIFY_FORMAT=slack
+
+# --- Deploy server ---
+# SERVER_SECRET=
+`
+
+// WriteTemplate writes a commented configuration template, refusing to overwrite unless force is set
+func WriteTemplate(path string, force bool) error {
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+	}
+	if err := os.WriteFile(path, []byte(template), 0644); err != nil {
+		return fmt.Errorf("failed to write config template: %w", err)
+	}
+	return nil
+}
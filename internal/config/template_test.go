@@ -0,0 +1,30 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deployment.conf")
+	if err := WriteTemplate(path, false); err != nil {
+		t.Fatalf("WriteTemplate() error = %v", err)
+	}
+	if err := WriteTemplate(path, false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("WriteTemplate() over an existing file error = %v, want a refusal", err)
+	}
+	if err := WriteTemplate(path, true); err != nil {
+		t.Errorf("WriteTemplate() with force error = %v", err)
+	}
+
+	// Every key the template sets must be one the parser knows
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig("", writeConfig(t, string(data)+"\nSTRICT_CONFIG=true\n")); err != nil {
+		t.Errorf("template does not load strictly: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package deploy
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"sbi-deployment/internal/helm"
+)
+
+// CIAnnotations maps CI_ANNOTATIONS (environment variable to annotation key) to the
+// annotations for the variables set in the environment
+func CIAnnotations(mappings map[string]string, getenv func(string) string) map[string]string {
+	annotations := map[string]string{}
+	for name, key := range mappings {
+		if value := getenv(name); value != "" {
+			annotations[key] = value
+		}
+	}
+	return annotations
+}
+
+// annotateCIRun records the triggering CI run on the release's workloads; a chart without
+// labelled workloads only gets a warning
+func (d *Deployer) annotateCIRun(releaseName string) error {
+	annotations := CIAnnotations(d.config.CIAnnotations, os.Getenv)
+	if len(annotations) == 0 {
+		return nil
+	}
+	found, err := d.helmClient.AnnotateRelease(releaseName, d.config.Namespace, annotations)
+	if err != nil {
+		return fmt.Errorf("failed to record the CI run: %w", err)
+	}
+	if !found {
+		d.log.Warnf("No deployment, statefulset or daemonset in %s is labelled %s, CI run not recorded", d.config.Namespace, helm.ReleaseSelector(releaseName))
+		return nil
+	}
+	d.log.Infof("Recorded CI run on workloads matching %s: %s", helm.ReleaseSelector(releaseName), annotationList(annotations))
+	return nil
+}
+
+// annotationList renders annotations as sorted key=value pairs for logs
+func annotationList(annotations map[string]string) string {
+	pairs := make([]string, 0, len(annotations))
+	for key, value := range annotations {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
@@ -0,0 +1,73 @@
+package deploy
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/testutil"
+)
+
+func TestCIAnnotations(t *testing.T) {
+	env := map[string]string{
+		"GITHUB_RUN_ID": "42",
+		"CI_RUN_URL":    "",
+	}
+	mappings := map[string]string{
+		"GITHUB_RUN_ID": "sbi-deployment/github-run-id",
+		"CI_RUN_URL":    "sbi-deployment/ci-run-url",
+		"BUILD_URL":     "sbi-deployment/build-url",
+	}
+	got := CIAnnotations(mappings, func(name string) string { return env[name] })
+	want := map[string]string{"sbi-deployment/github-run-id": "42"}
+	if !maps.Equal(got, want) {
+		t.Errorf("CIAnnotations() = %v, want %v", got, want)
+	}
+}
+
+func TestAnnotationList(t *testing.T) {
+	got := annotationList(map[string]string{"b": "2", "a": "1"})
+	if want := "a=1, b=2"; got != want {
+		t.Errorf("annotationList() = %q, want %q", got, want)
+	}
+}
+
+func TestAnnotateCIRun(t *testing.T) {
+	t.Setenv("GITHUB_RUN_ID", "42")
+	log := testutil.FakeTools(t, map[string]string{"kubectl": "echo 'deployment.apps/web annotated'"})
+	d := New(&config.Config{Namespace: "prod", CIAnnotations: map[string]string{"GITHUB_RUN_ID": "sbi-deployment/github-run-id"}}, false)
+	if err := d.annotateCIRun("web"); err != nil {
+		t.Fatalf("annotateCIRun() error = %v", err)
+	}
+	want := []string{"kubectl annotate deployments,statefulsets,daemonsets -n prod -l app.kubernetes.io/instance=web --overwrite sbi-deployment/github-run-id=42"}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+
+	t.Setenv("GITHUB_RUN_ID", "")
+	log = testutil.FakeTools(t, map[string]string{"kubectl": "exit 1"})
+	if err := d.annotateCIRun("web"); err != nil {
+		t.Errorf("annotateCIRun() error = %v outside CI, want nothing to record", err)
+	}
+	if got := testutil.Calls(t, log); len(got) != 0 {
+		t.Errorf("calls = %q, want none outside CI", got)
+	}
+}
+
+func TestReleaseAnnotateFailureOnlyWarns(t *testing.T) {
+	t.Setenv("GITHUB_RUN_ID", "42")
+	log := testutil.FakeTools(t, map[string]string{
+		"helm":    "",
+		"kubectl": `if [ "$1" = annotate ]; then echo 'Error from server (Forbidden)'; exit 1; fi; echo 'deployment "web" successfully rolled out'`,
+	})
+	d := New(&config.Config{Namespace: "prod", Wait: true, CIAnnotations: map[string]string{"GITHUB_RUN_ID": "sbi-deployment/github-run-id"}}, false)
+	if _, err := d.release(t.TempDir(), &Summary{ReleaseName: "web", TargetTag: "v1"}, &config.Credentials{}); err != nil {
+		t.Fatalf("release() error = %v, want the annotate failure only logged", err)
+	}
+	got := testutil.Calls(t, log)
+	if len(got) != 3 || !strings.HasPrefix(got[1], "kubectl rollout status") || !strings.HasPrefix(got[2], "kubectl annotate") {
+		t.Errorf("calls = %q, want the upgrade, rollout status, then annotate", got)
+	}
+}
@@ -0,0 +1,101 @@
+package deploy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/helm"
+)
+
+// AuditRecord is the machine-verifiable record of what a deployment would execute
+type AuditRecord struct {
+	Timestamp    string     `json:"timestamp"`
+	ConfigFile   string     `json:"config_file"`
+	ConfigFiles  []string   `json:"config_files,omitempty"`
+	ConfigSHA256 string     `json:"config_sha256"`
+	Summary      *Summary   `json:"summary"`
+	Commands     [][]string `json:"commands"`
+}
+
+// auditDeploy records every command a deployment would run without executing any of them
+func (d *Deployer) auditDeploy(imageTag, imageName string, credentials *config.Credentials) (*Summary, error) {
+	summary, err := d.newSummary(imageTag, imageName)
+	if err != nil {
+		return nil, err
+	}
+	summary.DryRun = true
+
+	checksum, err := fileSHA256(d.config.ConfigFiles...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash config file: %w", err)
+	}
+
+	record := AuditRecord{
+		Timestamp:    time.Now().UTC().Format(time.RFC3339),
+		ConfigFile:   d.config.ConfigFile,
+		ConfigFiles:  d.config.ConfigFiles,
+		ConfigSHA256: checksum,
+		Summary:      summary,
+		Commands:     d.plannedCommands(summary, credentials),
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	if err := os.WriteFile(d.config.AuditFile, append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write audit file: %w", err)
+	}
+
+	d.log.Infof("Audit record with %d commands written to %s", len(record.Commands), d.config.AuditFile)
+	return summary, nil
+}
+
+// plannedCommand returns the argv of one planned command with the flags the helm client adds to
+// every helm and kubectl call (SKIP_TLS_VERIFY, HELM_DEBUG); a KUBECONFIGS cluster's calls are
+// prefixed with env KUBECONFIG=<path> so the record shows which cluster each one targets
+func (d *Deployer) plannedCommand(tool string, args []string) []string {
+	clusterTool := tool == "helm" || tool == d.helmClient.KubeCLI()
+	if d.config.SkipTLSVerify && clusterTool {
+		args = append(args, helm.InsecureArgs(tool)...)
+	}
+	if d.config.HelmDebug && tool == "helm" {
+		args = append(args, "--debug")
+	}
+	argv := append([]string{tool}, args...)
+	if kubeconfig := d.helmClient.Kubeconfig(); kubeconfig != "" && clusterTool {
+		argv = append([]string{"env", "KUBECONFIG=" + kubeconfig}, argv...)
+	}
+	return argv
+}
+
+// plannedCommands lists the full argv of each command a deployment would run, in order, from the
+// same pre-flight checks and steps a deployment runs. Left out are the commands that depend on what
+// the cluster reports: the delete of the failed pods CLEAN_FAILED_PODS finds, the recovery of a release
+// left pending, and the rollback after a failed upgrade. Retried commands are listed once.
+func (d *Deployer) plannedCommands(s *Summary, credentials *config.Credentials) [][]string {
+	var commands [][]string
+	for _, check := range d.preflightCheckList() {
+		commands = append(commands, check.commands...)
+	}
+	run := &deployRun{d: d, summary: s, credentials: credentials, chartPath: s.ChartPath}
+	return append(commands, planSteps(run.steps())...)
+}
+
+// fileSHA256 returns the hex SHA-256 of the concatenated contents of the files, in order
+func fileSHA256(paths ...string) (string, error) {
+	h := sha256.New()
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
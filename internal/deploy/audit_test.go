@@ -0,0 +1,233 @@
+package deploy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/testutil"
+)
+
+// loadTestConfig writes a deployment.conf with the required keys plus extra and loads it
+func loadTestConfig(t *testing.T, extra string) *config.Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "deployment.conf")
+	content := "NEXUS_REGISTRY=nexus.example.com\nHARBOR_REGISTRY=harbor.example.com\nHELM_CHART_PATH=./chart\nNAMESPACE=prod\nRELEASE_NAME=web\nCONTAINER_RUNTIME=docker\n" + extra
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadConfig("", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.ConfigFile = path
+	cfg.ConfigFiles = []string{path}
+	return cfg
+}
+
+// readAudit runs an audit deploy of web:v1 and decodes the record it writes
+func readAudit(t *testing.T, cfg *config.Config) AuditRecord {
+	t.Helper()
+	cfg.AuditFile = filepath.Join(t.TempDir(), "audit.json")
+	credentials := &config.Credentials{NexusUsername: "nexus-user", NexusPassword: "nexus-secret", HarborUsername: "harbor-user", HarborPassword: "harbor-secret"}
+	if _, err := New(cfg, false).auditDeploy("v1", "web", credentials); err != nil {
+		t.Fatalf("auditDeploy() error = %v", err)
+	}
+	data, err := os.ReadFile(cfg.AuditFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var record AuditRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatal(err)
+	}
+	return record
+}
+
+// plannedLines joins every planned argv with spaces
+func plannedLines(record AuditRecord) []string {
+	var lines []string
+	for _, argv := range record.Commands {
+		lines = append(lines, strings.Join(argv, " "))
+	}
+	return lines
+}
+
+func TestAuditDeploy(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"docker": "exit 1", "helm": "exit 1", "kubectl": "exit 1"})
+	cfg := loadTestConfig(t, "")
+	record := readAudit(t, cfg)
+
+	data, err := os.ReadFile(cfg.ConfigFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	if record.ConfigSHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("config_sha256 = %s, want the config file's hash", record.ConfigSHA256)
+	}
+	if !record.Summary.DryRun || record.Summary.TargetImage != "harbor.example.com/web:v1" {
+		t.Errorf("summary = %+v, want a dry run of harbor.example.com/web:v1", record.Summary)
+	}
+
+	lines := plannedLines(record)
+	for _, want := range []string{
+		"docker pull nexus.example.com/web:v1",
+		"docker tag nexus.example.com/web:v1 harbor.example.com/web:v1",
+		"docker push harbor.example.com/web:v1",
+		"helm upgrade --install web ./chart --namespace prod --set image.tag=v1",
+	} {
+		if !containsPrefix(lines, want) {
+			t.Errorf("planned commands %q lack %q", lines, want)
+		}
+	}
+	for _, line := range lines {
+		if strings.Contains(line, "secret") {
+			t.Errorf("planned command leaks a password: %q", line)
+		}
+	}
+	if got := testutil.Calls(t, log); len(got) != 0 {
+		t.Errorf("audit ran commands: %q", got)
+	}
+}
+
+// containsPrefix reports whether any line starts with prefix
+func containsPrefix(lines []string, prefix string) bool {
+	for _, line := range lines {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAuditDeployWithoutWait(t *testing.T) {
+	for _, line := range plannedLines(readAudit(t, loadTestConfig(t, "WAIT=false\nRUN_HELM_TEST=true\n"))) {
+		if strings.Contains(line, "--wait") || strings.Contains(line, "rollout status") || strings.HasPrefix(line, "helm test") {
+			t.Errorf("planned %q with WAIT=false", line)
+		}
+	}
+}
+
+func TestAuditSyncOrder(t *testing.T) {
+	lines := plannedLines(readAudit(t, loadTestConfig(t, "TIMESTAMP_TAG=true\nHARBOR_REGISTRIES=harbor.example.com,harbor-dr.example.com\n")))
+	extraTag, mirror := -1, -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "docker push harbor.example.com/web:deployed-") && extraTag < 0 {
+			extraTag = i
+		}
+		if strings.HasPrefix(line, "docker login harbor-dr.example.com") && mirror < 0 {
+			mirror = i
+		}
+	}
+	if extraTag < 0 || mirror < 0 || extraTag > mirror {
+		t.Errorf("planned commands %q: want the extra tag pushed before the mirror copy, as syncPhase does", lines)
+	}
+}
+
+func TestPlannedCommand(t *testing.T) {
+	d := New(&config.Config{KubeCLI: "kubectl", SkipTLSVerify: true, HelmDebug: true}, false)
+	for _, tc := range []struct {
+		tool string
+		want string
+	}{
+		{"helm", "helm status web --kube-insecure-skip-tls-verify --debug"},
+		{"kubectl", "kubectl status web --insecure-skip-tls-verify"},
+		{"docker", "docker status web"},
+	} {
+		if got := strings.Join(d.plannedCommand(tc.tool, []string{"status", "web"}), " "); got != tc.want {
+			t.Errorf("plannedCommand(%s) = %q, want %q", tc.tool, got, tc.want)
+		}
+	}
+}
+
+func TestAuditListsEveryStep(t *testing.T) {
+	cfg := loadTestConfig(t, "CLEAN_FAILED_PODS=true\n")
+	cfg.WatchWindow, cfg.WatchInterval = 20*time.Second, 10*time.Second
+	lines := plannedLines(readAudit(t, cfg))
+	if len(lines) < 3 || lines[0] != "docker version" || lines[1] != "helm version" || lines[2] != "kubectl version --client" {
+		t.Errorf("planned commands %q, want the pre-flight checks first", lines)
+	}
+	polls := 0
+	for _, line := range lines {
+		if line == "kubectl get pods -n prod -l app.kubernetes.io/instance=web -o json" {
+			polls++
+		}
+	}
+	// One lookup for CLEAN_FAILED_PODS and three WATCH_WINDOW polls (at 0s, 10s, and 20s)
+	if polls != 4 {
+		t.Errorf("planned %d pod lookups in %q, want 4", polls, lines)
+	}
+	for _, want := range []string{
+		"docker image inspect nexus.example.com/web:v1",
+		"docker image inspect harbor.example.com/web:v1",
+	} {
+		if !containsPrefix(lines, want) {
+			t.Errorf("planned commands %q lack %q", lines, want)
+		}
+	}
+
+	lines = plannedLines(readAudit(t, loadTestConfig(t, "PARALLEL_PHASES=true\nHELM_CHART_PATH=oci://charts.example.com/team/web\nLINT_CHART=true\n")))
+	pull, lint := -1, -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "helm pull oci://charts.example.com/team/web --untar") {
+			pull = i
+		}
+		if strings.HasPrefix(line, "helm lint "+filepath.Join(os.TempDir(), "sbi-chart-*", "web")) {
+			lint = i
+		}
+	}
+	if pull < 0 || lint < pull {
+		t.Errorf("planned commands %q, want the chart pulled and the pulled copy linted", lines)
+	}
+}
+
+func TestReleaseRunsPlannedCommands(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{
+		"helm":    "",
+		"kubectl": `if [ "$1" = get ]; then echo '{"items": [{"metadata": {"name": "web-1"}, "status": {"conditions": [{"type": "Ready", "status": "True"}]}}]}'; fi`,
+	})
+	cfg := &config.Config{Namespace: "prod", Wait: true, CleanFailedPods: true, RolloutRestart: true, RunHelmTest: true, WatchWindow: time.Millisecond, WatchInterval: time.Hour}
+	d := New(cfg, false)
+	chart := t.TempDir()
+	summary := &Summary{ReleaseName: "web", TargetTag: "v1", Namespace: "prod"}
+	if _, err := d.release(chart, summary, &config.Credentials{}); err != nil {
+		t.Fatalf("release() error = %v", err)
+	}
+	var planned []string
+	for _, argv := range d.planReleaseToClusters(chart, summary, &config.Credentials{}) {
+		planned = append(planned, strings.Join(argv, " "))
+	}
+	if got := testutil.Calls(t, log); !slices.Equal(got, planned) {
+		t.Errorf("release ran %q, audit plans %q", got, planned)
+	}
+}
+
+func TestAuditPerCluster(t *testing.T) {
+	lines := plannedLines(readAudit(t, loadTestConfig(t, "KUBECONFIGS=/kube/east.yaml,/kube/west.yaml\nREQUIRE_EXISTING_NAMESPACE=true\n")))
+	for _, want := range []string{
+		"env KUBECONFIG=/kube/east.yaml kubectl get namespace prod -o name",
+		"env KUBECONFIG=/kube/west.yaml kubectl get namespace prod -o name",
+		"env KUBECONFIG=/kube/east.yaml helm upgrade --install web ./chart",
+		"env KUBECONFIG=/kube/west.yaml helm upgrade --install web ./chart",
+		"env KUBECONFIG=/kube/east.yaml kubectl rollout status deployment/web -n prod",
+		"env KUBECONFIG=/kube/west.yaml kubectl rollout status deployment/web -n prod",
+	} {
+		if !containsPrefix(lines, want) {
+			t.Errorf("planned commands %q lack %q", lines, want)
+		}
+	}
+	// Only the client version check runs once, outside any cluster
+	for _, line := range lines {
+		if strings.HasPrefix(line, "helm upgrade") || strings.HasPrefix(line, "kubectl") && line != "kubectl version --client" {
+			t.Errorf("planned %q without the cluster's KUBECONFIG", line)
+		}
+	}
+}
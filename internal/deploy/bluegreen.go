@@ -0,0 +1,88 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/helm"
+)
+
+// Blue/green colors; each color is its own <release>-<color> helm release
+const (
+	Blue  = "blue"
+	Green = "green"
+)
+
+// InactiveColor returns the color to deploy next given the active one; with nothing
+// recorded yet the first deploy goes to blue
+func InactiveColor(active string) (string, error) {
+	switch active {
+	case "":
+		return Blue, nil
+	case Blue:
+		return Green, nil
+	case Green:
+		return Blue, nil
+	}
+	return "", fmt.Errorf("service has unknown active color %q", active)
+}
+
+// activeColor looks up the color the blue/green Service routes to; dry runs and audits
+// only warn when the cluster cannot be read
+func (d *Deployer) activeColor() (string, error) {
+	active, err := d.helmClient.ActiveColor(d.config.BlueGreenService, d.config.Namespace)
+	if err != nil && (d.dryRun || d.config.AuditFile != "") {
+		d.log.Warnf("Could not read active color, assuming none: %v", err)
+		return "", nil
+	}
+	return active, err
+}
+
+// BlueGreen deploys the new tag to the inactive color, health-checks it, and then switches
+// BLUE_GREEN_SERVICE to it; the previous color keeps running for an instant switch back
+func (d *Deployer) BlueGreen(imageTag, imageName string, credentials *config.Credentials) (*Summary, error) {
+	if d.config.ReleaseName == "" || d.config.BlueGreenService == "" {
+		return nil, fmt.Errorf("blue/green deploys require RELEASE_NAME and BLUE_GREEN_SERVICE")
+	}
+	if !d.config.Wait {
+		return nil, fmt.Errorf("blue/green deploys health-check the new color before switching and cannot run with WAIT=false")
+	}
+	// The Service lives in the rendered namespace, not the {{ image_name }} template
+	d, err := d.withRenderedNamespace(imageName)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := d.activeColor()
+	if err != nil {
+		return nil, err
+	}
+	target, err := InactiveColor(active)
+	if err != nil {
+		return nil, err
+	}
+	if active != "" {
+		d.log.Infof("Service %s routes to %s, deploying %s", d.config.BlueGreenService, active, target)
+	}
+
+	summary, err := d.withReleaseSuffix("-"+target, nil).Deploy(imageTag, imageName, credentials)
+	if err != nil {
+		return nil, fmt.Errorf("%s deploy failed, service %s left unchanged: %w", target, d.config.BlueGreenService, err)
+	}
+
+	if d.dryRun || d.config.AuditFile != "" {
+		patch, err := helm.ServiceSwitchPatch(d.config.BlueGreenSelectorKey, summary.ReleaseName, target)
+		if err != nil {
+			return nil, err
+		}
+		args := helm.PatchServiceArgs(d.config.BlueGreenService, d.config.Namespace, patch)
+		d.log.Infof("   ✓ Would run: %s %s", d.helmClient.KubeCLI(), strings.Join(args, " "))
+		return summary, nil
+	}
+	if err := d.helmClient.SwitchService(d.config.BlueGreenService, d.config.Namespace, d.config.BlueGreenSelectorKey, summary.ReleaseName, target); err != nil {
+		return nil, err
+	}
+	d.log.Infof("Service %s now routes to %s", d.config.BlueGreenService, summary.ReleaseName)
+	return summary, nil
+}
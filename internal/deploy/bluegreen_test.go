@@ -0,0 +1,28 @@
+package deploy
+
+import (
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/config"
+)
+
+func TestInactiveColor(t *testing.T) {
+	tests := map[string]string{"": Blue, Blue: Green, Green: Blue}
+	for active, want := range tests {
+		if got, err := InactiveColor(active); err != nil || got != want {
+			t.Errorf("InactiveColor(%q) = %q, %v; want %q", active, got, err, want)
+		}
+	}
+	if _, err := InactiveColor("red"); err == nil {
+		t.Errorf("InactiveColor(red) passed")
+	}
+}
+
+func TestBlueGreenRequiresWait(t *testing.T) {
+	d := &Deployer{config: &config.Config{ReleaseName: "web", BlueGreenService: "web"}}
+	_, err := d.BlueGreen("v1", "web", &config.Credentials{})
+	if err == nil || !strings.Contains(err.Error(), "WAIT=false") {
+		t.Errorf("BlueGreen() error = %v, want WAIT required", err)
+	}
+}
@@ -0,0 +1,101 @@
+package deploy
+
+import (
+	"fmt"
+	"strconv"
+
+	"sbi-deployment/internal/config"
+)
+
+// CanarySuffix is appended to the release name of the canary release
+const CanarySuffix = "-canary"
+
+// CanaryOptions controls the size of a canary release and whether it is promoted
+type CanaryOptions struct {
+	Replicas int  // replicaCount of the canary release
+	Weight   int  // percentage of traffic for charts that support weighted canaries; 0 leaves it unset
+	Promote  bool // deploy the main release with the same tag once the canary is healthy
+}
+
+// CanaryReleaseName returns the name of the canary release running beside releaseName
+func CanaryReleaseName(releaseName string) string {
+	return releaseName + CanarySuffix
+}
+
+// CanarySetValues returns the helm values that size the canary release
+func CanarySetValues(opts CanaryOptions) map[string]string {
+	values := map[string]string{
+		"replicaCount": strconv.Itoa(opts.Replicas),
+	}
+	if opts.Weight > 0 {
+		values["canary.enabled"] = "true"
+		values["canary.weight"] = strconv.Itoa(opts.Weight)
+	}
+	return values
+}
+
+// validate rejects canary options that cannot produce a useful canary
+func (opts CanaryOptions) validate() error {
+	if opts.Replicas < 1 {
+		return fmt.Errorf("canary replicas must be at least 1, got %d", opts.Replicas)
+	}
+	if opts.Weight < 0 || opts.Weight > 100 {
+		return fmt.Errorf("canary weight must be between 0 and 100, got %d", opts.Weight)
+	}
+	return nil
+}
+
+// withReleaseSuffix returns a copy of the deployer that deploys <release><suffix> with extra helm values
+func (d *Deployer) withReleaseSuffix(suffix string, values map[string]string) *Deployer {
+	cfg := *d.config
+	cfg.SetValues = make(map[string]string, len(d.config.SetValues)+len(values))
+	cfg.MergeSetValues(d.config.SetValues)
+	cfg.MergeSetValues(values)
+
+	clone := *d
+	clone.config = &cfg
+	clone.releaseSuffix = suffix
+	return &clone
+}
+
+// Canary deploys <release>-canary with the new tag at a reduced replica count, health-checks it, and
+// optionally promotes the tag to the main release; the image is synced once, by the canary
+func (d *Deployer) Canary(imageTag, imageName string, credentials *config.Credentials, opts CanaryOptions) (*Summary, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	if d.config.ReleaseName == "" {
+		return nil, fmt.Errorf("canary deploys require RELEASE_NAME")
+	}
+	if !d.config.Wait {
+		return nil, fmt.Errorf("canary deploys health-check the canary and cannot run with WAIT=false")
+	}
+
+	summary, err := d.withReleaseSuffix(CanarySuffix, CanarySetValues(opts)).Deploy(imageTag, imageName, credentials)
+	if err != nil {
+		return nil, fmt.Errorf("canary deploy failed: %w", err)
+	}
+	if !opts.Promote {
+		d.log.Infof("Canary %s is healthy at tag %s", summary.ReleaseName, summary.TargetTag)
+		return summary, nil
+	}
+
+	d.log.Infof("Canary %s is healthy, promoting tag %s to the main release...", summary.ReleaseName, summary.TargetTag)
+	promote, tag := d.promotion(summary)
+	promoted, err := promote.Deploy(tag, imageName, credentials)
+	if err != nil {
+		return nil, fmt.Errorf("canary promotion failed: %w", err)
+	}
+	return promoted, nil
+}
+
+// promotion returns the deployer and tag that roll the canary's image out to the main release:
+// the tag the canary resolved (never latest-semver again) pinned to the digest it synced, without a second sync
+func (d *Deployer) promotion(canary *Summary) (*Deployer, string) {
+	promote := *d
+	cfg := *d.config
+	cfg.SkipSync = true
+	promote.config = &cfg
+	promote.pinnedDigest = canary.ImageDigest
+	return &promote, canary.ImageTag
+}
@@ -0,0 +1,81 @@
+package deploy
+
+import (
+	"maps"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/config"
+)
+
+func TestCanarySetValues(t *testing.T) {
+	if got, want := CanarySetValues(CanaryOptions{Replicas: 1}), map[string]string{"replicaCount": "1"}; !maps.Equal(got, want) {
+		t.Errorf("CanarySetValues() = %v, want %v", got, want)
+	}
+	want := map[string]string{"replicaCount": "2", "canary.enabled": "true", "canary.weight": "10"}
+	if got := CanarySetValues(CanaryOptions{Replicas: 2, Weight: 10}); !maps.Equal(got, want) {
+		t.Errorf("CanarySetValues() with a weight = %v, want %v", got, want)
+	}
+}
+
+func TestCanaryOptionsValidate(t *testing.T) {
+	for _, opts := range []CanaryOptions{{Replicas: 0}, {Replicas: 1, Weight: -1}, {Replicas: 1, Weight: 101}} {
+		if err := opts.validate(); err == nil {
+			t.Errorf("validate(%+v) passed", opts)
+		}
+	}
+	if err := (CanaryOptions{Replicas: 1, Weight: 100}).validate(); err != nil {
+		t.Errorf("validate() error = %v", err)
+	}
+}
+
+func TestWithReleaseSuffix(t *testing.T) {
+	d := &Deployer{config: &config.Config{ReleaseName: "web", SetValues: map[string]string{"replicaCount": "4", "debug": "true"}}}
+	canary := d.withReleaseSuffix(CanarySuffix, CanarySetValues(CanaryOptions{Replicas: 1}))
+	if got := canary.releaseName("web"); got != "web-canary" {
+		t.Errorf("releaseName() = %q, want web-canary", got)
+	}
+	if canary.config.SetValues["replicaCount"] != "1" || canary.config.SetValues["debug"] != "true" {
+		t.Errorf("canary values = %v, want the canary size over the configured values", canary.config.SetValues)
+	}
+	if d.config.SetValues["replicaCount"] != "4" || d.releaseName("web") != "web" {
+		t.Errorf("withReleaseSuffix() changed the main release")
+	}
+}
+
+func TestCanaryRequiresWait(t *testing.T) {
+	d := &Deployer{config: &config.Config{ReleaseName: "web"}}
+	_, err := d.Canary("v1", "web", &config.Credentials{}, CanaryOptions{Replicas: 1})
+	if err == nil || !strings.Contains(err.Error(), "WAIT=false") {
+		t.Errorf("Canary() error = %v, want WAIT required", err)
+	}
+}
+
+func TestCanaryPromotion(t *testing.T) {
+	cfg := &config.Config{NexusRegistry: "nexus.example.com", HarborRegistry: "harbor.example.com", HelmChartPath: "./chart", ReleaseName: "web"}
+	d := &Deployer{config: cfg}
+
+	// A latest-semver canary promotes the tag it resolved, not whatever is newest by then
+	promote, tag := d.promotion(&Summary{ImageTag: "v1.4.0", TargetTag: "v1.4.0"})
+	if tag != "v1.4.0" || !promote.config.SkipSync || cfg.SkipSync {
+		t.Errorf("promotion() = tag %q, SkipSync %v; want the canary's resolved tag without a second sync", tag, promote.config.SkipSync)
+	}
+	if s, err := promote.newSummary(tag, "web"); err != nil || s.ImageDigest != "" {
+		t.Errorf("promoted summary = %+v, %v; want no digest when the canary knew none", s, err)
+	}
+
+	// DEPLOY_BY_DIGEST on the skip-sync path deploys the digest the canary pushed
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	cfg.DeployByDigest = "tag"
+	promote, tag = d.promotion(&Summary{ImageTag: "v1.4.0", TargetTag: "v1.4.0", ImageDigest: digest})
+	s, err := promote.newSummary(tag, "web")
+	if err != nil {
+		t.Fatalf("newSummary() error = %v", err)
+	}
+	if s.TargetTag != "v1.4.0" || s.ImageDigest != digest {
+		t.Errorf("promoted summary = tag %q, digest %q; want the canary's tag pinned to its digest", s.TargetTag, s.ImageDigest)
+	}
+	if opts := promote.helmDeployOptions(s.ChartPath, s); opts.ImageTag != "v1.4.0@"+digest {
+		t.Errorf("promoted image tag = %q, want the canary's digest", opts.ImageTag)
+	}
+}
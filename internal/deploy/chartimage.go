@@ -0,0 +1,61 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+
+	"sbi-deployment/internal/docker"
+)
+
+// repositoryKey returns the values path of the image repository that sits next to the tag key
+func repositoryKey(tagKey string) string {
+	if i := strings.LastIndex(tagKey, "."); i >= 0 {
+		return tagKey[:i] + ".repository"
+	}
+	return "repository"
+}
+
+// matchRepository reports whether a chart's image repository points at the target repository
+func matchRepository(chartRepository, targetRepository string) bool {
+	normalize := func(repo string) string {
+		return strings.TrimSuffix(strings.TrimSpace(repo), "/")
+	}
+	return normalize(chartRepository) == normalize(targetRepository)
+}
+
+// checkChartImage compares the chart's image repository with the Harbor target, ignoring the tag.
+// A mismatch is a warning unless STRICT_CHART_IMAGE_MATCH is set.
+func (d *Deployer) checkChartImage(summary *Summary) error {
+	key := repositoryKey(d.config.ImageTagKey)
+	targetRepository, _ := docker.SplitReference(summary.TargetImage)
+
+	chartRepository, ok := d.config.SetValues[key]
+	if !ok && d.config.SetImageRepository {
+		d.log.Debugf("SET_IMAGE_REPOSITORY sets %s=%s, skipping chart image check", key, targetRepository)
+		return nil
+	}
+	if !ok {
+		var err error
+		chartRepository, ok, err = d.helmClient.ChartValue(summary.ChartPath, d.config.ChartVersion, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	var problem error
+	switch {
+	case !ok || chartRepository == "":
+		problem = fmt.Errorf("chart %s does not set %s", summary.ChartPath, key)
+	case !matchRepository(chartRepository, targetRepository):
+		problem = fmt.Errorf("chart %s=%s does not match target image repository %s", key, chartRepository, targetRepository)
+	}
+	if problem == nil {
+		d.log.Debugf("Chart %s matches target repository %s", key, targetRepository)
+		return nil
+	}
+	if d.config.StrictChartImageMatch {
+		return problem
+	}
+	d.log.Warnf("%v", problem)
+	return nil
+}
@@ -0,0 +1,42 @@
+package deploy
+
+import (
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/testutil"
+)
+
+func TestRepositoryKey(t *testing.T) {
+	tests := map[string]string{"image.tag": "image.repository", "app.image.tag": "app.image.repository", "tag": "repository"}
+	for tagKey, want := range tests {
+		if got := repositoryKey(tagKey); got != want {
+			t.Errorf("repositoryKey(%q) = %q, want %q", tagKey, got, want)
+		}
+	}
+	if !matchRepository(" harbor.example.com/web/ ", "harbor.example.com/web") || matchRepository("nexus.example.com/web", "harbor.example.com/web") {
+		t.Errorf("matchRepository() should ignore whitespace and trailing slashes only")
+	}
+}
+
+func TestCheckChartImage(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"helm": `printf 'image:\n  repository: nexus.example.com/web\n'`})
+	summary := &Summary{ChartPath: "./chart", TargetImage: "harbor.example.com/web:v1"}
+	cfg := &config.Config{ImageTagKey: "image.tag"}
+
+	if err := New(cfg, false).checkChartImage(summary); err != nil {
+		t.Errorf("checkChartImage() without STRICT_CHART_IMAGE_MATCH error = %v, want a warning only", err)
+	}
+
+	cfg.StrictChartImageMatch = true
+	err := New(cfg, false).checkChartImage(summary)
+	if err == nil || !strings.Contains(err.Error(), "does not match target image repository harbor.example.com/web") {
+		t.Errorf("checkChartImage() error = %v, want a mismatch", err)
+	}
+
+	cfg.SetValues = map[string]string{"image.repository": "harbor.example.com/web"}
+	if err := New(cfg, false).checkChartImage(summary); err != nil {
+		t.Errorf("checkChartImage() with a matching --set error = %v", err)
+	}
+}
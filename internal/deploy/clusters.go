@@ -0,0 +1,108 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/logging"
+)
+
+// ClusterResult records the outcome of releasing to one KUBECONFIGS cluster
+type ClusterResult struct {
+	Kubeconfig string `json:"kubeconfig"`
+	Notes      string `json:"notes,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// runBounded calls fn for every index in [0, n) with at most limit calls in flight
+func runBounded(limit, n int, fn func(i int)) {
+	if limit < 1 {
+		limit = 1
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < limit && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// clusterName is the short label used to prefix a cluster's log lines
+func clusterName(kubeconfig string) string {
+	return strings.TrimSuffix(filepath.Base(kubeconfig), filepath.Ext(kubeconfig))
+}
+
+// forCluster returns a copy of the deployer whose helm and kubectl calls target kubeconfig;
+// captured manifests go to a per-cluster subdirectory so clusters don't overwrite each other
+func (d *Deployer) forCluster(kubeconfig string) *Deployer {
+	name := clusterName(kubeconfig)
+	clone := d.withLogger(logging.WithPrefix("[" + name + "]"))
+	clone.helmClient = clone.helmClient.WithKubeconfig(kubeconfig)
+	cfg := *d.config
+	cfg.ManifestDir = filepath.Join(cfg.ManifestDir, name)
+	clone.config = &cfg
+	return clone
+}
+
+// planReleaseToClusters lists the commands releaseToClusters runs, cluster after cluster for KUBECONFIGS
+func (d *Deployer) planReleaseToClusters(chartPath string, summary *Summary, credentials *config.Credentials) [][]string {
+	if len(d.config.Kubeconfigs) == 0 {
+		return planSteps(d.releaseSteps(chartPath, summary, credentials, new(string)))
+	}
+	var commands [][]string
+	for _, kubeconfig := range d.config.Kubeconfigs {
+		commands = append(commands, planSteps(d.forCluster(kubeconfig).releaseSteps(chartPath, summary, credentials, new(string)))...)
+	}
+	return commands
+}
+
+// releaseToClusters runs the release phase on the current cluster, or on every KUBECONFIGS cluster
+// with at most MAX_PARALLEL_CLUSTERS at a time; every cluster is attempted and all failures are reported
+func (d *Deployer) releaseToClusters(chartPath string, summary *Summary, credentials *config.Credentials) error {
+	if len(d.config.Kubeconfigs) == 0 {
+		notes, err := d.release(chartPath, summary, credentials)
+		summary.Notes = notes
+		return err
+	}
+
+	kubeconfigs := d.config.Kubeconfigs
+	d.log.Infof("Releasing %s to %d clusters (at most %d at a time)...", summary.ReleaseName, len(kubeconfigs), d.config.MaxParallelClusters)
+
+	results := make([]ClusterResult, len(kubeconfigs))
+	errs := make([]error, len(kubeconfigs))
+	runBounded(d.config.MaxParallelClusters, len(kubeconfigs), func(i int) {
+		results[i].Kubeconfig = kubeconfigs[i]
+		notes, err := d.forCluster(kubeconfigs[i]).release(chartPath, summary, credentials)
+		results[i].Notes = notes
+		if err != nil {
+			results[i].Error = err.Error()
+			errs[i] = fmt.Errorf("cluster %s: %w", clusterName(kubeconfigs[i]), err)
+		}
+	})
+	summary.Clusters = results
+
+	if err := errors.Join(errs...); err != nil {
+		failed := 0
+		for _, e := range errs {
+			if e != nil {
+				failed++
+			}
+		}
+		return fmt.Errorf("%d of %d clusters failed: %w", failed, len(kubeconfigs), err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package deploy
+
+import (
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/testutil"
+)
+
+func TestRunBounded(t *testing.T) {
+	var running, peak atomic.Int32
+	var mu sync.Mutex
+	var done []int
+	runBounded(2, 6, func(i int) {
+		now := running.Add(1)
+		for {
+			old := peak.Load()
+			if now <= old || peak.CompareAndSwap(old, now) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		mu.Lock()
+		done = append(done, i)
+		mu.Unlock()
+	})
+
+	slices.Sort(done)
+	if !slices.Equal(done, []int{0, 1, 2, 3, 4, 5}) {
+		t.Errorf("ran %v, want every index once", done)
+	}
+	if peak.Load() > 2 {
+		t.Errorf("%d ran at once, want at most 2", peak.Load())
+	}
+}
+
+func TestForCluster(t *testing.T) {
+	d := New(&config.Config{ManifestDir: "manifests"}, false)
+	cluster := d.forCluster("/etc/kube/prod-eu.yaml")
+	if clusterName("/etc/kube/prod-eu.yaml") != "prod-eu" {
+		t.Errorf("clusterName() = %q", clusterName("/etc/kube/prod-eu.yaml"))
+	}
+	if cluster.config.ManifestDir != filepath.Join("manifests", "prod-eu") || d.config.ManifestDir != "manifests" {
+		t.Errorf("ManifestDir = %q (original %q), want a per-cluster subdirectory", cluster.config.ManifestDir, d.config.ManifestDir)
+	}
+}
+
+// perClusterKubectl answers current-context with the name of the KUBECONFIG file it runs against
+const perClusterKubectl = `basename "$KUBECONFIG" .yaml`
+
+func TestClusterChecksRunPerCluster(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"kubectl": perClusterKubectl})
+	d := New(&config.Config{
+		ExpectedContext: "prod-eu",
+		Kubeconfigs:     []string{"/etc/kube/prod-eu.yaml", "/etc/kube/prod-us.yaml"},
+	}, false)
+
+	var failed []string
+	for _, kubeconfig := range d.config.Kubeconfigs {
+		for _, check := range d.forCluster(kubeconfig).clusterChecks() {
+			if err := check.run(); err != nil {
+				failed = append(failed, clusterName(kubeconfig)+" "+check.name)
+			}
+		}
+	}
+	if !slices.Equal(failed, []string{"prod-us kube context"}) {
+		t.Errorf("failed checks = %q, want only the us cluster's context", failed)
+	}
+}
+
+func TestAlreadyDeployedOnEveryCluster(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"helm": `case "$KUBECONFIG" in
+*prod-eu*) echo '{"image": {"tag": "v2"}}' ;;
+*) echo '{"image": {"tag": "v1"}}' ;;
+esac`})
+	summary := &Summary{ReleaseName: "web", TargetTag: "v2"}
+	cfg := &config.Config{Namespace: "prod", ImageTagKey: "image.tag", Kubeconfigs: []string{"/etc/kube/prod-eu.yaml", "/etc/kube/prod-us.yaml"}}
+
+	if deployed, err := New(cfg, false).alreadyDeployed(summary); err != nil || deployed {
+		t.Errorf("alreadyDeployed() = %v, %v; want false while prod-us runs v1", deployed, err)
+	}
+	cfg.Kubeconfigs = cfg.Kubeconfigs[:1]
+	if deployed, err := New(cfg, false).alreadyDeployed(summary); err != nil || !deployed {
+		t.Errorf("alreadyDeployed() = %v, %v; want true when every cluster runs v2", deployed, err)
+	}
+}
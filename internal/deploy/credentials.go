@@ -0,0 +1,226 @@
+package deploy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/docker"
+	"sbi-deployment/internal/helm"
+	"sbi-deployment/internal/logging"
+	"sbi-deployment/internal/utils"
+	"sbi-deployment/internal/vault"
+)
+
+// CredentialSource fills in registry credentials that are still empty before prompting
+type CredentialSource interface {
+	Name() string
+	Fill(creds *config.Credentials) error
+}
+
+// SecretReader reads the string fields of a secret, e.g. from Vault
+type SecretReader interface {
+	ReadSecret(path string) (map[string]string, error)
+}
+
+// vaultSource reads credentials from a Vault secret with nexus_username, nexus_password,
+// harbor_username and harbor_password fields
+type vaultSource struct {
+	reader SecretReader
+	path   string
+}
+
+func (v *vaultSource) Name() string {
+	return "vault:" + v.path
+}
+
+func (v *vaultSource) Fill(creds *config.Credentials) error {
+	fields, err := v.reader.ReadSecret(v.path)
+	if err != nil {
+		return err
+	}
+	fillEmpty(&creds.NexusUsername, fields["nexus_username"])
+	fillEmpty(&creds.NexusPassword, fields["nexus_password"])
+	fillEmpty(&creds.HarborUsername, fields["harbor_username"])
+	fillEmpty(&creds.HarborPassword, fields["harbor_password"])
+	return nil
+}
+
+// gpgSource decrypts a credentials file of NEXUS_USERNAME=... lines in memory with the gpg CLI
+type gpgSource struct {
+	path    string
+	decrypt func(path string) ([]byte, error)
+}
+
+func (g *gpgSource) Name() string {
+	return "gpg:" + g.path
+}
+
+func (g *gpgSource) Fill(creds *config.Credentials) error {
+	plaintext, err := g.decrypt(g.path)
+	if err != nil {
+		return err
+	}
+	fields := parseCredentialLines(plaintext)
+	fillEmpty(&creds.NexusUsername, fields["NEXUS_USERNAME"])
+	fillEmpty(&creds.NexusPassword, fields["NEXUS_PASSWORD"])
+	fillEmpty(&creds.HarborUsername, fields["HARBOR_USERNAME"])
+	fillEmpty(&creds.HarborPassword, fields["HARBOR_PASSWORD"])
+	return nil
+}
+
+// pullSecretSource reads Harbor credentials from a kubernetes.io/dockerconfigjson secret
+// already in the cluster, e.g. the release's imagePullSecret
+type pullSecretSource struct {
+	name      string
+	namespace string
+	registry  string
+	read      func(name, namespace string) ([]byte, error)
+}
+
+func (p *pullSecretSource) Name() string {
+	return "secret:" + p.namespace + "/" + p.name
+}
+
+func (p *pullSecretSource) Fill(creds *config.Credentials) error {
+	if creds.HarborUsername != "" && creds.HarborPassword != "" {
+		return nil
+	}
+	data, err := p.read(p.name, p.namespace)
+	if err != nil {
+		return err
+	}
+	username, password, err := docker.ParseDockerConfig(data, p.registry)
+	if err != nil {
+		return err
+	}
+	fillEmpty(&creds.HarborUsername, username)
+	fillEmpty(&creds.HarborPassword, password)
+	return nil
+}
+
+// GPGDecryptArgs builds the gpg arguments that decrypt path to stdout; with a passphrase
+// it is read from stdin, otherwise gpg-agent supplies the key
+func GPGDecryptArgs(path string, withPassphrase bool) []string {
+	args := []string{"--batch", "--quiet"}
+	if withPassphrase {
+		args = append(args, "--pinentry-mode", "loopback", "--passphrase-fd", "0")
+	}
+	return append(args, "--decrypt", path)
+}
+
+// gpgDecrypt decrypts path with the gpg CLI, using GPG_PASSPHRASE when set
+func gpgDecrypt(path string) ([]byte, error) {
+	passphrase := os.Getenv("GPG_PASSPHRASE")
+	cmd := utils.Command("gpg", GPGDecryptArgs(path, passphrase != "")...)
+	if passphrase != "" {
+		cmd.Stdin = strings.NewReader(passphrase)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("gpg decrypt failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+// parseCredentialLines reads KEY=VALUE lines, skipping blanks and # comments
+func parseCredentialLines(data []byte) map[string]string {
+	fields := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return fields
+}
+
+// requiredCredentials reports which registries a deploy of imageTag needs credentials for:
+// REQUIRED_CREDENTIALS when set, otherwise derived from the sync mode. Skip-sync deploys only
+// need Nexus to resolve latest-semver, and Harbor to verify the image, pull an OCI chart, or
+// create the pull secret.
+func (d *Deployer) requiredCredentials(imageTag string) (nexus, harbor bool) {
+	if len(d.config.RequiredCredentials) > 0 {
+		for _, registry := range d.config.RequiredCredentials {
+			switch registry {
+			case "nexus":
+				nexus = true
+			case "harbor":
+				harbor = true
+			}
+		}
+		return nexus, harbor
+	}
+	// Listing the Nexus tags needs a Nexus login whatever the sync mode
+	resolveTag := imageTag == LatestSemverTag
+	if d.config.SkipSync {
+		return resolveTag, d.config.VerifyTargetImage || strings.HasPrefix(d.config.HelmChartPath, "oci://") || d.config.CreatePullSecret != ""
+	}
+	// Local builds push to Harbor without pulling anything from Nexus
+	return d.config.BuildContext == "" || resolveTag, true
+}
+
+// fillEmpty sets *field to value unless it is already set
+func fillEmpty(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
+// credentialSources returns the configured sources consulted after the environment
+func credentialSources(cfg *config.Config, helmClient *helm.Client) []CredentialSource {
+	var sources []CredentialSource
+	if cfg.CredentialsGPGFile != "" {
+		sources = append(sources, &gpgSource{path: cfg.CredentialsGPGFile, decrypt: gpgDecrypt})
+	}
+	if cfg.VaultSecretPath != "" {
+		addr := cfg.VaultAddr
+		if addr == "" {
+			addr = os.Getenv("VAULT_ADDR")
+		}
+		sources = append(sources, &vaultSource{
+			reader: vault.New(addr, os.Getenv("VAULT_TOKEN")),
+			path:   cfg.VaultSecretPath,
+		})
+	}
+	if cfg.HarborPullSecret != "" {
+		namespace := cfg.HarborPullSecretNamespace
+		if namespace == "" {
+			namespace = cfg.Namespace
+		}
+		sources = append(sources, &pullSecretSource{
+			name:      cfg.HarborPullSecret,
+			namespace: namespace,
+			registry:  cfg.HarborRegistry,
+			read:      helmClient.DockerConfigSecret,
+		})
+	}
+	return sources
+}
+
+// complete reports whether every credential field is set
+func complete(creds *config.Credentials) bool {
+	return creds.NexusUsername != "" && creds.NexusPassword != "" &&
+		creds.HarborUsername != "" && creds.HarborPassword != ""
+}
+
+// fillCredentials consults each source in order until all credentials are set
+func fillCredentials(creds *config.Credentials, sources []CredentialSource, log *logging.Logger) error {
+	for _, source := range sources {
+		if complete(creds) {
+			return nil
+		}
+		if err := source.Fill(creds); err != nil {
+			return fmt.Errorf("failed to read credentials from %s: %w", source.Name(), err)
+		}
+		log.Debugf("Read registry credentials from %s", source.Name())
+	}
+	return nil
+}
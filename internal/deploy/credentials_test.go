@@ -0,0 +1,137 @@
+package deploy
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/testutil"
+)
+
+// fakeSecrets is a SecretReader backed by a map of secret paths
+type fakeSecrets map[string]map[string]string
+
+func (f fakeSecrets) ReadSecret(path string) (map[string]string, error) {
+	fields, ok := f[path]
+	if !ok {
+		return nil, errors.New("secret not found")
+	}
+	return fields, nil
+}
+
+func TestFillCredentialsFromVault(t *testing.T) {
+	secrets := fakeSecrets{"secret/data/sbi": {
+		"nexus_username": "vault-nexus", "nexus_password": "n-pass",
+		"harbor_username": "vault-harbor", "harbor_password": "h-pass",
+	}}
+	creds := &config.Credentials{NexusUsername: "env-nexus"}
+	sources := []CredentialSource{&vaultSource{reader: secrets, path: "secret/data/sbi"}}
+	if err := fillCredentials(creds, sources, nil); err != nil {
+		t.Fatalf("fillCredentials() error = %v", err)
+	}
+	want := config.Credentials{NexusUsername: "env-nexus", NexusPassword: "n-pass", HarborUsername: "vault-harbor", HarborPassword: "h-pass"}
+	if *creds != want {
+		t.Errorf("credentials = %+v, want %+v with the environment value kept", *creds, want)
+	}
+}
+
+func TestFillCredentialsStopsWhenComplete(t *testing.T) {
+	creds := &config.Credentials{NexusUsername: "a", NexusPassword: "b", HarborUsername: "c", HarborPassword: "d"}
+	sources := []CredentialSource{&vaultSource{reader: fakeSecrets{}, path: "missing"}}
+	if err := fillCredentials(creds, sources, nil); err != nil {
+		t.Errorf("fillCredentials() consulted a source for complete credentials: %v", err)
+	}
+
+	creds.HarborPassword = ""
+	err := fillCredentials(creds, sources, nil)
+	if err == nil || err.Error() != "failed to read credentials from vault:missing: secret not found" {
+		t.Errorf("fillCredentials() error = %v, want the source named", err)
+	}
+}
+
+func TestParseCredentialLines(t *testing.T) {
+	fields := parseCredentialLines([]byte("# registry credentials\nNEXUS_USERNAME = reader\n\nHARBOR_PASSWORD=p=ss\nmalformed\n"))
+	want := map[string]string{"NEXUS_USERNAME": "reader", "HARBOR_PASSWORD": "p=ss"}
+	if !maps.Equal(fields, want) {
+		t.Errorf("parseCredentialLines() = %v, want %v", fields, want)
+	}
+}
+
+func TestGPGDecryptArgs(t *testing.T) {
+	if got, want := GPGDecryptArgs("creds.gpg", false), []string{"--batch", "--quiet", "--decrypt", "creds.gpg"}; !slices.Equal(got, want) {
+		t.Errorf("GPGDecryptArgs() = %q, want %q", got, want)
+	}
+	want := []string{"--batch", "--quiet", "--pinentry-mode", "loopback", "--passphrase-fd", "0", "--decrypt", "creds.gpg"}
+	if got := GPGDecryptArgs("creds.gpg", true); !slices.Equal(got, want) {
+		t.Errorf("GPGDecryptArgs() with a passphrase = %q, want %q", got, want)
+	}
+}
+
+func TestGPGSourceFill(t *testing.T) {
+	source := &gpgSource{path: "creds.gpg", decrypt: func(string) ([]byte, error) {
+		return []byte("NEXUS_USERNAME=gpg-nexus\nHARBOR_USERNAME=gpg-harbor\n"), nil
+	}}
+	creds := &config.Credentials{NexusUsername: "env-nexus"}
+	if err := source.Fill(creds); err != nil {
+		t.Fatal(err)
+	}
+	if creds.NexusUsername != "env-nexus" || creds.HarborUsername != "gpg-harbor" {
+		t.Errorf("credentials = %+v, want empty fields filled only", *creds)
+	}
+}
+
+func TestGPGDecryptUsesPassphrase(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"gpg": `read passphrase; [ "$passphrase" = hunter2 ] && echo NEXUS_USERNAME=reader`})
+	t.Setenv("GPG_PASSPHRASE", "hunter2")
+	plaintext, err := gpgDecrypt("creds.gpg")
+	if err != nil || string(plaintext) != "NEXUS_USERNAME=reader\n" {
+		t.Errorf("gpgDecrypt() = %q, %v", plaintext, err)
+	}
+	if got := testutil.Calls(t, log); len(got) != 1 || got[0] != "gpg --batch --quiet --pinentry-mode loopback --passphrase-fd 0 --decrypt creds.gpg" {
+		t.Errorf("calls = %q", got)
+	}
+}
+
+func TestPullSecretSourceFill(t *testing.T) {
+	reads := 0
+	source := &pullSecretSource{name: "harbor-pull", namespace: "prod", registry: "harbor.example.com",
+		read: func(name, namespace string) ([]byte, error) {
+			reads++
+			return []byte(`{"auths": {"harbor.example.com": {"username": "robot", "password": "token"}}}`), nil
+		}}
+
+	creds := &config.Credentials{NexusUsername: "reader"}
+	if err := source.Fill(creds); err != nil {
+		t.Fatal(err)
+	}
+	if creds.HarborUsername != "robot" || creds.HarborPassword != "token" || source.Name() != "secret:prod/harbor-pull" {
+		t.Errorf("credentials = %+v from %s", *creds, source.Name())
+	}
+	if err := source.Fill(creds); err != nil || reads != 1 {
+		t.Errorf("Fill() read the secret %d times, want once while Harbor credentials are set", reads)
+	}
+}
+
+func TestRequiredCredentials(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           config.Config
+		nexus, harbor bool
+	}{
+		{"sync", config.Config{}, true, true},
+		{"local build", config.Config{BuildContext: "."}, false, true},
+		{"skip sync", config.Config{SkipSync: true}, false, false},
+		{"skip sync verifying the image", config.Config{SkipSync: true, VerifyTargetImage: true}, false, true},
+		{"skip sync with an OCI chart", config.Config{SkipSync: true, HelmChartPath: "oci://harbor.example.com/charts/web"}, false, true},
+		{"skip sync creating a pull secret", config.Config{SkipSync: true, CreatePullSecret: "harbor-pull"}, false, true},
+		{"REQUIRED_CREDENTIALS", config.Config{RequiredCredentials: []string{"harbor"}}, false, true},
+	}
+	for _, tt := range tests {
+		d := &Deployer{config: &tt.cfg}
+		if nexus, harbor := d.requiredCredentials("v1"); nexus != tt.nexus || harbor != tt.harbor {
+			t.Errorf("%s: requiredCredentials() = %v, %v, want %v, %v", tt.name, nexus, harbor, tt.nexus, tt.harbor)
+		}
+	}
+}
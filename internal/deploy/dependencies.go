@@ -0,0 +1,28 @@
+package deploy
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// tcpDialTimeout bounds each connection attempt to a dependency
+const tcpDialTimeout = 3 * time.Second
+
+// waitForTCP returns a check that dials addr until it accepts a connection or timeout elapses
+func waitForTCP(addr string, timeout, interval time.Duration) func() error {
+	return func() error {
+		deadline := time.Now().Add(timeout)
+		for {
+			conn, err := net.DialTimeout("tcp", addr, tcpDialTimeout)
+			if err == nil {
+				conn.Close()
+				return nil
+			}
+			if time.Now().Add(interval).After(deadline) {
+				return fmt.Errorf("dependency %s not reachable after %s: %w", addr, timeout, err)
+			}
+			time.Sleep(interval)
+		}
+	}
+}
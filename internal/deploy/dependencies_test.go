@@ -0,0 +1,44 @@
+package deploy
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitForTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	if err := waitForTCP(addr, time.Second, 10*time.Millisecond)(); err != nil {
+		t.Errorf("waitForTCP() on a listening port error = %v", err)
+	}
+
+	listener.Close()
+	err = waitForTCP(addr, 30*time.Millisecond, 10*time.Millisecond)()
+	if err == nil || !strings.Contains(err.Error(), "not reachable after 30ms") {
+		t.Errorf("waitForTCP() on a closed port error = %v", err)
+	}
+}
+
+func TestWaitForTCPRetriesUntilListening(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		if l, err := net.Listen("tcp", addr); err == nil {
+			t.Cleanup(func() { l.Close() })
+		}
+	}()
+	if err := waitForTCP(addr, 2*time.Second, 10*time.Millisecond)(); err != nil {
+		t.Errorf("waitForTCP() error = %v, want success once the dependency starts", err)
+	}
+}
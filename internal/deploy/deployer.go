@@ -1,17 +1,24 @@
 package deploy
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"os"
+	"path"
+	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
+	"golang.org/x/term"
 	"sbi-deployment/internal/config"
 	"sbi-deployment/internal/docker"
 	"sbi-deployment/internal/helm"
+	"sbi-deployment/internal/logging"
+	"sbi-deployment/internal/scan"
+	"sbi-deployment/internal/sign"
 	"sbi-deployment/internal/utils"
-	"golang.org/x/term"
 )
 
 // Deployer handles the deployment process
@@ -19,28 +26,69 @@ type Deployer struct {
 	config       *config.Config
 	dockerClient *docker.Client
 	helmClient   *helm.Client
-	verbose      bool
+	scanner      *scan.Scanner
+	signer       *sign.Signer
 	dryRun       bool
+	log          *logging.Logger
+
+	credentialSources []CredentialSource
+
+	// Appended to the release name for canary and blue/green releases
+	releaseSuffix string
+
+	// How the namespace was derived, for --explain
+	namespaceSource string
+
+	// Digest of an image an earlier deploy already synced and verified, e.g. a promoted canary's
+	pinnedDigest string
+
+	// Values files from VALUES_CONFIGMAP and VALUES_SCRIPT for this deploy, in --values order
+	valuesFiles []string
+
+	// Lifecycle event handler for embedders (WithEventHandler)
+	onEvent func(Event)
 }
 
-// New creates a new Deployer instance
-func New(cfg *config.Config, verbose, dryRun bool) *Deployer {
+// New creates a new Deployer instance and applies the configured proxy to all spawned commands
+func New(cfg *config.Config, dryRun bool) *Deployer {
+	utils.SetProxyEnv(cfg.HTTPProxy, cfg.HTTPSProxy, cfg.NoProxy)
+
+	helmClient := helm.New(cfg.KubeCLI, dryRun).
+		WithRolloutSuccessText(cfg.RolloutSuccessText).
+		WithSkipTLSVerify(cfg.SkipTLSVerify).
+		WithDebug(cfg.HelmDebug)
 	return &Deployer{
 		config:       cfg,
-		dockerClient: docker.New(verbose, dryRun),
-		helmClient:   helm.New(verbose, dryRun),
-		verbose:      verbose,
+		dockerClient: docker.New(cfg.ContainerRuntime, dryRun).WithPlatform(cfg.DockerPlatform),
+		helmClient:   helmClient,
+		scanner:      scan.New(cfg.ScannerCommand, cfg.ScanSeverity),
+		signer:       sign.New(cfg.CosignKey),
 		dryRun:       dryRun,
+		log:          logging.Default(),
+
+		credentialSources: credentialSources(cfg, helmClient),
 	}
 }
 
+// withLogger returns a shallow copy of the deployer whose output, including the
+// docker/helm clients', goes through log; used to tell parallel goroutines apart
+func (d *Deployer) withLogger(log *logging.Logger) *Deployer {
+	clone := *d
+	clone.log = log
+	clone.dockerClient = d.dockerClient.WithLogger(log)
+	clone.helmClient = d.helmClient.WithLogger(log)
+	clone.scanner = d.scanner.WithLogger(log)
+	clone.signer = d.signer.WithLogger(log)
+	return &clone
+}
+
 // SetupEnvironment installs required dependencies
 func (d *Deployer) SetupEnvironment() error {
-	log.Println("Setting up deployment environment...")
+	d.log.Infof("Setting up deployment environment...")
 
 	// Check if running as root or with sudo access
 	if !utils.IsRoot() {
-		log.Println("Note: Environment setup requires sudo privileges")
+		d.log.Infof("Note: Environment setup requires sudo privileges")
 	}
 
 	// Install required packages
@@ -51,55 +99,68 @@ func (d *Deployer) SetupEnvironment() error {
 		"ca-certificates",
 	}
 
-	if err := utils.InstallPackages(packages); err != nil {
+	if err := utils.InstallPackages(packages, d.log); err != nil {
 		return fmt.Errorf("failed to install packages: %w", err)
 	}
 
 	// Add user to docker group
-	if err := utils.AddUserToDockerGroup(); err != nil {
-		log.Printf("Warning: Failed to add user to docker group: %v", err)
-		log.Println("You may need to manually add your user to the docker group and restart")
+	if err := utils.AddUserToDockerGroup(d.log); err != nil {
+		d.log.Warnf("Failed to add user to docker group: %v", err)
+		d.log.Infof("You may need to manually add your user to the docker group and restart")
+	}
+
+	downloads := utils.DownloadOptions{
+		Timeout: time.Duration(d.config.DownloadTimeout) * time.Second,
+		Retries: d.config.DownloadRetries,
+		Log:     d.log,
 	}
 
 	// Install Helm
 	if err := utils.CheckCommand("helm"); err != nil {
-		if err := utils.InstallHelm(); err != nil {
+		if err := utils.InstallHelm(downloads); err != nil {
 			return fmt.Errorf("failed to install Helm: %w", err)
 		}
 	} else {
-		log.Println("Helm is already installed")
+		d.log.Infof("Helm is already installed")
 	}
 
 	// Install kubectl
 	if err := utils.CheckCommand("kubectl"); err != nil {
-		if err := utils.InstallKubectl(); err != nil {
+		if err := utils.InstallKubectl(downloads); err != nil {
 			return fmt.Errorf("failed to install kubectl: %w", err)
 		}
 	} else {
-		log.Println("kubectl is already installed")
+		d.log.Infof("kubectl is already installed")
 	}
 
-	log.Println("Environment setup completed")
+	d.log.Infof("Environment setup completed")
 	return nil
 }
 
-// GetCredentials prompts for or retrieves credentials from environment
-func (d *Deployer) GetCredentials() (*config.Credentials, error) {
+// GetCredentials prompts for or retrieves credentials from environment; only the registries
+// a deploy of imageTag talks to are prompted for
+func (d *Deployer) GetCredentials(imageTag string) (*config.Credentials, error) {
 	creds := &config.Credentials{}
 
-	// Try to get from environment first
-	creds.NexusUsername = os.Getenv("NEXUS_USERNAME")
-	creds.NexusPassword = os.Getenv("NEXUS_PASSWORD")
-	creds.HarborUsername = os.Getenv("HARBOR_USERNAME")
-	creds.HarborPassword = os.Getenv("HARBOR_PASSWORD")
+	// Try to get from environment first, preferring environment-specific names
+	creds.NexusUsername = credentialEnv("NEXUS_USERNAME", d.config.Environment)
+	creds.NexusPassword = credentialEnv("NEXUS_PASSWORD", d.config.Environment)
+	creds.HarborUsername = credentialEnv("HARBOR_USERNAME", d.config.Environment)
+	creds.HarborPassword = credentialEnv("HARBOR_PASSWORD", d.config.Environment)
+
+	// Then configured secret stores such as Vault
+	if err := fillCredentials(creds, d.credentialSources, d.log); err != nil {
+		return nil, err
+	}
 
 	// Prompt for missing credentials
-	if creds.NexusUsername == "" {
+	needNexus, needHarbor := d.requiredCredentials(imageTag)
+	if needNexus && creds.NexusUsername == "" {
 		fmt.Print("Enter Nexus Username: ")
 		fmt.Scanln(&creds.NexusUsername)
 	}
 
-	if creds.NexusPassword == "" {
+	if needNexus && creds.NexusPassword == "" {
 		fmt.Print("Enter Nexus Password: ")
 		password, err := term.ReadPassword(int(syscall.Stdin))
 		if err != nil {
@@ -109,12 +170,12 @@ func (d *Deployer) GetCredentials() (*config.Credentials, error) {
 		fmt.Println()
 	}
 
-	if creds.HarborUsername == "" {
+	if needHarbor && creds.HarborUsername == "" {
 		fmt.Print("Enter Harbor Username: ")
 		fmt.Scanln(&creds.HarborUsername)
 	}
 
-	if creds.HarborPassword == "" {
+	if needHarbor && creds.HarborPassword == "" {
 		fmt.Print("Enter Harbor Password: ")
 		password, err := term.ReadPassword(int(syscall.Stdin))
 		if err != nil {
@@ -127,107 +188,625 @@ func (d *Deployer) GetCredentials() (*config.Credentials, error) {
 	return creds, nil
 }
 
-// Deploy executes the complete deployment process
-func (d *Deployer) Deploy(imageTag, imageName string, credentials *config.Credentials) error {
+// credentialEnv reads NAME_<ENV> (e.g. NEXUS_USERNAME_PROD) when an environment is set, falling back to NAME
+func credentialEnv(name, environment string) string {
+	if environment != "" {
+		suffix := strings.ToUpper(strings.ReplaceAll(environment, "-", "_"))
+		if value := os.Getenv(name + "_" + suffix); value != "" {
+			return value
+		}
+	}
+	return os.Getenv(name)
+}
+
+// Summary describes the outcome of a deployment
+type Summary struct {
+	ImageName   string          `json:"image_name"`
+	ImageTag    string          `json:"image_tag"`
+	TargetTag   string          `json:"target_tag"`
+	SourceImage string          `json:"source_image"`
+	TargetImage string          `json:"target_image"`
+	ChartPath   string          `json:"chart_path"`
+	ReleaseName string          `json:"release_name"`
+	Namespace   string          `json:"namespace"`
+	ImageSize   int64           `json:"image_size_bytes,omitempty"`
+	ImageDigest string          `json:"image_digest,omitempty"`
+	Unchanged   bool            `json:"unchanged,omitempty"`
+	Notes       string          `json:"notes,omitempty"`
+	Clusters    []ClusterResult `json:"clusters,omitempty"`
+	ExtraTags   []string        `json:"extra_tags,omitempty"`
+	Mirrors     []string        `json:"mirrors,omitempty"`
+	Revision    int             `json:"revision,omitempty"`
+	DryRun      bool            `json:"dry_run"`
+	Duration    float64         `json:"duration_seconds"`
+}
+
+// Deploy executes the complete deployment process and reports the outcome to the deploy log, notifier, and StatsD
+func (d *Deployer) Deploy(imageTag, imageName string, credentials *config.Credentials) (*Summary, error) {
+	scoped, err := d.withRenderedNamespace(imageName)
+	if err != nil {
+		return nil, err
+	}
+	d = scoped
+
+	if err := d.config.CheckSkipTLSVerify(d.config.Namespace); err != nil {
+		return nil, err
+	}
+	if d.config.SkipTLSVerify {
+		d.log.Warnf("SKIP_TLS_VERIFY is enabled: the cluster's TLS certificate is NOT verified for namespace %s", d.config.Namespace)
+	}
+
+	// Dry runs and audits resolve the tag too, so they show what would really be deployed
+	if imageTag == LatestSemverTag {
+		if imageTag, err = d.resolveLatestSemver(imageName, credentials); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	d.emit(Event{Type: EventDeployStart})
+	summary, err := d.deploy(imageTag, imageName, credentials)
+	end := Event{Type: EventDeployEnd, Err: err}
+	if summary != nil {
+		end.Release = summary.ReleaseName
+	}
+	d.emit(end)
+	if !d.dryRun && d.config.AuditFile == "" {
+		d.recordDeploy(imageTag, summary, err)
+		d.notify(imageTag, imageName, summary, err)
+		d.emitMetrics(summary, err, time.Since(start))
+	}
+	if err == nil && d.config.OutputEnvFile != "" && !d.dryRun && d.config.AuditFile == "" {
+		if writeErr := d.writeOutputEnv(summary); writeErr != nil {
+			return summary, writeErr
+		}
+	}
+	return summary, err
+}
+
+// withRenderedNamespace returns a copy of the deployer whose NAMESPACE has its {{ env }} and
+// {{ image_name }} placeholders resolved for this deployment, leaving the template intact for the next one
+func (d *Deployer) withRenderedNamespace(imageName string) (*Deployer, error) {
+	if !strings.Contains(d.config.Namespace, "{{") {
+		return d, nil
+	}
+
+	vars := map[string]string{
+		"env":        d.config.Environment,
+		"image_name": d.resolveImageName(imageName),
+	}
+	namespace := utils.RenderTemplate(d.config.Namespace, vars)
+	if !utils.IsDNSLabel(namespace) {
+		return nil, fmt.Errorf("NAMESPACE %q renders to %q, which is not a valid DNS label", d.config.Namespace, namespace)
+	}
+	d.log.Infof("Using namespace %s (from %s)", namespace, d.config.Namespace)
+
+	cfg := *d.config
+	cfg.Namespace = namespace
+	clone := *d
+	clone.config = &cfg
+	clone.namespaceSource = fmt.Sprintf("from NAMESPACE template %s with image_name=%s", d.config.Namespace, vars["image_name"])
+	return &clone, nil
+}
+
+// deploy runs the deployment phases
+func (d *Deployer) deploy(imageTag, imageName string, credentials *config.Credentials) (*Summary, error) {
+	start := time.Now()
+	if d.config.Explain {
+		if err := d.explain(imageTag, imageName); err != nil {
+			return nil, err
+		}
+	}
+	if d.config.AuditFile != "" {
+		return d.auditDeploy(imageTag, imageName, credentials)
+	}
 	if d.dryRun {
-		return d.dryRunDeploy(imageTag, imageName, credentials)
+		summary, err := d.dryRunDeploy(imageTag, imageName, credentials)
+		if summary != nil {
+			summary.Duration = time.Since(start).Seconds()
+		}
+		return summary, err
 	}
 	// Pre-flight checks
-	if err := d.preflightChecks(); err != nil {
-		return fmt.Errorf("pre-flight checks failed: %w", err)
+	endPreflight := d.startPhase(PhasePreflight)
+	err := d.preflightChecks()
+	endPreflight(err)
+	if err != nil {
+		return nil, fmt.Errorf("pre-flight checks failed: %w", err)
 	}
 
-	// Determine image name from parameter, release name, or chart path
-	if imageName == "" {
-		imageName = d.config.ReleaseName
-		if imageName == "" {
-			// Extract from chart path if available
-			if strings.Contains(d.config.HelmChartPath, "{{") {
-				// Template not resolved, use a default
-				imageName = "app"
-			} else {
-				// Extract from path
-				parts := strings.Split(strings.TrimSuffix(d.config.HelmChartPath, "/"), "/")
-				if len(parts) > 0 {
-					imageName = parts[len(parts)-1]
-				} else {
-					imageName = "app"
-				}
-			}
+	summary, err := d.newSummary(imageTag, imageName)
+	if err != nil {
+		return nil, err
+	}
+	run := &deployRun{d: d, summary: summary, credentials: credentials, chartPath: summary.ChartPath}
+	defer run.cleanup()
+	if err := d.runSteps(run.steps()); err != nil {
+		if !errors.Is(err, errUnchanged) {
+			return nil, err
 		}
+		summary.Unchanged = true
 	}
 
-	sourceImage := fmt.Sprintf("%s/%s:%s", d.config.NexusRegistry, imageName, imageTag)
-	targetImage := fmt.Sprintf("%s/%s:%s", d.config.HarborRegistry, imageName, imageTag)
+	summary.Duration = time.Since(start).Seconds()
+	return summary, nil
+}
+
+// deployRun is the state the steps of one deployment share
+type deployRun struct {
+	d           *Deployer // carries the generated values files once they are fetched
+	summary     *Summary
+	credentials *config.Credentials
+	chartPath   string // the local copy of a remote chart pulled under PARALLEL_PHASES
+	unchanged   bool   // SKIP_UNCHANGED found the target tag live
+	cleanups    []func()
+}
+
+// cleanup removes the run's temporary files, latest first
+func (r *deployRun) cleanup() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
 
-	// Image sync process
-	if err := d.syncImage(sourceImage, targetImage, credentials); err != nil {
-		return fmt.Errorf("image sync failed: %w", err)
+// steps returns the steps of a deployment after the pre-flight checks, in order
+func (r *deployRun) steps() []step {
+	cfg, s := r.d.config, r.summary
+	runtime := r.d.dockerClient.Runtime()
+	return []step{
+		// An OCI chart stored in Harbor is pulled with the same Harbor login as the image
+		{
+			when: helm.OCIChartOnRegistry(s.ChartPath, cfg.HarborRegistry),
+			run: func() error {
+				return r.d.helmClient.RegistryLogin(helm.RegistryHost(s.ChartPath), r.credentials.HarborUsername, r.credentials.HarborPassword)
+			},
+			plan: func() [][]string {
+				return [][]string{r.d.plannedCommand("helm", helm.RegistryLoginArgs(helm.RegistryHost(s.ChartPath), r.credentials.HarborUsername))}
+			},
+		},
+		// Catch chart/image drift before anything is pushed
+		{
+			when: cfg.CheckChartImage,
+			run: func() error {
+				if err := r.d.checkChartImage(s); err != nil {
+					return fmt.Errorf("chart image check failed: %w", err)
+				}
+				return nil
+			},
+			plan: func() [][]string {
+				if _, ok := cfg.SetValues[repositoryKey(cfg.ImageTagKey)]; ok || cfg.SetImageRepository {
+					return nil
+				}
+				return [][]string{r.d.plannedCommand("helm", helm.ShowValuesArgs(s.ChartPath, cfg.ChartVersion))}
+			},
+		},
+		// Values files are checked before anything is pushed; generated values override the base
+		{
+			when: cfg.ValuesConfigMap != "",
+			run: func() error {
+				valuesFile, err := r.d.fetchConfigMapValues()
+				if err != nil {
+					return err
+				}
+				r.cleanups = append(r.cleanups, func() { os.Remove(valuesFile) })
+				r.d = r.d.withValuesFile(valuesFile)
+				return nil
+			},
+			plan: func() [][]string {
+				name, key, err := helm.ParseConfigMapRef(cfg.ValuesConfigMap)
+				if err != nil {
+					return nil
+				}
+				return [][]string{r.d.plannedCommand(r.d.helmClient.KubeCLI(), helm.ConfigMapKeyArgs(name, key, s.Namespace))}
+			},
+		},
+		{
+			when: cfg.ValuesScript != "",
+			run: func() error {
+				valuesFile, err := r.d.runValuesScript(s)
+				if err != nil {
+					return err
+				}
+				r.cleanups = append(r.cleanups, func() { os.Remove(valuesFile) })
+				r.d = r.d.withValuesFile(valuesFile)
+				return nil
+			},
+			plan: func() [][]string { return [][]string{r.d.plannedCommand(cfg.ValuesScript, nil)} },
+		},
+		// Re-running a pipeline for the tag that is already live is a no-op
+		{
+			when: cfg.SkipUnchanged,
+			run: func() error {
+				unchanged, err := r.d.alreadyDeployed(s)
+				if err != nil {
+					return err
+				}
+				if unchanged && cfg.SkipUnchangedSync {
+					r.d.log.Infof("Release %s already at tag %s, skipping sync and deploy", s.ReleaseName, s.TargetTag)
+					return errUnchanged
+				}
+				r.unchanged = unchanged
+				return nil
+			},
+			plan: func() [][]string {
+				if len(cfg.Kubeconfigs) == 0 {
+					return [][]string{r.d.plannedCommand("helm", helm.GetValuesArgs(s.ReleaseName, s.Namespace))}
+				}
+				var commands [][]string
+				for _, kubeconfig := range cfg.Kubeconfigs {
+					commands = append(commands, r.d.forCluster(kubeconfig).plannedCommand("helm", helm.GetValuesArgs(s.ReleaseName, s.Namespace)))
+				}
+				return commands
+			},
+		},
+		// Image sync process
+		{
+			when:  true,
+			phase: PhaseSync,
+			run: func() error {
+				switch {
+				case cfg.SkipSync:
+					return r.d.verifyTargetImage(s.TargetImage, r.credentials)
+				case cfg.ParallelPhases && helm.IsRemoteChart(r.chartPath):
+					localChart, cleanup, err := r.d.syncAndPullChart(s, r.credentials)
+					if err != nil {
+						return err
+					}
+					r.cleanups = append(r.cleanups, cleanup)
+					r.chartPath = localChart
+					return nil
+				default:
+					return r.d.syncPhase(s, r.credentials)
+				}
+			},
+			plan: func() [][]string {
+				switch {
+				case cfg.SkipSync:
+					if !cfg.VerifyTargetImage {
+						return nil
+					}
+					return [][]string{
+						r.d.plannedCommand(runtime, docker.LoginArgs(cfg.HarborRegistry, r.credentials.HarborUsername)),
+						r.d.plannedCommand(runtime, docker.ManifestInspectArgs(s.TargetImage)),
+					}
+				case cfg.ParallelPhases && helm.IsRemoteChart(r.chartPath):
+					// The chart is pulled into a fresh temporary directory; later steps use the pulled copy
+					chartDir := filepath.Join(os.TempDir(), "sbi-chart-*")
+					commands := planSteps(r.d.syncSteps(s, r.credentials, nil))
+					commands = append(commands, r.d.plannedCommand("helm", helm.PullChartArgs(s.ChartPath, cfg.ChartVersion, chartDir)))
+					r.chartPath = filepath.Join(chartDir, path.Base(s.ChartPath))
+					return commands
+				default:
+					return planSteps(r.d.syncSteps(s, r.credentials, nil))
+				}
+			},
+		},
+		{
+			when: cfg.SkipUnchanged,
+			run: func() error {
+				if !r.unchanged {
+					return nil
+				}
+				r.d.log.Infof("Release %s already at tag %s, skipping deploy", s.ReleaseName, s.TargetTag)
+				return errUnchanged
+			},
+		},
+		{
+			when: cfg.DeployByDigest != "",
+			run: func() error {
+				if s.ImageDigest == "" {
+					return fmt.Errorf("DEPLOY_BY_DIGEST is set but no digest is known for %s; pass --tag as a digest or sync the image", s.TargetImage)
+				}
+				return nil
+			},
+		},
+		// Subcharts must be in charts/ before helm can render the release
+		{
+			when: cfg.BuildDependencies,
+			run:  func() error { return r.d.buildDependencies(r.chartPath) },
+			plan: func() [][]string {
+				if helm.IsRemoteChart(r.chartPath) {
+					return nil
+				}
+				if needed, _ := helm.HasDependencies(r.chartPath); !needed {
+					return nil
+				}
+				return [][]string{r.d.plannedCommand("helm", helm.DependencyBuildArgs(r.chartPath))}
+			},
+		},
+		// Lint with the real values so template errors surface before the upgrade starts
+		{
+			when: cfg.LintChart,
+			run:  func() error { return r.d.lintChart(r.chartPath, s) },
+			plan: func() [][]string {
+				if helm.IsRemoteChart(r.chartPath) {
+					return nil
+				}
+				return [][]string{r.d.plannedCommand("helm", helm.LintArgs(r.d.helmDeployOptions(r.chartPath, s)))}
+			},
+		},
+		{
+			when:  true,
+			phase: PhaseRelease,
+			run:   func() error { return r.d.releaseToClusters(r.chartPath, s, r.credentials) },
+			plan:  func() [][]string { return r.d.planReleaseToClusters(r.chartPath, s, r.credentials) },
+		},
+		// Cleanup (manifest list copies and skipped syncs never touch local image storage)
+		{
+			when:  cfg.EnableCleanup && !cfg.PreserveManifestList && !cfg.SkipSync,
+			phase: PhaseCleanup,
+			run: func() error {
+				r.d.cleanupImages(r.d.localImages(s.TargetImage, s.SourceImage, s.ExtraTags, s.Mirrors)...)
+				return nil
+			},
+			plan: func() [][]string {
+				var commands [][]string
+				for _, image := range r.d.localImages(s.TargetImage, s.SourceImage, r.d.extraTagRefs(s.TargetImage, time.Now()), r.d.mirrorRefs(s.TargetImage)) {
+					commands = append(commands, r.d.plannedCommand(runtime, docker.RemoveArgs(image)))
+				}
+				return commands
+			},
+		},
 	}
+}
 
-	// Helm deployment
-	chartPath := strings.ReplaceAll(d.config.HelmChartPath, "{{ image_name }}", imageName)
-	releaseName := strings.ReplaceAll(d.config.ReleaseName, "{{ image_name }}", imageName)
+// alreadyDeployed reports whether the live release already runs the target tag;
+// with KUBECONFIGS it must run the target tag on every cluster
+func (d *Deployer) alreadyDeployed(summary *Summary) (bool, error) {
+	for _, kubeconfig := range d.config.Kubeconfigs {
+		cluster := d.forCluster(kubeconfig)
+		cluster.config.Kubeconfigs = nil
+		deployed, err := cluster.alreadyDeployed(summary)
+		if err != nil {
+			return false, fmt.Errorf("cluster %s: %w", clusterName(kubeconfig), err)
+		}
+		if !deployed {
+			return false, nil
+		}
+	}
+	if len(d.config.Kubeconfigs) > 0 {
+		return true, nil
+	}
+	deployed, err := d.helmClient.GetDeployedTag(summary.ReleaseName, d.config.Namespace, d.config.ImageTagKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read deployed tag: %w", err)
+	}
+	d.log.Debugf("Release %s currently deployed with %s=%q", summary.ReleaseName, d.config.ImageTagKey, deployed)
+	// DEPLOY_BY_DIGEST=tag deploys <tag>@<digest>; a known digest must match as well as the tag
+	tag, digest, pinned := strings.Cut(deployed, "@")
+	if pinned && summary.ImageDigest != "" && digest != summary.ImageDigest {
+		return false, nil
+	}
+	return tag != "" && tag == summary.TargetTag, nil
+}
 
-	if err := d.deployWithHelm(chartPath, releaseName, imageTag); err != nil {
-		return fmt.Errorf("helm deployment failed: %w", err)
+// localImages lists the local images a sync leaves behind: the target, its extra tag and mirror
+// copies, and the pulled source image (a BUILD_CONTEXT build has none)
+func (d *Deployer) localImages(targetImage, sourceImage string, extraTags, mirrors []string) []string {
+	images := append([]string{targetImage}, extraTags...)
+	images = append(images, mirrors...)
+	if d.config.BuildContext == "" {
+		images = append(images, sourceImage)
 	}
+	return images
+}
 
-	// Health check
-	if err := d.helmClient.CheckRolloutStatus(releaseName, d.config.Namespace); err != nil {
-		return fmt.Errorf("health check failed: %w", err)
+// cleanupImages removes every local image, reporting all failures together instead of stopping at the first.
+// Cleanup failures never fail the deployment.
+func (d *Deployer) cleanupImages(images ...string) {
+	removed, err := removeAll(images, d.dockerClient.Remove)
+	if len(removed) > 0 {
+		d.log.Infof("Cleaned up local images: %s", strings.Join(removed, ", "))
 	}
+	if err != nil {
+		d.log.Warnf("Cleanup incomplete (%d of %d images removed):\n%v", len(removed), len(images), err)
+	}
+}
 
-	// Cleanup
-	if d.config.EnableCleanup {
-		if err := d.dockerClient.Remove(targetImage); err != nil {
-			log.Printf("Warning: Failed to cleanup local image: %v", err)
+// removeAll calls remove for each image and returns the removed images and the joined errors of the rest
+func removeAll(images []string, remove func(string) error) ([]string, error) {
+	var removed []string
+	var errs []error
+	for _, image := range images {
+		if err := remove(image); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", image, err))
+			continue
 		}
+		removed = append(removed, image)
 	}
+	return removed, errors.Join(errs...)
+}
 
-	return nil
+// newSummary resolves the image references, chart path, and release name for a deployment
+func (d *Deployer) newSummary(imageTag, imageName string) (*Summary, error) {
+	// Determine image name from parameter, release name, or chart path
+	imageName = d.resolveImageName(imageName)
+
+	targetTag, err := d.targetTag(imageTag)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{
+		ImageName:   imageName,
+		ImageTag:    imageTag,
+		TargetTag:   targetTag,
+		SourceImage: docker.ImageRef(d.config.NexusRegistry, imageName, imageTag),
+		TargetImage: docker.ImageRef(d.config.HarborRegistry, imageName, targetTag),
+		ChartPath:   d.resolveChartPath(imageName),
+		ReleaseName: d.releaseName(imageName),
+		Namespace:   d.config.Namespace,
+	}
+	if docker.IsDigest(imageTag) {
+		summary.ImageDigest = imageTag
+	} else if d.pinnedDigest != "" {
+		summary.ImageDigest = d.pinnedDigest
+	}
+	return summary, nil
 }
 
-// preflightChecks validates all prerequisites
-func (d *Deployer) preflightChecks() error {
-	log.Println("Running pre-flight checks...")
+// targetTag appends the rendered TAG_SUFFIX to the source tag for the Harbor push
+func (d *Deployer) targetTag(imageTag string) (string, error) {
+	// Digests can't be pushed directly, so push under a tag derived from the digest
+	if docker.IsDigest(imageTag) {
+		imageTag = docker.DigestTag(imageTag)
+	}
+	if d.config.TagSuffix == "" {
+		return imageTag, nil
+	}
 
-	if err := d.dockerClient.CheckDocker(); err != nil {
-		return err
+	vars := map[string]string{}
+	if strings.Contains(d.config.TagSuffix, "git_sha") {
+		sha, err := utils.GitShortSHA()
+		if err != nil {
+			return "", fmt.Errorf("failed to render TAG_SUFFIX: %w", err)
+		}
+		vars["git_sha"] = sha
 	}
+	return imageTag + utils.RenderTemplate(d.config.TagSuffix, vars), nil
+}
 
-	if err := d.helmClient.CheckHelm(); err != nil {
-		return err
+// resolveChartPath returns the mapped chart for an image, falling back to the templated HELM_CHART_PATH
+func (d *Deployer) resolveChartPath(imageName string) string {
+	// Substituted values can carry stray whitespace (e.g. a tab) that os.Stat would reject
+	if chartPath, ok := d.config.ImageChartMap[imageName]; ok {
+		return strings.TrimSpace(chartPath)
 	}
+	return strings.TrimSpace(strings.ReplaceAll(d.config.HelmChartPath, "{{ image_name }}", imageName))
+}
+
+// releaseName renders RELEASE_NAME for an image, plus the suffix of a canary or blue/green release
+func (d *Deployer) releaseName(imageName string) string {
+	return strings.ReplaceAll(d.config.ReleaseName, "{{ image_name }}", imageName) + d.releaseSuffix
+}
+
+// resolveImageName determines the image name from the parameter, release name, or chart path
+func (d *Deployer) resolveImageName(imageName string) string {
+	name, _ := d.imageNameSource(imageName)
+	return name
+}
+
+// imageNameSource resolves the image name and describes where it came from for --explain
+func (d *Deployer) imageNameSource(imageName string) (string, string) {
+	if imageName != "" {
+		return imageName, "from --image"
+	}
+	if d.config.ImageName != "" {
+		return d.config.ImageName, "from the COMPOSE_FILE service image because --image is empty"
+	}
+	if d.config.ReleaseName != "" {
+		return d.config.ReleaseName, "from RELEASE_NAME because --image is empty"
+	}
+	// Extract from chart path if available
+	if strings.Contains(d.config.HelmChartPath, "{{") {
+		// Template not resolved, use a default
+		return "app", "default because --image and RELEASE_NAME are empty and HELM_CHART_PATH is templated"
+	}
+	// Extract from path
+	parts := strings.Split(strings.TrimSuffix(d.config.HelmChartPath, "/"), "/")
+	if len(parts) > 0 && parts[len(parts)-1] != "" {
+		return parts[len(parts)-1], "derived from chart path because --image and RELEASE_NAME are empty"
+	}
+	return "app", "default because --image, RELEASE_NAME, and HELM_CHART_PATH are empty"
+}
 
-	if err := d.helmClient.CheckKubectl(); err != nil {
+// preflightChecks validates all prerequisites
+func (d *Deployer) preflightChecks() error {
+	d.log.Infof("Running pre-flight checks...")
+	d.log.Infof("Using container runtime: %s", d.dockerClient.Runtime())
+
+	if err := runPreflightChecks(d.preflightCheckList(), d.config.ParallelPreflight, d.log); err != nil {
 		return err
 	}
 
 	// We'll check chart path during deployment as it may contain templates
-	log.Println("Pre-flight checks passed")
+	d.log.Infof("Pre-flight checks passed")
 	return nil
 }
 
+// preflightCheckList returns the configured pre-flight checks; audits list their commands
+func (d *Deployer) preflightCheckList() []preflightCheck {
+	runtime, kubeCLI := d.dockerClient.Runtime(), d.helmClient.KubeCLI()
+	checks := []preflightCheck{
+		{name: runtime, run: d.dockerClient.CheckDocker, commands: [][]string{d.plannedCommand(runtime, docker.VersionArgs())}},
+		{name: "helm", run: d.helmClient.CheckHelm, commands: [][]string{d.plannedCommand("helm", helm.VersionArgs())}},
+		{name: kubeCLI, run: d.helmClient.CheckKubectl, commands: [][]string{d.plannedCommand(kubeCLI, helm.KubeVersionArgs())}},
+	}
+	if d.config.ScanBeforePush {
+		checks = append(checks, preflightCheck{name: "image scanner", run: d.scanner.Check})
+	}
+	if d.config.SignImage {
+		checks = append(checks, preflightCheck{name: "cosign", run: d.signer.Check})
+	}
+	for _, addr := range d.config.WaitForTCP {
+		timeout := time.Duration(d.config.WaitForTCPTimeout) * time.Second
+		checks = append(checks, preflightCheck{name: "dependency " + addr, run: waitForTCP(addr, timeout, 2*time.Second)})
+	}
+	if len(d.config.Kubeconfigs) == 0 {
+		checks = append(checks, d.clusterChecks()...)
+	}
+	for _, kubeconfig := range d.config.Kubeconfigs {
+		for _, check := range d.forCluster(kubeconfig).clusterChecks() {
+			check.name = clusterName(kubeconfig) + " " + check.name
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// clusterChecks returns the checks that depend on the target cluster; with KUBECONFIGS they run once per cluster
+func (d *Deployer) clusterChecks() []preflightCheck {
+	var checks []preflightCheck
+	kubeCLI := d.helmClient.KubeCLI()
+	if d.config.ExpectedContext != "" || d.config.ExpectedCluster != "" {
+		var commands [][]string
+		if d.config.ExpectedContext != "" {
+			commands = append(commands, d.plannedCommand(kubeCLI, helm.CurrentContextArgs()))
+		}
+		if d.config.ExpectedCluster != "" {
+			commands = append(commands, d.plannedCommand(kubeCLI, helm.ClusterServerArgs()))
+		}
+		checks = append(checks, preflightCheck{name: "kube context", commands: commands, run: func() error {
+			return d.helmClient.CheckContext(d.config.ExpectedContext, d.config.ExpectedCluster)
+		}})
+	}
+	if d.config.RequireExistingNamespace {
+		commands := [][]string{d.plannedCommand(kubeCLI, helm.GetNamespaceArgs(d.config.Namespace))}
+		checks = append(checks, preflightCheck{name: "namespace " + d.config.Namespace, commands: commands, run: func() error {
+			return d.helmClient.CheckNamespaceExists(d.config.Namespace)
+		}})
+	}
+	return checks
+}
+
 // syncImage handles the image pull, tag, and push process
-func (d *Deployer) syncImage(sourceImage, targetImage string, credentials *config.Credentials) error {
-	log.Println("Starting image sync process...")
+func (d *Deployer) syncImage(sourceImage, targetImage string, credentials *config.Credentials, budget *retryBudget) error {
+	d.log.Infof("Starting image sync process...")
 
 	// Login to Nexus
-	if err := d.dockerClient.Login(d.config.NexusRegistry, credentials.NexusUsername, credentials.NexusPassword); err != nil {
+	if err := budget.do("Nexus login", func() error {
+		return d.dockerClient.Login(d.config.NexusRegistry, credentials.NexusUsername, credentials.NexusPassword)
+	}); err != nil {
 		return err
 	}
 
-	// Pull from Nexus with retries
-	var pullErr error
-	for i := 0; i < 3; i++ {
-		if pullErr = d.dockerClient.Pull(sourceImage); pullErr == nil {
-			break
+	if d.config.PreserveManifestList {
+		if err := d.scanImage(sourceImage); err != nil {
+			return err
 		}
-		log.Printf("Pull attempt %d failed, retrying...", i+1)
+		return d.copyManifestList(sourceImage, targetImage, credentials, budget)
+	}
+
+	// Pull from Nexus with retries
+	if err := budget.do("Pull", func() error {
+		return d.dockerClient.Pull(sourceImage)
+	}); err != nil {
+		return err
 	}
-	if pullErr != nil {
-		return pullErr
+
+	// Block images with vulnerabilities before they reach Harbor
+	if err := d.scanImage(sourceImage); err != nil {
+		return err
 	}
 
 	// Tag for Harbor
@@ -236,93 +815,934 @@ func (d *Deployer) syncImage(sourceImage, targetImage string, credentials *confi
 	}
 
 	// Login to Harbor
-	if err := d.dockerClient.Login(d.config.HarborRegistry, credentials.HarborUsername, credentials.HarborPassword); err != nil {
+	if err := budget.do("Harbor login", func() error {
+		return d.dockerClient.Login(d.config.HarborRegistry, credentials.HarborUsername, credentials.HarborPassword)
+	}); err != nil {
 		return err
 	}
 
 	// Push to Harbor
-	if err := d.dockerClient.Push(targetImage); err != nil {
+	if err := budget.do("Push", func() error {
+		return d.dockerClient.Push(targetImage)
+	}); err != nil {
 		return err
 	}
 
-	log.Println("Image sync completed successfully")
+	d.log.Infof("Image sync completed successfully (%s)", budget)
+	return nil
+}
+
+// syncPhase copies the image to Harbor and records its size, digest, and signature
+func (d *Deployer) syncPhase(summary *Summary, credentials *config.Credentials) error {
+	// Every retried step draws from one budget so a flaky network can't multiply attempts
+	budget := newRetryBudget(d.config.SyncRetries, time.Duration(d.config.SyncTimeout)*time.Second, d.log)
+	budget.onRetry = d.emitRetry
+	return d.runSteps(d.syncSteps(summary, credentials, budget))
+}
+
+// syncSteps returns the steps of the image sync, in order
+func (d *Deployer) syncSteps(summary *Summary, credentials *config.Credentials, budget *retryBudget) []step {
+	runtime := d.dockerClient.Runtime()
+	return []step{
+		{
+			when: true,
+			run: func() error {
+				if err := d.syncer().Sync(summary.SourceImage, summary.TargetImage, credentials, budget); err != nil {
+					return fmt.Errorf("image sync failed: %w", err)
+				}
+				return nil
+			},
+			plan: func() [][]string { return d.syncer().Plan(summary.SourceImage, summary.TargetImage, credentials) },
+		},
+		{
+			when: true,
+			run: func() error {
+				if err := d.pushExtraTags(summary); err != nil {
+					return fmt.Errorf("image sync failed: %w", err)
+				}
+				return nil
+			},
+			plan: func() [][]string { return d.planExtraTags(summary) },
+		},
+		{
+			when: true,
+			run: func() error {
+				if err := d.pushMirrors(summary, credentials, budget); err != nil {
+					return fmt.Errorf("image sync failed: %w", err)
+				}
+				return nil
+			},
+			plan: func() [][]string { return d.planMirrors(summary, credentials) },
+		},
+		// Record how much data was moved for bandwidth accounting
+		{
+			when: !d.config.PreserveManifestList,
+			run: func() error {
+				info, err := d.dockerClient.Inspect(d.localImage(summary))
+				if err != nil {
+					if d.config.TagLabel != "" {
+						return fmt.Errorf("cannot verify TAG_LABEL %s: %w", d.config.TagLabel, err)
+					}
+					d.log.Warnf("Failed to read image size: %v", err)
+					return nil
+				}
+				summary.ImageSize = info.Size
+				d.log.Infof("Synced image size: %s", utils.HumanSize(info.Size))
+				if d.config.TagLabel != "" {
+					if err := verifyTagLabel(info, d.config.TagLabel, summary.ImageTag); err != nil {
+						return err
+					}
+					d.log.Infof("Image label %s matches tag %s", d.config.TagLabel, summary.ImageTag)
+				}
+				return nil
+			},
+			plan: func() [][]string {
+				return [][]string{d.plannedCommand(runtime, docker.InspectArgs(d.localImage(summary)))}
+			},
+		},
+		{
+			when: !d.config.PreserveManifestList,
+			run: func() error {
+				digest, err := d.resolvePushedDigest(summary.TargetImage)
+				if err != nil {
+					if d.config.DeployByDigest != "" {
+						return fmt.Errorf("DEPLOY_BY_DIGEST is set but the pushed digest is unknown: %w", err)
+					}
+					d.log.Warnf("%v", err)
+					return nil
+				}
+				if summary.ImageDigest != "" && summary.ImageDigest != digest {
+					d.log.Warnf("pushed digest %s differs from requested digest %s, deploying the pushed digest", digest, summary.ImageDigest)
+				}
+				summary.ImageDigest = digest
+				d.log.Infof("Pushed image digest: %s", digest)
+				return nil
+			},
+			plan: func() [][]string {
+				return [][]string{d.plannedCommand(runtime, docker.InspectArgs(summary.TargetImage))}
+			},
+		},
+		{
+			when: d.config.SignImage,
+			run:  func() error { return d.signer.Sign(signingRef(summary.TargetImage, summary.ImageDigest)) },
+			plan: func() [][]string {
+				return [][]string{d.plannedCommand("cosign", d.signer.Args(signingRef(summary.TargetImage, summary.ImageDigest)))}
+			},
+		},
+		{
+			when: d.config.DigestOutFile != "",
+			run: func() error {
+				if summary.ImageDigest == "" {
+					return fmt.Errorf("pushed image digest is not available to write to %s", d.config.DigestOutFile)
+				}
+				if err := os.WriteFile(d.config.DigestOutFile, []byte(summary.ImageDigest+"\n"), 0644); err != nil {
+					return fmt.Errorf("failed to write image digest: %w", err)
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// verifyTagLabel checks that the image's label (e.g. org.opencontainers.image.version) names the deployed tag
+func verifyTagLabel(info *docker.ImageInfo, label, tag string) error {
+	value, ok := info.Label(label)
+	if !ok {
+		return fmt.Errorf("image has no %s label to verify tag %s against", label, tag)
+	}
+	if value != tag {
+		return fmt.Errorf("image label %s=%s does not match tag %s", label, value, tag)
+	}
 	return nil
 }
 
-// deployWithHelm handles the Helm deployment process
-func (d *Deployer) deployWithHelm(chartPath, releaseName, imageTag string) error {
-	log.Println("Starting Helm deployment...")
+// release rolls the synced image out to the cluster: helm upgrade, health checks, and tests
+func (d *Deployer) release(chartPath string, summary *Summary, credentials *config.Credentials) (string, error) {
+	var notes string
+	if err := d.runSteps(d.releaseSteps(chartPath, summary, credentials, &notes)); err != nil {
+		return "", err
+	}
+	return notes, nil
+}
+
+// releaseSteps returns the steps of the release phase, in order; the upgrade stores the chart notes in notes
+func (d *Deployer) releaseSteps(chartPath string, summary *Summary, credentials *config.Credentials, notes *string) []step {
+	releaseName, namespace := summary.ReleaseName, d.config.Namespace
+	kubeCLI := d.helmClient.KubeCLI()
+	annotations := CIAnnotations(d.config.CIAnnotations, os.Getenv)
+	return []step{
+		// Never take over a release another team or tool manages
+		{
+			when: d.config.ExpectedReleaseOwner != "",
+			run:  func() error { return d.checkReleaseOwner(releaseName) },
+			plan: func() [][]string {
+				return [][]string{d.plannedCommand("helm", helm.MetadataArgs(releaseName, namespace))}
+			},
+		},
+		// The pods need Harbor credentials of their own to pull the image
+		{
+			when: d.config.CreatePullSecret != "",
+			run:  func() error { return d.ensurePullSecret(credentials) },
+			plan: func() [][]string { return [][]string{d.plannedCommand(kubeCLI, helm.ApplyArgs(namespace))} },
+		},
+		// Stuck pods from a previous bad tag can block the new rollout; which pods get deleted
+		// depends on the live cluster, so an audit only lists the lookup
+		{
+			when: d.config.CleanFailedPods,
+			run: func() error {
+				if err := d.helmClient.DeleteFailedPods(releaseName, namespace); err != nil {
+					d.log.Warnf("Failed to clean up failed pods: %v", err)
+				}
+				return nil
+			},
+			plan: func() [][]string {
+				return [][]string{d.plannedCommand(kubeCLI, helm.ReleasePodsArgs(releaseName, namespace))}
+			},
+		},
+		// Record the manifest change for review before applying it
+		{
+			when: d.config.CaptureManifests,
+			run: func() error {
+				if err := d.captureManifests(chartPath, summary); err != nil {
+					return fmt.Errorf("manifest capture failed: %w", err)
+				}
+				return nil
+			},
+			plan: func() [][]string {
+				return [][]string{
+					d.plannedCommand("helm", helm.GetManifestArgs(releaseName, namespace)),
+					d.plannedCommand("helm", helm.TemplateArgs(d.helmDeployOptions(chartPath, summary))),
+				}
+			},
+		},
+		{
+			when: d.config.KubectlDiff,
+			run: func() error {
+				d.previewKubeDiff(d.helmDeployOptions(chartPath, summary))
+				return nil
+			},
+			plan: func() [][]string {
+				return [][]string{
+					d.plannedCommand("helm", helm.TemplateArgs(d.helmDeployOptions(chartPath, summary))),
+					d.plannedCommand(kubeCLI, helm.KubeDiffArgs(namespace)),
+				}
+			},
+		},
+		// Recovering a release left pending by an earlier deploy depends on its live status and is not planned
+		{
+			when: true,
+			run: func() error {
+				var err error
+				if *notes, err = d.deployWithHelm(chartPath, summary); err != nil {
+					return fmt.Errorf("helm deployment failed: %w", err)
+				}
+				// Async deploys leave the rollout and every check after it to the caller
+				if !d.config.Wait {
+					d.log.Infof("Upgrade of %s submitted, not waiting for the rollout (WAIT=false)", releaseName)
+				}
+				return nil
+			},
+			plan: func() [][]string {
+				return [][]string{d.plannedCommand("helm", helm.DeployArgs(d.helmDeployOptions(chartPath, summary)))}
+			},
+		},
+		// Health check
+		{
+			when: d.config.Wait,
+			run: func() error {
+				if err := d.helmClient.CheckRolloutStatus(releaseName, namespace); err != nil {
+					return fmt.Errorf("health check failed: %w", err)
+				}
+				return nil
+			},
+			plan: func() [][]string {
+				return [][]string{d.plannedCommand(kubeCLI, helm.RolloutStatusArgs(releaseName, namespace))}
+			},
+		},
+		// Trace the release back to the pipeline run that deployed it; like log streaming, this never fails a deploy
+		{
+			when: len(annotations) > 0,
+			run: func() error {
+				if err := d.annotateCIRun(releaseName); err != nil {
+					d.log.Warnf("%v", err)
+				}
+				return nil
+			},
+			plan: func() [][]string {
+				return [][]string{d.plannedCommand(kubeCLI, helm.AnnotateArgs(releaseName, namespace, annotations))}
+			},
+		},
+		// Pods only restart on their own when the pod template changed
+		{
+			when: d.config.Wait && d.config.RolloutRestart,
+			run:  func() error { return d.helmClient.RolloutRestart(releaseName, namespace) },
+			plan: func() [][]string {
+				return [][]string{
+					d.plannedCommand(kubeCLI, helm.RolloutRestartArgs(releaseName, namespace)),
+					d.plannedCommand(kubeCLI, helm.RolloutStatusArgs(releaseName, namespace)),
+				}
+			},
+		},
+		// Confirm the service answers, not just that pods are ready
+		{
+			when: d.config.Wait && d.config.SmokeTestURL != "",
+			run:  d.runSmokeTest,
+		},
+		// Chart-provided test hooks
+		{
+			when: d.config.Wait && d.config.RunHelmTest,
+			run:  func() error { return d.helmClient.Test(releaseName, namespace) },
+			plan: func() [][]string { return [][]string{d.plannedCommand("helm", helm.TestArgs(releaseName, namespace))} },
+		},
+		// Keep watching pod health for canary-style verification
+		{
+			when: d.config.Wait && d.config.WatchWindow > 0,
+			run: func() error {
+				if err := d.watchHealth(releaseName); err != nil {
+					return fmt.Errorf("health watch failed: %w", err)
+				}
+				return nil
+			},
+			plan: func() [][]string {
+				var commands [][]string
+				for range watchPolls(d.config.WatchWindow, d.watchInterval()) {
+					commands = append(commands, d.plannedCommand(kubeCLI, helm.ReleasePodsArgs(releaseName, namespace)))
+				}
+				return commands
+			},
+		},
+		// Show startup logs; a log streaming problem never fails a healthy deploy
+		{
+			when: d.config.Wait && d.config.WatchLogs > 0,
+			run: func() error {
+				if err := d.helmClient.WatchLogs(releaseName, namespace, d.config.WatchLogs); err != nil {
+					d.log.Warnf("%v", err)
+				}
+				return nil
+			},
+			plan: func() [][]string { return [][]string{d.plannedCommand(kubeCLI, helm.LogsArgs(releaseName, namespace))} },
+		},
+	}
+}
+
+// buildDependencies fetches the subcharts of a local chart that declares any
+func (d *Deployer) buildDependencies(chartPath string) error {
+	if helm.IsRemoteChart(chartPath) {
+		return nil
+	}
+	needed, err := helm.HasDependencies(chartPath)
+	if err != nil {
+		return err
+	}
+	if !needed {
+		d.log.Debugf("Chart %s declares no dependencies", chartPath)
+		return nil
+	}
+	if err := d.helmClient.BuildDependencies(chartPath); err != nil {
+		return fmt.Errorf("chart dependency build failed: %w", err)
+	}
+	return nil
+}
+
+// lintChart runs helm lint with the upgrade's values; helm can only lint a chart on disk
+func (d *Deployer) lintChart(chartPath string, summary *Summary) error {
+	if helm.IsRemoteChart(chartPath) {
+		d.log.Warnf("Skipping chart lint: %s is not a local chart (set PARALLEL_PHASES=true to pull it first)", chartPath)
+		return nil
+	}
+	if err := d.helmClient.Lint(d.helmDeployOptions(chartPath, summary)); err != nil {
+		return fmt.Errorf("chart lint failed: %w", err)
+	}
+	return nil
+}
+
+// syncAndPullChart runs the image sync and the remote chart download concurrently, returning the local chart path
+func (d *Deployer) syncAndPullChart(summary *Summary, credentials *config.Credentials) (string, func(), error) {
+	chartDir, err := os.MkdirTemp("", "sbi-chart-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create chart directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(chartDir) }
+
+	d.log.Infof("Syncing image and pulling chart %s in parallel...", summary.ChartPath)
+
+	var (
+		wg        sync.WaitGroup
+		syncErr   error
+		chartErr  error
+		chartPath string
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		syncErr = d.withLogger(logging.WithPrefix("["+summary.ImageName+"]")).syncPhase(summary, credentials)
+	}()
+	go func() {
+		defer wg.Done()
+		chartPath, chartErr = d.helmClient.WithLogger(logging.WithPrefix("[chart]")).PullChart(summary.ChartPath, d.config.ChartVersion, chartDir)
+		if chartErr != nil {
+			chartErr = fmt.Errorf("chart pull failed: %w", chartErr)
+		}
+	}()
+	wg.Wait()
+
+	if err := errors.Join(syncErr, chartErr); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return chartPath, cleanup, nil
+}
+
+// verifyTargetImage confirms an image already exists in Harbor when sync is skipped
+func (d *Deployer) verifyTargetImage(targetImage string, credentials *config.Credentials) error {
+	d.log.Infof("Skipping image sync, deploying existing image %s", targetImage)
+	if d.config.TagLabel != "" {
+		d.log.Warnf("TAG_LABEL %s is not verified when the image sync is skipped", d.config.TagLabel)
+	}
+	if !d.config.VerifyTargetImage {
+		return nil
+	}
+
+	if err := d.dockerClient.Login(d.config.HarborRegistry, credentials.HarborUsername, credentials.HarborPassword); err != nil {
+		return err
+	}
+	if err := d.dockerClient.ManifestExists(targetImage); err != nil {
+		return fmt.Errorf("image sync was skipped but the target image is missing: %w", err)
+	}
+
+	d.log.Infof("Verified %s exists in Harbor", targetImage)
+	return nil
+}
+
+// resolvePushedDigest reads the registry digest of a pushed image, waiting briefly for it to appear
+func (d *Deployer) resolvePushedDigest(image string) (string, error) {
+	repository, _ := docker.SplitReference(image)
+	for attempt := 1; attempt <= 3; attempt++ {
+		info, err := d.dockerClient.Inspect(image)
+		if err != nil {
+			return "", err
+		}
+		if digest := info.DigestFor(repository); digest != "" {
+			return digest, nil
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+	return "", fmt.Errorf("no registry digest recorded for %s after push", image)
+}
+
+// signingRef returns the digest-pinned reference for an image when the digest is known
+func signingRef(image, digest string) string {
+	if digest == "" {
+		return image
+	}
+	repository, _ := docker.SplitReference(image)
+	return repository + "@" + digest
+}
+
+// scanImage runs the vulnerability gate when SCAN_BEFORE_PUSH is enabled
+func (d *Deployer) scanImage(image string) error {
+	if !d.config.ScanBeforePush {
+		return nil
+	}
+	return d.scanner.Scan(image)
+}
+
+// copyManifestList syncs every architecture of a multi-arch image registry-to-registry
+func (d *Deployer) copyManifestList(sourceImage, targetImage string, credentials *config.Credentials, budget *retryBudget) error {
+	// Both registries must be authenticated since buildx reads and writes remotely
+	if err := budget.do("Harbor login", func() error {
+		return d.dockerClient.Login(d.config.HarborRegistry, credentials.HarborUsername, credentials.HarborPassword)
+	}); err != nil {
+		return err
+	}
+
+	if err := budget.do("Manifest list copy", func() error {
+		return d.dockerClient.CopyManifestList(sourceImage, targetImage)
+	}); err != nil {
+		return err
+	}
+
+	d.log.Infof("Image sync completed successfully (manifest list preserved, %s)", budget)
+	return nil
+}
+
+// deployWithHelm handles the Helm deployment process and returns the chart NOTES
+func (d *Deployer) deployWithHelm(chartPath string, summary *Summary) (string, error) {
+	d.log.Infof("Starting Helm deployment...")
+	releaseName := summary.ReleaseName
 
 	// Check chart path
 	if err := d.helmClient.CheckChartPath(chartPath); err != nil {
-		return err
+		return "", err
 	}
 
 	// Deploy with Helm
-	if err := d.helmClient.Deploy(chartPath, releaseName, d.config.Namespace, imageTag, d.config.Timeout); err != nil {
+	notes, err := d.helmClient.Deploy(d.helmDeployOptions(chartPath, summary))
+	if errors.Is(err, helm.ErrOperationInProgress) {
+		if recoverErr := d.recoverPendingRelease(releaseName, err); recoverErr != nil {
+			// Rolling back on top of a pending operation would fail the same way
+			return "", recoverErr
+		}
+		notes, err = d.helmClient.Deploy(d.helmDeployOptions(chartPath, summary))
+	}
+	if err != nil {
 		// Attempt rollback if enabled
 		if d.config.EnableRollback {
-			log.Println("Deployment failed, attempting rollback...")
-			if rollbackErr := d.helmClient.Rollback(releaseName); rollbackErr != nil {
-				log.Printf("Rollback also failed: %v", rollbackErr)
+			d.log.Infof("Deployment failed, attempting rollback...")
+			if rollbackErr := d.rollbackAndVerify(releaseName); rollbackErr != nil {
+				d.log.Errorf("%v", rollbackErr)
+				return "", fmt.Errorf("%w (%v)", err, rollbackErr)
 			}
+			return "", fmt.Errorf("%w (rolled back to previous revision)", err)
 		}
+		return "", err
+	}
+
+	d.log.Infof("Helm deployment completed successfully")
+	if notes != "" {
+		d.log.Infof("Chart notes:\n%s", notes)
+	}
+	return notes, nil
+}
+
+// helmDeployOptions builds the helm upgrade options for a deployment from the configuration
+func (d *Deployer) helmDeployOptions(chartPath string, s *Summary) helm.DeployOptions {
+	opts := helm.DeployOptions{
+		ChartPath:       chartPath,
+		ReleaseName:     s.ReleaseName,
+		Namespace:       d.config.Namespace,
+		ImageTag:        s.TargetTag,
+		ImageTagKey:     d.config.ImageTagKey,
+		ImageDigest:     s.ImageDigest,
+		ImageDigestKey:  d.config.ImageDigestKey,
+		Timeout:         d.config.Timeout,
+		ChartVersion:    d.config.ChartVersion,
+		SetValues:       d.config.SetValues,
+		CreateNamespace: d.config.HelmCreateNamespace,
+		Labels:          d.ownerLabels(),
+		NoWait:          !d.config.Wait,
+		PullSecret:      d.config.CreatePullSecret,
+	}
+	opts.ValuesFiles = d.valuesFiles
+	// Charts without a digest value still pin the image through a tag@digest reference
+	if d.config.DeployByDigest == "tag" && s.ImageDigest != "" {
+		opts.ImageTag = s.TargetTag + "@" + s.ImageDigest
+		opts.ImageDigest = ""
+	}
+	// Point the chart at the Harbor copy rather than whatever registry its values name
+	if d.config.SetImageRepository {
+		opts.ImageRepository, _ = docker.SplitReference(s.TargetImage)
+		opts.ImageRepositoryKey = repositoryKey(d.config.ImageTagKey)
+	}
+	return opts
+}
+
+// recoverPendingRelease rolls a release stuck in a pending state back to its last deployed revision
+func (d *Deployer) recoverPendingRelease(releaseName string, pendingErr error) error {
+	if !d.config.AutoRecoverPending {
+		return fmt.Errorf("%w; run 'helm history %s -n %s' and 'helm rollback %s <revision> -n %s', or set AUTO_RECOVER_PENDING=true",
+			pendingErr, releaseName, d.config.Namespace, releaseName, d.config.Namespace)
+	}
+
+	revision, err := d.helmClient.LastDeployedRevision(releaseName, d.config.Namespace)
+	if err != nil {
+		return fmt.Errorf("%w; automatic recovery failed: %v", pendingErr, err)
+	}
+
+	d.log.Infof("Release %s has a pending operation, rolling back to last deployed revision %d...", releaseName, revision)
+	if err := d.helmClient.Rollback(releaseName, d.config.Namespace, revision); err != nil {
+		return fmt.Errorf("%w; automatic recovery failed: %v", pendingErr, err)
+	}
+
+	d.log.Infof("Recovered pending release, retrying deployment...")
+	return nil
+}
+
+// rollbackAndVerify rolls back a release and confirms the previous revision is healthy
+func (d *Deployer) rollbackAndVerify(releaseName string) error {
+	d.emit(Event{Type: EventRollback, Release: releaseName})
+	if err := d.rollback(releaseName); err != nil {
+		return fmt.Errorf("rollback failed: %w", err)
+	}
+	d.log.Infof("Rollback completed, verifying previous revision health...")
+
+	if err := d.helmClient.CheckRolloutStatus(releaseName, d.config.Namespace); err != nil {
+		return fmt.Errorf("rollback succeeded but release %s is still unhealthy: %w", releaseName, err)
+	}
+
+	d.log.Infof("Rollback verified: previous revision is healthy")
+	return nil
+}
+
+// rollback rolls a release back to its previous revision. With FORCE_ROLLBACK_RECOVERY, a
+// rollback still running after ROLLBACK_GRACE_PERIOD is killed, the release's stuck pods are
+// force-deleted, and the rollback is retried once within the same grace period.
+func (d *Deployer) rollback(releaseName string) error {
+	if !d.config.ForceRollbackRecovery {
+		return d.helmClient.Rollback(releaseName, d.config.Namespace, 0)
+	}
+
+	// The killed attempt may have recorded a revision of its own, so the retry names the
+	// target explicitly instead of rolling back to "previous" again
+	target := 0
+	if current, err := d.helmClient.CurrentRevision(releaseName, d.config.Namespace); err != nil {
+		d.log.Warnf("Could not read current revision of %s: %v", releaseName, err)
+	} else if current > 1 {
+		target = current - 1
+	}
+
+	grace := time.Duration(d.config.RollbackGracePeriod) * time.Second
+	err := d.helmClient.RollbackWithin(releaseName, d.config.Namespace, target, grace)
+	if !errors.Is(err, helm.ErrRollbackTimeout) {
 		return err
 	}
 
-	log.Println("Helm deployment completed successfully")
+	d.log.Warnf("%v; force-deleting stuck pods and retrying once (FORCE_ROLLBACK_RECOVERY)", err)
+	if err := d.helmClient.ForceDeleteStuckPods(releaseName, d.config.Namespace); err != nil {
+		return fmt.Errorf("forced recovery failed: %w", err)
+	}
+	if err := d.helmClient.RollbackWithin(releaseName, d.config.Namespace, target, grace); err != nil {
+		return fmt.Errorf("retry after forced recovery failed: %w", err)
+	}
+	return nil
+}
+
+// watchInterval is the time between WATCH_WINDOW pod health polls
+func (d *Deployer) watchInterval() time.Duration {
+	if d.config.WatchInterval <= 0 {
+		return 10 * time.Second
+	}
+	return d.config.WatchInterval
+}
+
+// watchPolls is the number of pod health polls watchHealth makes in window, not counting the polls' own time
+func watchPolls(window, interval time.Duration) int {
+	return int(window/interval) + 1
+}
+
+// watchHealth polls pod readiness for the configured window, failing on the first unhealthy poll
+func (d *Deployer) watchHealth(releaseName string) error {
+	interval := d.watchInterval()
+	d.log.Infof("Watching pod health for %s (every %s)...", d.config.WatchWindow, interval)
+
+	deadline := time.Now().Add(d.config.WatchWindow)
+	for poll := 1; ; poll++ {
+		if err := d.helmClient.CheckPodsReady(releaseName, d.config.Namespace); err != nil {
+			return fmt.Errorf("poll %d: %w", poll, err)
+		}
+		if time.Now().Add(interval).After(deadline) {
+			break
+		}
+		time.Sleep(interval)
+	}
+
+	d.log.Infof("Pods stayed healthy for %s", d.config.WatchWindow)
 	return nil
 }
 
+// ForceRollback rolls a release back independent of any deploy state and returns the resulting revision
+func (d *Deployer) ForceRollback(releaseName, namespace string, revision int) (int, error) {
+	if revision > 0 {
+		d.log.Infof("Rolling back %s in namespace %s to revision %d...", releaseName, namespace, revision)
+	} else {
+		d.log.Infof("Rolling back %s in namespace %s to the previous revision...", releaseName, namespace)
+	}
+
+	if err := d.helmClient.Rollback(releaseName, namespace, revision); err != nil {
+		return 0, err
+	}
+
+	if err := d.helmClient.CheckRolloutStatus(releaseName, namespace); err != nil {
+		return 0, fmt.Errorf("rollback succeeded but release %s is unhealthy: %w", releaseName, err)
+	}
+
+	current, err := d.helmClient.CurrentRevision(releaseName, namespace)
+	if err != nil {
+		return 0, err
+	}
+	return current, nil
+}
+
+// RollbackResult records the outcome of rolling back one release
+type RollbackResult struct {
+	Release  string
+	Revision int
+	Err      error
+}
+
+// RollbackAll rolls every release in a namespace back to its previous revision
+func (d *Deployer) RollbackAll(namespace string) ([]RollbackResult, error) {
+	releases, err := d.helmClient.ListReleases(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []RollbackResult
+	for _, release := range releases {
+		if release.Revision <= 1 {
+			results = append(results, RollbackResult{
+				Release:  release.Name,
+				Revision: release.Revision,
+				Err:      fmt.Errorf("no previous revision to roll back to"),
+			})
+			continue
+		}
+
+		d.log.Infof("Rolling back %s from revision %d...", release.Name, release.Revision)
+		result := RollbackResult{Release: release.Name}
+		if err := d.helmClient.Rollback(release.Name, namespace, 0); err != nil {
+			result.Err = err
+		} else {
+			result.Revision, result.Err = d.helmClient.CurrentRevision(release.Name, namespace)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
 // dryRunDeploy shows what would be done without executing
-func (d *Deployer) dryRunDeploy(imageTag, imageName string, credentials *config.Credentials) error {
-	log.Println("=== DRY RUN MODE - No actual operations will be performed ===")
-	
-	// Determine image name
-	if imageName == "" {
-		imageName = d.config.ReleaseName
-		if imageName == "" {
-			imageName = "app"
-		}
-	}
-	
-	sourceImage := fmt.Sprintf("%s/%s:%s", d.config.NexusRegistry, imageName, imageTag)
-	targetImage := fmt.Sprintf("%s/%s:%s", d.config.HarborRegistry, imageName, imageTag)
-	chartPath := strings.ReplaceAll(d.config.HelmChartPath, "{{ image_name }}", imageName)
-	releaseName := strings.ReplaceAll(d.config.ReleaseName, "{{ image_name }}", imageName)
-
-	log.Printf("1. Pre-flight checks:")
-	log.Printf("   ✓ Would check Docker availability")
-	log.Printf("   ✓ Would check Helm availability")
-	log.Printf("   ✓ Would check kubectl availability")
-	log.Printf("   ✓ Would check chart path: %s", chartPath)
-
-	log.Printf("2. Image sync operations:")
-	log.Printf("   ✓ Would login to Nexus registry: %s", d.config.NexusRegistry)
-	log.Printf("   ✓ Would pull image: %s", sourceImage)
-	log.Printf("   ✓ Would tag image: %s -> %s", sourceImage, targetImage)
-	log.Printf("   ✓ Would login to Harbor registry: %s", d.config.HarborRegistry)
-	log.Printf("   ✓ Would push image: %s", targetImage)
-
-	log.Printf("3. Helm deployment:")
-	log.Printf("   ✓ Would deploy using chart: %s", chartPath)
-	log.Printf("   ✓ Would set release name: %s", releaseName)
-	log.Printf("   ✓ Would deploy to namespace: %s", d.config.Namespace)
-	log.Printf("   ✓ Would set image tag: %s", imageTag)
-	log.Printf("   ✓ Would wait for deployment (timeout: %ds)", d.config.Timeout)
-	
+func (d *Deployer) dryRunDeploy(imageTag, imageName string, credentials *config.Credentials) (*Summary, error) {
+	d.log.Infof("=== DRY RUN MODE - No actual operations will be performed ===")
+
+	// Determine image name the same way a real deploy does
+	imageName = d.resolveImageName(imageName)
+
+	targetTag, err := d.targetTag(imageTag)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceImage := docker.ImageRef(d.config.NexusRegistry, imageName, imageTag)
+	targetImage := docker.ImageRef(d.config.HarborRegistry, imageName, targetTag)
+	chartPath := d.resolveChartPath(imageName)
+	releaseName := d.releaseName(imageName)
+
+	endPhase := d.startPhase(PhasePreflight)
+	d.log.Infof("1. Pre-flight checks:")
+	d.log.Infof("   ✓ Would check %s availability", d.dockerClient.Runtime())
+	d.log.Infof("   ✓ Would check Helm availability")
+	d.log.Infof("   ✓ Would check %s availability", d.helmClient.KubeCLI())
+	if d.config.ExpectedContext != "" {
+		d.log.Infof("   ✓ Would require kube context: %s", d.config.ExpectedContext)
+	}
+	if d.config.RequireExistingNamespace {
+		d.log.Infof("   ✓ Would require existing namespace: %s", d.config.Namespace)
+	}
+	if d.config.HelmCreateNamespace {
+		d.log.Infof("   ✓ Would create namespace %s if missing (helm --create-namespace)", d.config.Namespace)
+	}
+	for _, addr := range d.config.WaitForTCP {
+		d.log.Infof("   ✓ Would wait up to %ds for dependency: %s", d.config.WaitForTCPTimeout, addr)
+	}
+	if d.config.ExpectedCluster != "" {
+		d.log.Infof("   ✓ Would require cluster server: %s", d.config.ExpectedCluster)
+	}
+	d.log.Infof("   ✓ Would check chart path: %s", chartPath)
+	if d.config.SkipUnchanged {
+		d.log.Infof("   ✓ Would skip deploy if %s already has %s=%s", releaseName, d.config.ImageTagKey, targetTag)
+	}
+	if d.config.CheckChartImage {
+		repository, _ := docker.SplitReference(targetImage)
+		d.log.Infof("   ✓ Would check chart %s matches %s (strict: %t)", repositoryKey(d.config.ImageTagKey), repository, d.config.StrictChartImageMatch)
+	}
+
+	endPhase(nil)
+	endPhase = d.startPhase(PhaseSync)
+	d.log.Infof("2. Image sync operations:")
+	if d.config.SkipSync {
+		d.log.Infof("   ✓ Would skip image sync and deploy existing image: %s", targetImage)
+		if d.config.VerifyTargetImage {
+			d.log.Infof("   ✓ Would verify target image exists in Harbor: %s", targetImage)
+		}
+	} else {
+		d.dryRunSync(sourceImage, targetImage)
+	}
+
+	endPhase(nil)
+	endPhase = d.startPhase(PhaseRelease)
+	d.log.Infof("3. Helm deployment:")
+	if d.config.CleanFailedPods {
+		d.log.Infof("   ✓ Would delete ImagePullBackOff/ErrImagePull pods matching %s", helm.ReleaseSelector(releaseName))
+	}
+	d.log.Infof("   ✓ Would deploy using chart: %s", chartPath)
+	if helm.OCIChartOnRegistry(chartPath, d.config.HarborRegistry) {
+		d.log.Infof("   ✓ Would log helm in to %s with the Harbor credentials", helm.RegistryHost(chartPath))
+	}
+	if d.config.ChartVersion != "" && helm.IsRemoteChart(chartPath) {
+		d.log.Infof("   ✓ Would pin chart version: %s", d.config.ChartVersion)
+	}
+	d.log.Infof("   ✓ Would set release name: %s", releaseName)
+	d.log.Infof("   ✓ Would deploy to namespace: %s", d.config.Namespace)
+	if d.config.TagLabel != "" {
+		d.log.Infof("   ✓ Would verify image label %s equals %s", d.config.TagLabel, imageTag)
+	}
+	d.log.Infof("   ✓ Would set image tag: %s=%s", d.config.ImageTagKey, targetTag)
+	switch d.config.DeployByDigest {
+	case "digest":
+		d.log.Infof("   ✓ Would require the pushed digest and set %s=<digest>", d.config.ImageDigestKey)
+	case "tag":
+		d.log.Infof("   ✓ Would require the pushed digest and set %s=%s@<digest>", d.config.ImageTagKey, targetTag)
+	}
+	if d.config.SetImageRepository {
+		targetRepository, _ := docker.SplitReference(targetImage)
+		d.log.Infof("   ✓ Would set image repository: %s=%s", repositoryKey(d.config.ImageTagKey), targetRepository)
+	}
+	if docker.IsDigest(imageTag) {
+		d.log.Infof("   ✓ Would set image digest: %s=%s", d.config.ImageDigestKey, imageTag)
+	}
+	if d.config.ExpectedReleaseOwner != "" {
+		d.log.Infof("   ✓ Would verify release owner: %s=%s", d.config.ReleaseOwnerLabel, d.config.ExpectedReleaseOwner)
+	}
+	if annotations := CIAnnotations(d.config.CIAnnotations, os.Getenv); len(annotations) > 0 {
+		d.log.Infof("   ✓ Would annotate workloads matching %s with %s", helm.ReleaseSelector(releaseName), annotationList(annotations))
+	}
+	if d.config.CreatePullSecret != "" {
+		d.log.Infof("   ✓ Would create or update pull secret %s in %s for %s", d.config.CreatePullSecret, d.config.Namespace, d.config.HarborRegistry)
+		d.log.Infof("   ✓ Would set value: %s=%s", helm.PullSecretValueKey, d.config.CreatePullSecret)
+	}
+	if d.config.ValuesConfigMap != "" {
+		d.log.Infof("   ✓ Would pass base values from configmap %s in %s with --values", d.config.ValuesConfigMap, d.config.Namespace)
+	}
+	if d.config.ValuesScript != "" {
+		d.log.Infof("   ✓ Would pass values generated by %s with --values", d.config.ValuesScript)
+	}
+	for key, value := range d.config.SetValues {
+		d.log.Infof("   ✓ Would set value: %s=%s", key, value)
+	}
+	planned := &Summary{ReleaseName: releaseName, TargetTag: targetTag, TargetImage: targetImage}
+	if docker.IsDigest(imageTag) {
+		planned.ImageDigest = imageTag
+	}
+	d.log.Infof("   Values compared to the live release:")
+	d.dryRunValuesDiff(d.helmDeployOptions(chartPath, planned))
+	if d.config.KubectlDiff {
+		d.previewKubeDiff(d.helmDeployOptions(chartPath, planned))
+	}
+	if d.config.BuildDependencies && !helm.IsRemoteChart(chartPath) {
+		if needed, err := helm.HasDependencies(chartPath); err != nil {
+			d.log.Warnf("Could not check chart dependencies: %v", err)
+		} else if needed {
+			d.log.Infof("   ✓ Would run: helm %s", strings.Join(helm.DependencyBuildArgs(chartPath), " "))
+		}
+	}
+	if d.config.LintChart {
+		if helm.IsRemoteChart(chartPath) {
+			d.log.Infof("   ✓ Would skip lint of remote chart %s", chartPath)
+		} else {
+			d.log.Infof("   ✓ Would run: helm %s", strings.Join(helm.LintArgs(d.helmDeployOptions(chartPath, planned)), " "))
+		}
+	}
+	if d.config.CaptureManifests {
+		currentPath, newPath := manifestPaths(d.config.ManifestDir, releaseName)
+		d.log.Infof("   ✓ Would capture manifests to %s and %s", currentPath, newPath)
+	}
+	if len(d.config.Kubeconfigs) > 0 {
+		d.log.Infof("   ✓ Would release to %d clusters, at most %d at a time: %s", len(d.config.Kubeconfigs), d.config.MaxParallelClusters, strings.Join(d.config.Kubeconfigs, ", "))
+	}
+	if d.config.Wait {
+		d.log.Infof("   ✓ Would wait for deployment (timeout: %ds)", d.config.Timeout)
+	} else {
+		d.log.Infof("   ✓ Would submit the upgrade without waiting; rollout checks are skipped (WAIT=false)")
+	}
+	if d.config.RolloutRestart {
+		d.log.Infof("   ✓ Would run: %s %s", d.helmClient.KubeCLI(), strings.Join(helm.RolloutRestartArgs(d.releaseName(imageName), d.config.Namespace), " "))
+	}
+	if d.config.WatchLogs > 0 {
+		d.log.Infof("   ✓ Would stream pod logs for %s: %s %s", d.config.WatchLogs, d.helmClient.KubeCLI(), strings.Join(helm.LogsArgs(d.releaseName(imageName), d.config.Namespace), " "))
+	}
+	if d.config.SmokeTestURL != "" {
+		d.log.Infof("   ✓ Would smoke test %s expecting status %d (timeout: %ds)", d.config.SmokeTestURL, d.config.SmokeTestExpectStatus, d.config.SmokeTestTimeout)
+	}
+
 	if d.config.EnableRollback {
-		log.Printf("   ✓ Rollback is enabled if deployment fails")
+		d.log.Infof("   ✓ Rollback is enabled if deployment fails")
+		if d.config.ForceRollbackRecovery {
+			d.log.Infof("   ✓ Would force-delete stuck pods and retry once if the rollback takes over %ds", d.config.RollbackGracePeriod)
+		}
+		d.log.Infof("   ✓ Would verify rollout status after rollback")
 	}
 
-	log.Printf("4. Health check:")
-	log.Printf("   ✓ Would check rollout status for deployment/%s in namespace %s", releaseName, d.config.Namespace)
+	if d.config.Wait {
+		d.log.Infof("4. Health check:")
+		d.log.Infof("   ✓ Would check rollout status for deployment/%s in namespace %s", releaseName, d.config.Namespace)
+	}
 
-	if d.config.EnableCleanup {
-		log.Printf("5. Cleanup:")
-		log.Printf("   ✓ Would remove local image: %s", targetImage)
+	if d.config.RunHelmTest {
+		d.log.Infof("   ✓ Would run helm test for %s", releaseName)
+	}
+	if d.config.WatchWindow > 0 {
+		d.log.Infof("   ✓ Would watch pod readiness for %s", d.config.WatchWindow)
 	}
 
-	log.Printf("=== DRY RUN COMPLETED - All operations would succeed ===")
-	return nil
-}
\ No newline at end of file
+	endPhase(nil)
+
+	if d.config.EnableCleanup && !d.config.PreserveManifestList && !d.config.SkipSync {
+		endCleanup := d.startPhase(PhaseCleanup)
+		d.log.Infof("5. Cleanup:")
+		images := d.localImages(targetImage, sourceImage, d.extraTagRefs(targetImage, time.Now()), d.mirrorRefs(targetImage))
+		d.log.Infof("   ✓ Would remove local images: %s", strings.Join(images, ", "))
+		endCleanup(nil)
+	}
+
+	d.log.Infof("=== DRY RUN COMPLETED - All operations would succeed ===")
+	return &Summary{
+		ImageName:   imageName,
+		ImageTag:    imageTag,
+		TargetTag:   targetTag,
+		SourceImage: sourceImage,
+		TargetImage: targetImage,
+		ChartPath:   chartPath,
+		ReleaseName: releaseName,
+		Namespace:   d.config.Namespace,
+		DryRun:      true,
+	}, nil
+}
+
+// dryRunSync shows the image sync operations that would be performed
+func (d *Deployer) dryRunSync(sourceImage, targetImage string) {
+	if d.config.BuildContext != "" {
+		d.log.Infof("   ✓ Would build image: %s %s", d.dockerClient.Runtime(),
+			strings.Join(docker.BuildArgs(targetImage, d.config.BuildContext, d.config.Dockerfile, d.config.DockerPlatform, d.config.BuildArgs), " "))
+		if d.config.ScanBeforePush {
+			d.log.Infof("   ✓ Would scan image: %s %s", d.scanner.Command(), strings.Join(d.scanner.Args(targetImage), " "))
+		}
+		d.log.Infof("   ✓ Would login to Harbor registry: %s", d.config.HarborRegistry)
+		d.log.Infof("   ✓ Would push image: %s", targetImage)
+		for _, ref := range d.extraTagRefs(targetImage, time.Now()) {
+			d.log.Infof("   ✓ Would also push tag: %s", ref)
+		}
+		for _, ref := range d.mirrorRefs(targetImage) {
+			d.log.Infof("   ✓ Would mirror image to: %s", ref)
+		}
+		if d.config.SignImage {
+			d.log.Infof("   ✓ Would sign image: cosign %s", strings.Join(d.signer.Args(targetImage), " "))
+		}
+		return
+	}
+	d.log.Infof("   ✓ Would login to Nexus registry: %s", d.config.NexusRegistry)
+	if d.config.ScanBeforePush {
+		d.log.Infof("   ✓ Would scan image: %s %s", d.scanner.Command(), strings.Join(d.scanner.Args(sourceImage), " "))
+	}
+	if d.config.PreserveManifestList {
+		d.log.Infof("   ✓ Would login to Harbor registry: %s", d.config.HarborRegistry)
+		d.log.Infof("   ✓ Would copy manifest list: %s -> %s", sourceImage, targetImage)
+	} else {
+		d.log.Infof("   ✓ Would pull image: %s %s", d.dockerClient.Runtime(), strings.Join(docker.PullArgs(sourceImage, d.config.DockerPlatform), " "))
+		d.log.Infof("   ✓ Would tag image: %s -> %s", sourceImage, targetImage)
+		d.log.Infof("   ✓ Would login to Harbor registry: %s", d.config.HarborRegistry)
+		d.log.Infof("   ✓ Would push image: %s", targetImage)
+	}
+	for _, ref := range d.extraTagRefs(targetImage, time.Now()) {
+		d.log.Infof("   ✓ Would also push tag: %s", ref)
+	}
+	for _, ref := range d.mirrorRefs(targetImage) {
+		if d.config.PreserveManifestList {
+			d.log.Infof("   ✓ Would copy manifest list to mirror: %s", ref)
+		} else {
+			d.log.Infof("   ✓ Would mirror image to: %s", ref)
+		}
+	}
+	if d.config.SignImage {
+		d.log.Infof("   ✓ Would sign image: cosign %s", strings.Join(d.signer.Args(targetImage), " "))
+	}
+}
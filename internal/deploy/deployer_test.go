@@ -0,0 +1,444 @@
+package deploy
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/docker"
+	"sbi-deployment/internal/logging"
+	"sbi-deployment/internal/testutil"
+)
+
+func TestLocalImages(t *testing.T) {
+	target := "harbor.example.com/web:v1"
+	source := "nexus.example.com/web:v1"
+	extra := []string{"harbor.example.com/web:deployed-20260101-1200"}
+	mirrors := []string{"harbor2.example.com/web:v1"}
+
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want []string
+	}{
+		{
+			name: "nexus sync",
+			want: []string{target, extra[0], mirrors[0], source},
+		},
+		{
+			name: "build context has no source image",
+			cfg:  config.Config{BuildContext: "."},
+			want: []string{target, extra[0], mirrors[0]},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Deployer{config: &tt.cfg}
+			if got := d.localImages(target, source, extra, mirrors); !slices.Equal(got, tt.want) {
+				t.Errorf("localImages() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRollbackAndVerify(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{
+		"helm":    "exit 0",
+		"kubectl": `echo 'deployment "web" successfully rolled out'`,
+	})
+	d := New(&config.Config{Namespace: "prod"}, false)
+	if err := d.rollbackAndVerify("web"); err != nil {
+		t.Fatalf("rollbackAndVerify() error = %v", err)
+	}
+	want := []string{"helm rollback web --namespace prod", "kubectl rollout status deployment/web -n prod"}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestRollbackAndVerifyFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		helm    string
+		kubectl string
+		want    string
+	}{
+		{name: "rollback fails", helm: "echo 'Error: no revision' >&2; exit 1", kubectl: "exit 0", want: "rollback failed"},
+		{name: "still unhealthy", helm: "exit 0", kubectl: "echo 'error: timed out waiting' >&2; exit 1", want: "rollback succeeded but release web is still unhealthy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.FakeTools(t, map[string]string{"helm": tt.helm, "kubectl": tt.kubectl})
+			err := New(&config.Config{Namespace: "prod"}, false).rollbackAndVerify("web")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("rollbackAndVerify() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestForceRollback(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{
+		"helm":    `[ "$1" = status ] && echo '{"version": 7}'; exit 0`,
+		"kubectl": `echo 'deployment "web" successfully rolled out'`,
+	})
+	current, err := New(&config.Config{}, false).ForceRollback("web", "prod", 5)
+	if err != nil {
+		t.Fatalf("ForceRollback() error = %v", err)
+	}
+	if current != 7 {
+		t.Errorf("ForceRollback() = %d, want the current revision 7", current)
+	}
+	want := []string{
+		"helm rollback web 5 --namespace prod",
+		"kubectl rollout status deployment/web -n prod",
+		"helm status web --namespace prod -o json",
+	}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestForceRollbackUnhealthy(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"helm": "exit 0", "kubectl": "echo 'error: timed out' >&2; exit 1"})
+	_, err := New(&config.Config{}, false).ForceRollback("web", "prod", 0)
+	if err == nil || !strings.Contains(err.Error(), "is unhealthy") {
+		t.Errorf("ForceRollback() error = %v, want an unhealthy release", err)
+	}
+}
+
+func TestRecoverPendingRelease(t *testing.T) {
+	pending := errors.New("release web: another helm operation is in progress")
+
+	log := testutil.FakeTools(t, map[string]string{"helm": "exit 0"})
+	err := New(&config.Config{Namespace: "prod"}, false).recoverPendingRelease("web", pending)
+	if !errors.Is(err, pending) || !strings.Contains(err.Error(), "AUTO_RECOVER_PENDING=true") {
+		t.Errorf("recoverPendingRelease() error = %v, want the pending error with a recovery hint", err)
+	}
+	if got := testutil.Calls(t, log); len(got) != 0 {
+		t.Errorf("recovery ran without AUTO_RECOVER_PENDING: %q", got)
+	}
+
+	log = testutil.FakeTools(t, map[string]string{"helm": `[ "$1" = history ] && echo '[{"revision": 3, "status": "deployed"}, {"revision": 4, "status": "pending-upgrade"}]'; exit 0`})
+	if err := New(&config.Config{Namespace: "prod", AutoRecoverPending: true}, false).recoverPendingRelease("web", pending); err != nil {
+		t.Fatalf("recoverPendingRelease() error = %v", err)
+	}
+	want := []string{"helm history web --namespace prod -o json", "helm rollback web 3 --namespace prod"}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestResolveChartPath(t *testing.T) {
+	d := &Deployer{config: &config.Config{
+		HelmChartPath: "./charts/{{ image_name }}\t",
+		ImageChartMap: map[string]string{"worker": " oci://harbor.example.com/charts/jobs "},
+	}}
+	if got := d.resolveChartPath("api"); got != "./charts/api" {
+		t.Errorf("resolveChartPath(api) = %q, want the rendered HELM_CHART_PATH", got)
+	}
+	if got := d.resolveChartPath("worker"); got != "oci://harbor.example.com/charts/jobs" {
+		t.Errorf("resolveChartPath(worker) = %q, want the IMAGE_CHART_MAP entry", got)
+	}
+}
+
+func TestResolvePushedDigest(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"docker": `echo '[{"RepoDigests": ["nexus.example.com/web@sha256:1111", "harbor.example.com/web@sha256:2222"]}]'`})
+	d := New(&config.Config{ContainerRuntime: "docker"}, false)
+	digest, err := d.resolvePushedDigest("harbor.example.com/web:v1")
+	if err != nil || digest != "sha256:2222" {
+		t.Errorf("resolvePushedDigest() = %q, %v; want the Harbor digest", digest, err)
+	}
+}
+
+func TestSigningRef(t *testing.T) {
+	if got := signingRef("harbor.example.com/web:v1", "sha256:2222"); got != "harbor.example.com/web@sha256:2222" {
+		t.Errorf("signingRef() = %q, want the digest-pinned reference", got)
+	}
+	if got := signingRef("harbor.example.com/web:v1", ""); got != "harbor.example.com/web:v1" {
+		t.Errorf("signingRef() = %q, want the tag without a digest", got)
+	}
+}
+
+func TestVerifyTargetImage(t *testing.T) {
+	credentials := &config.Credentials{HarborUsername: "ci", HarborPassword: "secret"}
+	cfg := &config.Config{ContainerRuntime: "docker", HarborRegistry: "harbor.example.com"}
+
+	log := testutil.FakeTools(t, map[string]string{"docker": "exit 1"})
+	if err := New(cfg, false).verifyTargetImage("harbor.example.com/web:v1", credentials); err != nil {
+		t.Fatalf("verifyTargetImage() without VERIFY_TARGET_IMAGE error = %v", err)
+	}
+	if got := testutil.Calls(t, log); got != nil {
+		t.Errorf("calls = %q, want no registry access", got)
+	}
+
+	cfg.VerifyTargetImage = true
+	testutil.FakeTools(t, map[string]string{"docker": `[ "$1" = login ]`})
+	err := New(cfg, false).verifyTargetImage("harbor.example.com/web:v1", credentials)
+	if err == nil || !strings.Contains(err.Error(), "target image is missing") {
+		t.Errorf("verifyTargetImage() error = %v, want a missing image", err)
+	}
+}
+
+func TestTargetTag(t *testing.T) {
+	t.Setenv("GITHUB_SHA", "abc1234def5678")
+	tests := []struct {
+		suffix string
+		want   string
+	}{
+		{"", "v1.2.0"},
+		{"-hotfix", "v1.2.0-hotfix"},
+		{"-{{ git_sha }}", "v1.2.0-abc1234"},
+	}
+	for _, tt := range tests {
+		d := &Deployer{config: &config.Config{TagSuffix: tt.suffix}}
+		if got, err := d.targetTag("v1.2.0"); err != nil || got != tt.want {
+			t.Errorf("targetTag() with TAG_SUFFIX %q = %q, %v; want %q", tt.suffix, got, err, tt.want)
+		}
+	}
+}
+
+func TestCredentialEnv(t *testing.T) {
+	t.Setenv("NEXUS_USERNAME", "shared")
+	t.Setenv("NEXUS_USERNAME_PROD_EU", "prod-eu")
+	t.Setenv("NEXUS_USERNAME_STAGING", "")
+
+	tests := []struct {
+		environment string
+		want        string
+	}{
+		{"", "shared"},
+		{"prod-eu", "prod-eu"},
+		{"staging", "shared"},
+	}
+	for _, tt := range tests {
+		if got := credentialEnv("NEXUS_USERNAME", tt.environment); got != tt.want {
+			t.Errorf("credentialEnv() for environment %q = %q, want %q", tt.environment, got, tt.want)
+		}
+	}
+}
+
+func TestRollbackAll(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"helm": `case "$1" in
+list) echo '[{"name": "web", "revision": "4"}, {"name": "worker", "revision": "1"}]' ;;
+status) echo '{"version": 5}' ;;
+esac`})
+	results, err := New(&config.Config{}, false).RollbackAll("prod")
+	if err != nil {
+		t.Fatalf("RollbackAll() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("RollbackAll() = %+v, want a result per release", results)
+	}
+	if results[0].Release != "web" || results[0].Revision != 5 || results[0].Err != nil {
+		t.Errorf("web result = %+v, want a rollback to revision 5", results[0])
+	}
+	if results[1].Release != "worker" || results[1].Err == nil {
+		t.Errorf("worker result = %+v, want no previous revision", results[1])
+	}
+	if got := testutil.Calls(t, log); !slices.Contains(got, "helm rollback web --namespace prod") || slices.Contains(got, "helm rollback worker --namespace prod") {
+		t.Errorf("calls = %q, want only web rolled back", got)
+	}
+}
+
+func TestNewSummaryForDigest(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	d := &Deployer{config: &config.Config{NexusRegistry: "nexus.example.com", HarborRegistry: "harbor.example.com", HelmChartPath: "./chart"}}
+	s, err := d.newSummary(digest, "web")
+	if err != nil {
+		t.Fatalf("newSummary() error = %v", err)
+	}
+	if s.SourceImage != "nexus.example.com/web@"+digest || s.TargetImage != "harbor.example.com/web:sha256-abababababab" || s.ImageDigest != digest {
+		t.Errorf("newSummary() = source %q, target %q, digest %q", s.SourceImage, s.TargetImage, s.ImageDigest)
+	}
+}
+
+func TestAlreadyDeployed(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"helm": `echo '{"image": {"tag": "v1.2.0"}}'`})
+	d := New(&config.Config{Namespace: "prod", ImageTagKey: "image.tag"}, false)
+	for tag, want := range map[string]bool{"v1.2.0": true, "v1.3.0": false} {
+		deployed, err := d.alreadyDeployed(&Summary{ReleaseName: "web", TargetTag: tag})
+		if err != nil || deployed != want {
+			t.Errorf("alreadyDeployed() for %s = %v, %v; want %v", tag, deployed, err, want)
+		}
+	}
+
+	// DEPLOY_BY_DIGEST=tag leaves <tag>@<digest> in the release values
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	testutil.FakeTools(t, map[string]string{"helm": `echo '{"image": {"tag": "v1.2.0@` + digest + `"}}'`})
+	for _, tc := range []struct {
+		tag, digest string
+		want        bool
+	}{
+		{"v1.2.0", "", true},
+		{"v1.2.0", digest, true},
+		{"v1.2.0", "sha256:" + strings.Repeat("cd", 32), false},
+		{"v1.3.0", "", false},
+	} {
+		deployed, err := d.alreadyDeployed(&Summary{ReleaseName: "web", TargetTag: tc.tag, ImageDigest: tc.digest})
+		if err != nil || deployed != tc.want {
+			t.Errorf("alreadyDeployed() for %s@%s = %v, %v; want %v", tc.tag, tc.digest, deployed, err, tc.want)
+		}
+	}
+}
+
+func TestWithLoggerLeavesOriginal(t *testing.T) {
+	d := New(&config.Config{}, false)
+	prefixed := logging.WithPrefix("[web]")
+	clone := d.withLogger(prefixed)
+	if clone.log != prefixed || d.log == prefixed {
+		t.Errorf("withLogger() must set the logger on the copy only")
+	}
+	if clone.config != d.config {
+		t.Errorf("withLogger() copy does not share the configuration")
+	}
+}
+
+func TestRemoveAllContinuesPastFailures(t *testing.T) {
+	images := []string{"harbor.example.com/web:v1", "nexus.example.com/web:v1", "harbor2.example.com/web:v1"}
+	var attempted []string
+	removed, err := removeAll(images, func(image string) error {
+		attempted = append(attempted, image)
+		if strings.HasPrefix(image, "nexus") {
+			return errors.New("image is in use")
+		}
+		return nil
+	})
+	if !slices.Equal(attempted, images) {
+		t.Errorf("attempted %q, want every image", attempted)
+	}
+	if !slices.Equal(removed, []string{images[0], images[2]}) {
+		t.Errorf("removed = %q", removed)
+	}
+	if err == nil || err.Error() != "nexus.example.com/web:v1: image is in use" {
+		t.Errorf("error = %v, want the failed image named", err)
+	}
+	if _, err := removeAll(images, func(string) error { return nil }); err != nil {
+		t.Errorf("removeAll() error = %v when every removal succeeds", err)
+	}
+}
+
+func TestWithRenderedNamespace(t *testing.T) {
+	d := &Deployer{config: &config.Config{Namespace: "{{ env }}-{{ image_name }}", Environment: "staging"}}
+	rendered, err := d.withRenderedNamespace("web")
+	if err != nil {
+		t.Fatalf("withRenderedNamespace() error = %v", err)
+	}
+	if rendered.config.Namespace != "staging-web" || d.config.Namespace != "{{ env }}-{{ image_name }}" {
+		t.Errorf("namespace = %q, template = %q; want the copy rendered and the template kept", rendered.config.Namespace, d.config.Namespace)
+	}
+
+	plain := &Deployer{config: &config.Config{Namespace: "prod"}}
+	if got, err := plain.withRenderedNamespace("web"); err != nil || got != plain {
+		t.Errorf("withRenderedNamespace() without placeholders = %v, %v; want the deployer unchanged", got, err)
+	}
+
+	bad := &Deployer{config: &config.Config{Namespace: "{{ env }}_{{ image_name }}", Environment: "staging"}}
+	if _, err := bad.withRenderedNamespace("web"); err == nil || !strings.Contains(err.Error(), "not a valid DNS label") {
+		t.Errorf("withRenderedNamespace() error = %v, want an invalid label", err)
+	}
+}
+
+func TestHelmDeployOptionsSetImageRepository(t *testing.T) {
+	summary := &Summary{ReleaseName: "web", TargetTag: "v1", TargetImage: "harbor.example.com:8443/team/web:v1"}
+	d := &Deployer{config: &config.Config{ImageTagKey: "app.image.tag"}}
+	if opts := d.helmDeployOptions("./chart", summary); opts.ImageRepository != "" {
+		t.Errorf("ImageRepository = %q without SET_IMAGE_REPOSITORY", opts.ImageRepository)
+	}
+
+	d.config.SetImageRepository = true
+	opts := d.helmDeployOptions("./chart", summary)
+	if opts.ImageRepository != "harbor.example.com:8443/team/web" || opts.ImageRepositoryKey != "app.image.repository" {
+		t.Errorf("repository = %q at %q, want the Harbor repository beside the tag key", opts.ImageRepository, opts.ImageRepositoryKey)
+	}
+}
+
+func TestHelmDeployOptionsDeployByDigest(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	summary := &Summary{ReleaseName: "web", TargetTag: "v1", ImageDigest: digest}
+
+	d := &Deployer{config: &config.Config{DeployByDigest: "digest"}}
+	if opts := d.helmDeployOptions("./chart", summary); opts.ImageTag != "v1" || opts.ImageDigest != digest {
+		t.Errorf("digest mode = tag %q, digest %q", opts.ImageTag, opts.ImageDigest)
+	}
+	d.config.DeployByDigest = "tag"
+	if opts := d.helmDeployOptions("./chart", summary); opts.ImageTag != "v1@"+digest || opts.ImageDigest != "" {
+		t.Errorf("tag mode = tag %q, digest %q; want the digest folded into the tag", opts.ImageTag, opts.ImageDigest)
+	}
+}
+
+func TestVerifyTagLabel(t *testing.T) {
+	const label = "org.opencontainers.image.version"
+	info := &docker.ImageInfo{}
+	info.Config.Labels = map[string]string{label: "v1"}
+	if err := verifyTagLabel(info, label, "v1"); err != nil {
+		t.Errorf("verifyTagLabel(v1) = %v", err)
+	}
+	if err := verifyTagLabel(info, label, "v2"); err == nil || !strings.Contains(err.Error(), "does not match tag v2") {
+		t.Errorf("verifyTagLabel(v2) = %v, want a mismatch", err)
+	}
+	if err := verifyTagLabel(&docker.ImageInfo{}, label, "v1"); err == nil || !strings.Contains(err.Error(), "has no "+label) {
+		t.Errorf("verifyTagLabel(unlabelled) = %v, want a missing label error", err)
+	}
+}
+
+func TestReleaseWithoutWait(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"helm": "", "kubectl": "exit 1"})
+	chart := t.TempDir()
+	d := New(&config.Config{Namespace: "prod", Wait: false, RunHelmTest: true}, false)
+	if _, err := d.release(chart, &Summary{ReleaseName: "web", TargetTag: "v1"}, &config.Credentials{}); err != nil {
+		t.Fatalf("release() error = %v", err)
+	}
+	got := testutil.Calls(t, log)
+	if len(got) != 1 || !strings.HasPrefix(got[0], "helm upgrade --install web "+chart) || strings.Contains(got[0], "--wait") {
+		t.Errorf("calls = %q, want only an upgrade without --wait", got)
+	}
+}
+
+func TestLintChart(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"helm": "exit 1"})
+	d := New(&config.Config{Namespace: "prod", LintChart: true}, false)
+	if err := d.lintChart("oci://harbor.example.com/charts/web", &Summary{TargetTag: "v1"}); err != nil {
+		t.Errorf("lintChart(remote) = %v, want it skipped", err)
+	}
+	if got := testutil.Calls(t, log); len(got) != 0 {
+		t.Errorf("calls = %q, want no lint of a remote chart", got)
+	}
+	if err := d.lintChart("./chart", &Summary{TargetTag: "v1"}); err == nil || !strings.Contains(err.Error(), "chart lint failed") {
+		t.Errorf("lintChart(local) = %v, want the lint failure", err)
+	}
+}
+
+func TestRollbackForcedRecovery(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "hung-once")
+	log := testutil.FakeTools(t, map[string]string{
+		"helm": `case "$1" in
+status) echo '{"version": 5}';;
+rollback) [ -f ` + marker + ` ] || { touch ` + marker + `; exec sleep 10; };;
+esac`,
+		"kubectl": `if [ "$1" = get ]; then echo '{"items": [{"metadata": {"name": "web-2", "deletionTimestamp": "2026-01-01T00:00:00Z"}, "status": {"phase": "Running"}}]}'; fi`,
+	})
+	d := New(&config.Config{Namespace: "prod", ForceRollbackRecovery: true, RollbackGracePeriod: 1}, false)
+	if err := d.rollback("web"); err != nil {
+		t.Fatalf("rollback() error = %v", err)
+	}
+	got := testutil.Calls(t, log)
+	want := []string{
+		"helm status web --namespace prod -o json",
+		"helm rollback web 4 --namespace prod",
+		"kubectl get pods -n prod -l app.kubernetes.io/instance=web -o json",
+		"kubectl delete pod -n prod --grace-period=0 --force web-2",
+		"helm rollback web 4 --namespace prod",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("calls = %q, want %q", got, want)
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("call %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"sbi-deployment/internal/utils"
+)
+
+// DeployLogRecord is one line appended to DEPLOY_LOG_FILE
+type DeployLogRecord struct {
+	Timestamp string `json:"timestamp"`
+	User      string `json:"user"`
+	Release   string `json:"release"`
+	Namespace string `json:"namespace"`
+	Tag       string `json:"tag"`
+	Result    string `json:"result"`
+	Error     string `json:"error,omitempty"`
+}
+
+// format renders the record as a Markdown table row for .md files and as a JSON line otherwise
+func (r DeployLogRecord) format(path string) (string, error) {
+	if strings.HasSuffix(path, ".md") {
+		cell := func(s string) string { return strings.ReplaceAll(s, "|", `\|`) }
+		return fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n",
+			cell(r.Timestamp), cell(r.User), cell(r.Release), cell(r.Namespace), cell(r.Tag), cell(r.Result)), nil
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// appendDeployLog appends a record to path under an exclusive lock so concurrent deploys don't interleave
+func appendDeployLog(path string, record DeployLogRecord) error {
+	line, err := record.format(path)
+	if err != nil {
+		return fmt.Errorf("failed to encode deploy log record: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open deploy log: %w", err)
+	}
+	defer file.Close()
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("failed to lock deploy log: %w", err)
+	}
+	defer syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+
+	// A new Markdown log starts with a table header
+	if info, err := file.Stat(); err == nil && info.Size() == 0 && strings.HasSuffix(path, ".md") {
+		line = "| Timestamp | User | Release | Namespace | Tag | Result |\n|---|---|---|---|---|---|\n" + line
+	}
+	if _, err := file.WriteString(line); err != nil {
+		return fmt.Errorf("failed to write deploy log: %w", err)
+	}
+	return nil
+}
+
+// recordDeploy appends the outcome of a deployment to DEPLOY_LOG_FILE; failures are only logged
+func (d *Deployer) recordDeploy(imageTag string, summary *Summary, deployErr error) {
+	if d.config.DeployLogFile == "" {
+		return
+	}
+
+	record := DeployLogRecord{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		User:      utils.GetCurrentUser(),
+		Release:   d.config.ReleaseName,
+		Namespace: d.config.Namespace,
+		Tag:       imageTag,
+		Result:    "success",
+	}
+	if summary != nil {
+		record.Release = summary.ReleaseName
+		record.Tag = summary.TargetTag
+		if summary.Unchanged {
+			record.Result = "unchanged"
+		}
+	}
+	if deployErr != nil {
+		record.Result = "failure"
+		record.Error = deployErr.Error()
+	}
+
+	if err := appendDeployLog(d.config.DeployLogFile, record); err != nil {
+		d.log.Warnf("%v", err)
+	}
+}
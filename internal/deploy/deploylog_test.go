@@ -0,0 +1,61 @@
+package deploy
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAppendDeployLogMarkdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "DEPLOYS.md")
+	for _, tag := range []string{"v1", "v2|rc"} {
+		record := DeployLogRecord{Timestamp: "2026-01-01T12:00:00Z", User: "ci", Release: "web", Namespace: "prod", Tag: tag, Result: "success"}
+		if err := appendDeployLog(path, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "| Timestamp | User | Release | Namespace | Tag | Result |\n|---|---|---|---|---|---|\n" +
+		"| 2026-01-01T12:00:00Z | ci | web | prod | v1 | success |\n" +
+		"| 2026-01-01T12:00:00Z | ci | web | prod | v2\\|rc | success |\n"
+	if string(data) != want {
+		t.Errorf("log = %q, want %q", data, want)
+	}
+}
+
+func TestAppendDeployLogConcurrentJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deploys.jsonl")
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			record := DeployLogRecord{Release: "web", Tag: strings.Repeat("v", 512), Result: "failure", Error: "rollout timed out"}
+			if err := appendDeployLog(path, record); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 20 {
+		t.Fatalf("log has %d lines, want 20", len(lines))
+	}
+	for _, line := range lines {
+		var record DeployLogRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil || record.Error != "rollout timed out" {
+			t.Errorf("line %q = %+v, %v", line, record, err)
+		}
+	}
+}
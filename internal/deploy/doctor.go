@@ -0,0 +1,171 @@
+package deploy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"sbi-deployment/internal/utils"
+)
+
+// doctorCheck is a single environment check with a remediation hint
+type doctorCheck struct {
+	name     string
+	critical bool
+	hint     string
+	run      func() (string, error)
+}
+
+// doctorResult records the outcome of a doctor check
+type doctorResult struct {
+	check  doctorCheck
+	detail string
+	err    error
+}
+
+// Doctor checks the local environment, prints a checklist to w, and reports whether all critical checks passed
+func (d *Deployer) Doctor(w io.Writer) bool {
+	var results []doctorResult
+	for _, check := range d.doctorChecks() {
+		detail, err := check.run()
+		results = append(results, doctorResult{check: check, detail: detail, err: err})
+	}
+	return reportDoctor(w, results)
+}
+
+// doctorChecks lists every check run by the doctor subcommand, in display order
+func (d *Deployer) doctorChecks() []doctorCheck {
+	runtime := d.dockerClient.Runtime()
+	kubeCLI := d.helmClient.KubeCLI()
+	chartPath := d.resolveChartPath(d.resolveImageName(""))
+
+	return []doctorCheck{
+		{
+			name:     "configuration",
+			critical: true,
+			hint:     fmt.Sprintf("fix the reported value in %s", d.config.ConfigFile),
+			run: func() (string, error) {
+				return d.config.ConfigFile, d.config.Validate()
+			},
+		},
+		{
+			name:     runtime + " installed",
+			critical: true,
+			hint:     "run ./sbi-deploy --setup or install " + runtime,
+			run:      toolVersion(runtime, "version", "--format", "{{.Client.Version}}"),
+		},
+		{
+			name:     runtime + " daemon reachable",
+			critical: true,
+			hint:     "start the daemon (sudo systemctl start docker) and check your user is in the docker group",
+			run: func() (string, error) {
+				return "", d.dockerClient.CheckDocker()
+			},
+		},
+		{
+			name:     "helm installed",
+			critical: true,
+			hint:     "run ./sbi-deploy --setup or install Helm 3.x",
+			run:      toolVersion("helm", "version", "--short"),
+		},
+		{
+			name:     kubeCLI + " installed",
+			critical: true,
+			hint:     "run ./sbi-deploy --setup or install " + kubeCLI,
+			run:      toolVersion(kubeCLI, "version", "--client"),
+		},
+		{
+			name:     "kubeconfig valid",
+			critical: true,
+			hint:     fmt.Sprintf("check KUBECONFIG and the active context with '%s config current-context'", kubeCLI),
+			run:      toolVersion(kubeCLI, "cluster-info"),
+		},
+		// A BUILD_CONTEXT deploy builds the image itself and never pulls from Nexus
+		{
+			name:     "Nexus registry reachable",
+			critical: d.config.BuildContext == "",
+			hint:     "check NEXUS_REGISTRY, network access, and proxy settings",
+			run:      registryReachable(d.config.NexusRegistry),
+		},
+		{
+			name:     "Harbor registry reachable",
+			critical: true,
+			hint:     "check HARBOR_REGISTRY, network access, and proxy settings",
+			run:      registryReachable(d.config.HarborRegistry),
+		},
+		{
+			name:     "chart path",
+			critical: false,
+			hint:     "check HELM_CHART_PATH; templated paths are resolved with the release name",
+			run: func() (string, error) {
+				return chartPath, d.helmClient.CheckChartPath(chartPath)
+			},
+		},
+	}
+}
+
+// toolVersion returns a check that runs a command and reports the first line of its output
+func toolVersion(command string, args ...string) func() (string, error) {
+	return func() (string, error) {
+		if err := utils.CheckCommand(command); err != nil {
+			return "", err
+		}
+		output, err := utils.RunCommand(command, args...)
+		firstLine := strings.TrimSpace(strings.SplitN(strings.TrimSpace(output), "\n", 2)[0])
+		if err != nil {
+			return firstLine, fmt.Errorf("%s %s failed: %w", command, strings.Join(args, " "), err)
+		}
+		return firstLine, nil
+	}
+}
+
+// registryReachable returns a check that the registry answers on its /v2/ API endpoint, through
+// the configured proxy like the deploy's own registry calls
+func registryReachable(registry string) func() (string, error) {
+	return func() (string, error) {
+		if registry == "" {
+			return "", fmt.Errorf("registry is not configured")
+		}
+		host := strings.SplitN(registry, "/", 2)[0]
+		client := &http.Client{Timeout: 5 * time.Second, Transport: utils.ProxyTransport()}
+		resp, err := client.Get(fmt.Sprintf("https://%s/v2/", host))
+		if err != nil {
+			return host, err
+		}
+		resp.Body.Close()
+		// 401 is expected without credentials and still proves reachability
+		return fmt.Sprintf("%s (HTTP %d)", host, resp.StatusCode), nil
+	}
+}
+
+// reportDoctor prints a ✓/✗ checklist and returns true if no critical check failed
+func reportDoctor(w io.Writer, results []doctorResult) bool {
+	healthy := true
+	for _, r := range results {
+		if r.err == nil {
+			if r.detail != "" {
+				fmt.Fprintf(w, "✓ %s: %s\n", r.check.name, r.detail)
+			} else {
+				fmt.Fprintf(w, "✓ %s\n", r.check.name)
+			}
+			continue
+		}
+
+		level := "warning"
+		if r.check.critical {
+			level = "critical"
+			healthy = false
+		}
+		fmt.Fprintf(w, "✗ %s (%s): %v\n", r.check.name, level, r.err)
+		fmt.Fprintf(w, "    hint: %s\n", r.check.hint)
+	}
+
+	if healthy {
+		fmt.Fprintln(w, "All critical checks passed")
+	} else {
+		fmt.Fprintln(w, "Some critical checks failed")
+	}
+	return healthy
+}
@@ -0,0 +1,87 @@
+package deploy
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/testutil"
+	"sbi-deployment/internal/utils"
+)
+
+func TestReportDoctor(t *testing.T) {
+	results := []doctorResult{
+		{check: doctorCheck{name: "helm"}, detail: "v3.12.0"},
+		{check: doctorCheck{name: "cosign", hint: "install cosign"}, err: errors.New("not found")},
+	}
+	var out bytes.Buffer
+	if !reportDoctor(&out, results) {
+		t.Errorf("reportDoctor() = false with only a non-critical failure")
+	}
+	for _, want := range []string{"✓ helm: v3.12.0", "✗ cosign (warning): not found", "hint: install cosign", "All critical checks passed"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report %q lacks %q", out.String(), want)
+		}
+	}
+
+	results = append(results, doctorResult{check: doctorCheck{name: "docker", critical: true, hint: "start docker"}, err: errors.New("daemon not running")})
+	out.Reset()
+	if reportDoctor(&out, results) {
+		t.Errorf("reportDoctor() = true with a critical failure")
+	}
+	if !strings.Contains(out.String(), "✗ docker (critical): daemon not running") {
+		t.Errorf("report %q lacks the critical failure", out.String())
+	}
+}
+
+func TestToolVersion(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"helm": `echo 'version.BuildInfo{Version:"v3.12.0"}'; echo second line`})
+	detail, err := toolVersion("helm", "version")()
+	if err != nil || detail != `version.BuildInfo{Version:"v3.12.0"}` {
+		t.Errorf("toolVersion() = %q, %v; want the first output line", detail, err)
+	}
+	if _, err := toolVersion("no-such-tool", "version")(); err == nil {
+		t.Errorf("toolVersion() passed for a missing tool")
+	}
+}
+
+func TestRegistryReachableNeedsRegistry(t *testing.T) {
+	if _, err := registryReachable("")(); err == nil {
+		t.Errorf("registryReachable(\"\") passed without a registry")
+	}
+}
+
+func TestRegistryReachableUsesProxy(t *testing.T) {
+	var tunnel string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodConnect {
+			tunnel = r.Host
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer proxy.Close()
+	t.Cleanup(func() { utils.SetProxyEnv("", "", "") })
+	utils.SetProxyEnv("", proxy.URL, "")
+
+	if _, err := registryReachable("nexus.example.com/team")(); err == nil {
+		t.Errorf("registryReachable() passed through a failing proxy")
+	}
+	if tunnel != "nexus.example.com:443" {
+		t.Errorf("proxy tunnel = %q, want the registry reached through HTTPS_PROXY", tunnel)
+	}
+}
+
+func TestDoctorNexusCheckWithBuildContext(t *testing.T) {
+	for buildContext, critical := range map[string]bool{"": true, "./app": false} {
+		d := New(&config.Config{NexusRegistry: "nexus.example.com", BuildContext: buildContext}, false)
+		for _, check := range d.doctorChecks() {
+			if check.name == "Nexus registry reachable" && check.critical != critical {
+				t.Errorf("BUILD_CONTEXT=%q: Nexus check critical = %v, want %v", buildContext, check.critical, critical)
+			}
+		}
+	}
+}
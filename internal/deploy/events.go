@@ -0,0 +1,67 @@
+package deploy
+
+import "time"
+
+// EventType names a deploy lifecycle event
+type EventType string
+
+const (
+	EventDeployStart EventType = "deploy_start"
+	EventDeployEnd   EventType = "deploy_end"
+	EventPhaseStart  EventType = "phase_start"
+	EventPhaseEnd    EventType = "phase_end"
+	EventRetry       EventType = "retry"
+	EventRollback    EventType = "rollback"
+)
+
+// Deploy phases reported in phase events; dry runs report the same phases
+const (
+	PhasePreflight = "preflight"
+	PhaseSync      = "sync"
+	PhaseRelease   = "release"
+	PhaseCleanup   = "cleanup"
+)
+
+// Event is a deploy lifecycle notification for embedders; Err is set on failed
+// phase and deploy ends, on the failure that caused a retry, and on rollbacks
+type Event struct {
+	Type    EventType
+	Phase   string // phase events
+	Step    string // retry events, e.g. "Pull"
+	Attempt int    // retry events: the attempt that failed
+	Release string // rollback and deploy end events
+	DryRun  bool
+	Err     error
+	Time    time.Time
+}
+
+// WithEventHandler returns a copy of the deployer that calls onEvent at each lifecycle event.
+// With KUBECONFIGS the release phase runs per cluster, so onEvent may be called concurrently.
+func (d *Deployer) WithEventHandler(onEvent func(Event)) *Deployer {
+	clone := *d
+	clone.onEvent = onEvent
+	return &clone
+}
+
+// emit delivers e to the event handler, if any
+func (d *Deployer) emit(e Event) {
+	if d.onEvent == nil {
+		return
+	}
+	e.DryRun = d.dryRun
+	e.Time = time.Now()
+	d.onEvent(e)
+}
+
+// startPhase emits the start of phase and returns the function that emits its end
+func (d *Deployer) startPhase(phase string) func(error) {
+	d.emit(Event{Type: EventPhaseStart, Phase: phase})
+	return func(err error) {
+		d.emit(Event{Type: EventPhaseEnd, Phase: phase, Err: err})
+	}
+}
+
+// emitRetry reports a failed attempt that the retry budget is about to repeat
+func (d *Deployer) emitRetry(step string, attempt int, err error) {
+	d.emit(Event{Type: EventRetry, Step: step, Attempt: attempt, Err: err})
+}
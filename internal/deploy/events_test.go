@@ -0,0 +1,61 @@
+package deploy
+
+import (
+	"errors"
+	"testing"
+
+	"sbi-deployment/internal/config"
+)
+
+func TestStartPhaseEmitsStartAndEnd(t *testing.T) {
+	var events []Event
+	d := (&Deployer{config: &config.Config{}, dryRun: true}).WithEventHandler(func(e Event) {
+		events = append(events, e)
+	})
+
+	failed := errors.New("helm upgrade failed")
+	end := d.startPhase(PhaseRelease)
+	end(failed)
+
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want a start and an end", events)
+	}
+	if start := events[0]; start.Type != EventPhaseStart || start.Phase != PhaseRelease || start.Err != nil {
+		t.Errorf("start = %+v", start)
+	}
+	if end := events[1]; end.Type != EventPhaseEnd || end.Phase != PhaseRelease || !errors.Is(end.Err, failed) {
+		t.Errorf("end = %+v, want the phase error", end)
+	}
+	for _, e := range events {
+		if !e.DryRun || e.Time.IsZero() {
+			t.Errorf("event %+v lacks DryRun or Time", e)
+		}
+	}
+}
+
+func TestEmitRetry(t *testing.T) {
+	var events []Event
+	d := (&Deployer{config: &config.Config{}}).WithEventHandler(func(e Event) {
+		events = append(events, e)
+	})
+	budget := newRetryBudget(1, 0, nil)
+	budget.backoff = 0
+	budget.onRetry = d.emitRetry
+
+	var calls int
+	if err := budget.do("Pull", failing(1, &calls)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(events) != 1 || events[0].Type != EventRetry || events[0].Step != "Pull" || events[0].Attempt != 1 || events[0].Err == nil {
+		t.Errorf("events = %+v, want one retry of Pull attempt 1", events)
+	}
+}
+
+func TestEmitWithoutHandler(t *testing.T) {
+	d := &Deployer{config: &config.Config{}}
+	d.startPhase(PhaseSync)(nil)
+	d.emitRetry("Push", 1, errors.New("flaky"))
+	if handled := d.WithEventHandler(func(Event) {}); d.onEvent != nil || handled.onEvent == nil {
+		t.Errorf("WithEventHandler() changed the original deployer")
+	}
+}
@@ -0,0 +1,77 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+
+	"sbi-deployment/internal/docker"
+)
+
+// explanation is one derived value and the reason it has that value
+type explanation struct {
+	name   string
+	value  string
+	source string
+}
+
+// explanations describes how each computed deployment value was derived
+func (d *Deployer) explanations(imageTag, imageName string) ([]explanation, error) {
+	name, nameSource := d.imageNameSource(imageName)
+
+	targetTag, err := d.targetTag(imageTag)
+	if err != nil {
+		return nil, err
+	}
+	tagSource := "from --tag"
+	switch {
+	case docker.IsDigest(imageTag) && d.config.TagSuffix != "":
+		tagSource = "tag derived from --tag digest plus TAG_SUFFIX " + d.config.TagSuffix
+	case docker.IsDigest(imageTag):
+		tagSource = "tag derived from --tag digest"
+	case d.config.TagSuffix != "":
+		tagSource = "from --tag plus TAG_SUFFIX " + d.config.TagSuffix
+	}
+
+	chartSource := "from HELM_CHART_PATH"
+	if _, ok := d.config.ImageChartMap[name]; ok {
+		chartSource = "from IMAGE_CHART_MAP entry for " + name
+	} else if strings.Contains(d.config.HelmChartPath, "{{ image_name }}") {
+		chartSource = "from HELM_CHART_PATH template with image_name=" + name
+	}
+
+	namespaceSource := d.namespaceSource
+	if namespaceSource == "" {
+		namespaceSource = "from NAMESPACE"
+	}
+
+	releaseSource := "from RELEASE_NAME"
+	if strings.Contains(d.config.ReleaseName, "{{ image_name }}") {
+		releaseSource = "from RELEASE_NAME template with image_name=" + name
+	}
+
+	return []explanation{
+		{"imageName", name, nameSource},
+		{"sourceImage", docker.ImageRef(d.config.NexusRegistry, name, imageTag), "NEXUS_REGISTRY/imageName:--tag"},
+		{"targetTag", targetTag, tagSource},
+		{"targetImage", docker.ImageRef(d.config.HarborRegistry, name, targetTag), "HARBOR_REGISTRY/imageName:targetTag"},
+		{"chartPath", d.resolveChartPath(name), chartSource},
+		{"releaseName", d.releaseName(name), releaseSource},
+		{"namespace", d.config.Namespace, namespaceSource},
+	}, nil
+}
+
+// explain logs the derivation of each computed value
+func (d *Deployer) explain(imageTag, imageName string) error {
+	lines, err := d.explanations(imageTag, imageName)
+	if err != nil {
+		return err
+	}
+	for _, e := range lines {
+		d.log.Infof("explain: %s", e)
+	}
+	return nil
+}
+
+func (e explanation) String() string {
+	return fmt.Sprintf("%s=%s (%s)", e.name, e.value, e.source)
+}
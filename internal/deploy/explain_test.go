@@ -0,0 +1,77 @@
+package deploy
+
+import (
+	"slices"
+	"testing"
+
+	"sbi-deployment/internal/config"
+)
+
+func TestExplanations(t *testing.T) {
+	d := &Deployer{config: &config.Config{
+		NexusRegistry:  "nexus.example.com",
+		HarborRegistry: "harbor.example.com",
+		HelmChartPath:  "./charts/{{ image_name }}",
+		ReleaseName:    "{{ image_name }}",
+		Namespace:      "prod",
+		TagSuffix:      "-rc",
+	}}
+	lines, err := d.explanations("v1", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range lines {
+		got = append(got, e.String())
+	}
+	want := []string{
+		"imageName=web (from --image)",
+		"sourceImage=nexus.example.com/web:v1 (NEXUS_REGISTRY/imageName:--tag)",
+		"targetTag=v1-rc (from --tag plus TAG_SUFFIX -rc)",
+		"targetImage=harbor.example.com/web:v1-rc (HARBOR_REGISTRY/imageName:targetTag)",
+		"chartPath=./charts/web (from HELM_CHART_PATH template with image_name=web)",
+		"releaseName=web (from RELEASE_NAME template with image_name=web)",
+		"namespace=prod (from NAMESPACE)",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("explanations() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestImageNameSource(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    config.Config
+		want   string
+		source string
+	}{
+		{"compose service", config.Config{ImageName: "web", ReleaseName: "api"}, "web", "from the COMPOSE_FILE service image because --image is empty"},
+		{"release name", config.Config{ReleaseName: "api"}, "api", "from RELEASE_NAME because --image is empty"},
+		{"chart path", config.Config{HelmChartPath: "./charts/worker/"}, "worker", "derived from chart path because --image and RELEASE_NAME are empty"},
+		{"templated chart", config.Config{HelmChartPath: "./charts/{{ image_name }}"}, "app", "default because --image and RELEASE_NAME are empty and HELM_CHART_PATH is templated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Deployer{config: &tt.cfg}
+			if name, source := d.imageNameSource(""); name != tt.want || source != tt.source {
+				t.Errorf("imageNameSource() = %q, %q; want %q, %q", name, source, tt.want, tt.source)
+			}
+		})
+	}
+}
+
+func TestExplanationsUseRenderedNamespace(t *testing.T) {
+	d := &Deployer{config: &config.Config{Namespace: "team-{{ image_name }}", HelmChartPath: "./chart"}}
+	rendered, err := d.withRenderedNamespace("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, err := rendered.explanations("v1", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	namespace := lines[len(lines)-1].String()
+	if namespace != "namespace=team-web (from NAMESPACE template team-{{ image_name }} with image_name=web)" {
+		t.Errorf("namespace explanation = %q", namespace)
+	}
+}
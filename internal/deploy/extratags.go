@@ -0,0 +1,71 @@
+package deploy
+
+import (
+	"fmt"
+	"time"
+
+	"sbi-deployment/internal/docker"
+)
+
+// TimestampTag renders the deploy time as a tag with layout (a Go time layout such as
+// deployed-20060102-1504), always in UTC so runners in different zones agree
+func TimestampTag(layout string, t time.Time) string {
+	return t.UTC().Format(layout)
+}
+
+// extraTags returns the additional Harbor tags pushed alongside the deploy tag
+func (d *Deployer) extraTags(now time.Time) []string {
+	var tags []string
+	if d.config.TimestampTag {
+		tags = append(tags, TimestampTag(d.config.TimestampTagFormat, now))
+	}
+	return tags
+}
+
+// extraTagRefs returns the Harbor references of the extra tags for the target image
+func (d *Deployer) extraTagRefs(targetImage string, now time.Time) []string {
+	repository, _ := docker.SplitReference(targetImage)
+	var refs []string
+	for _, tag := range d.extraTags(now) {
+		refs = append(refs, repository+":"+tag)
+	}
+	return refs
+}
+
+// pushExtraTags pushes the synced image under each extra tag; manifest lists are copied
+// registry-side since there is no local image to tag
+func (d *Deployer) pushExtraTags(summary *Summary) error {
+	for _, ref := range d.extraTagRefs(summary.TargetImage, time.Now()) {
+		if d.config.PreserveManifestList {
+			if err := d.dockerClient.CopyManifestList(summary.TargetImage, ref); err != nil {
+				return fmt.Errorf("failed to add tag %s: %w", ref, err)
+			}
+		} else {
+			if err := d.dockerClient.Tag(summary.TargetImage, ref); err != nil {
+				return err
+			}
+			if err := d.dockerClient.Push(ref); err != nil {
+				return fmt.Errorf("failed to push tag %s: %w", ref, err)
+			}
+		}
+		summary.ExtraTags = append(summary.ExtraTags, ref)
+		d.log.Infof("Also tagged in Harbor: %s", ref)
+	}
+	return nil
+}
+
+// planExtraTags lists the commands pushExtraTags runs
+func (d *Deployer) planExtraTags(summary *Summary) [][]string {
+	runtime := d.dockerClient.Runtime()
+	var commands [][]string
+	for _, ref := range d.extraTagRefs(summary.TargetImage, time.Now()) {
+		if d.config.PreserveManifestList {
+			commands = append(commands, d.plannedCommand(runtime, docker.ManifestCopyArgs(summary.TargetImage, ref)))
+		} else {
+			commands = append(commands,
+				d.plannedCommand(runtime, docker.TagArgs(summary.TargetImage, ref)),
+				d.plannedCommand(runtime, docker.PushArgs(ref)))
+		}
+	}
+	return commands
+}
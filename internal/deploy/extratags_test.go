@@ -0,0 +1,48 @@
+package deploy
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/testutil"
+)
+
+func TestTimestampTag(t *testing.T) {
+	at := time.Date(2026, 3, 1, 1, 30, 0, 0, time.FixedZone("CET", 3600))
+	if got := TimestampTag("deployed-20060102-1504", at); got != "deployed-20260301-0030" {
+		t.Errorf("TimestampTag() = %q, want the time in UTC", got)
+	}
+}
+
+func TestExtraTagRefs(t *testing.T) {
+	at := time.Date(2026, 3, 1, 0, 30, 0, 0, time.UTC)
+	d := &Deployer{config: &config.Config{TimestampTagFormat: "deployed-20060102-1504"}}
+	if refs := d.extraTagRefs("harbor.example.com/team/web:v1", at); refs != nil {
+		t.Errorf("extraTagRefs() = %q without TIMESTAMP_TAG, want none", refs)
+	}
+	d.config.TimestampTag = true
+	want := []string{"harbor.example.com/team/web:deployed-20260301-0030"}
+	if refs := d.extraTagRefs("harbor.example.com/team/web:v1", at); !slices.Equal(refs, want) {
+		t.Errorf("extraTagRefs() = %q, want %q", refs, want)
+	}
+}
+
+func TestPushExtraTags(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"docker": ""})
+	d := New(&config.Config{ContainerRuntime: "docker", TimestampTag: true, TimestampTagFormat: "deployed-20060102"}, false)
+	summary := &Summary{TargetImage: "harbor.example.com/web:v1"}
+	if err := d.pushExtraTags(summary); err != nil {
+		t.Fatalf("pushExtraTags() error = %v", err)
+	}
+	if len(summary.ExtraTags) != 1 || !strings.HasPrefix(summary.ExtraTags[0], "harbor.example.com/web:deployed-") {
+		t.Fatalf("ExtraTags = %q, want one deployed- tag", summary.ExtraTags)
+	}
+	ref := summary.ExtraTags[0]
+	want := []string{"docker tag harbor.example.com/web:v1 " + ref, "docker push " + ref}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,25 @@
+package deploy
+
+import (
+	"strings"
+
+	"sbi-deployment/internal/helm"
+)
+
+// previewKubeDiff prints the server-side diff of the rendered chart against the cluster;
+// the preview never blocks a deploy, so failures are only warnings
+func (d *Deployer) previewKubeDiff(opts helm.DeployOptions) {
+	diff, changed, err := d.helmClient.KubeDiff(opts)
+	if err != nil {
+		d.log.Warnf("Could not preview the deploy with %s diff: %v", d.helmClient.KubeCLI(), err)
+		return
+	}
+	if !changed {
+		d.log.Infof("   ✓ No resource changes against the cluster")
+		return
+	}
+	d.log.Infof("   Resource changes against the cluster:")
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		d.log.Infof("   %s", line)
+	}
+}
@@ -0,0 +1,35 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/registry"
+)
+
+// LatestSemverTag is the --tag value that deploys the newest semver release tag in Nexus
+const LatestSemverTag = "latest-semver"
+
+// resolveLatestSemver lists the image's tags in NEXUS_REGISTRY and returns the highest semver release
+func (d *Deployer) resolveLatestSemver(imageName string, credentials *config.Credentials) (string, error) {
+	if d.config.NexusRegistry == "" {
+		return "", fmt.Errorf("--tag=%s requires NEXUS_REGISTRY", LatestSemverTag)
+	}
+	if credentials.NexusUsername == "" || credentials.NexusPassword == "" {
+		return "", fmt.Errorf("--tag=%s requires Nexus credentials to list tags", LatestSemverTag)
+	}
+	repository := strings.TrimSuffix(d.config.NexusRegistry, "/") + "/" + d.resolveImageName(imageName)
+	host, path, _ := strings.Cut(repository, "/")
+
+	tags, err := registry.New(host, credentials.NexusUsername, credentials.NexusPassword).Tags(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve --tag=%s: %w", LatestSemverTag, err)
+	}
+	tag, ok := registry.LatestSemver(tags)
+	if !ok {
+		return "", fmt.Errorf("no semver release tag among the %d tags of %s", len(tags), repository)
+	}
+	d.log.Infof("Resolved %s to %s (newest release of %d tags in %s)", LatestSemverTag, tag, len(tags), repository)
+	return tag, nil
+}
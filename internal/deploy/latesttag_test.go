@@ -0,0 +1,28 @@
+package deploy
+
+import (
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/config"
+)
+
+func TestResolveLatestSemverNeedsNexus(t *testing.T) {
+	d := &Deployer{config: &config.Config{}}
+	if _, err := d.resolveLatestSemver("web", &config.Credentials{NexusUsername: "ci", NexusPassword: "secret"}); err == nil || !strings.Contains(err.Error(), "requires NEXUS_REGISTRY") {
+		t.Errorf("resolveLatestSemver() error = %v, want NEXUS_REGISTRY required", err)
+	}
+	d.config.NexusRegistry = "nexus.example.com"
+	if _, err := d.resolveLatestSemver("web", &config.Credentials{}); err == nil || !strings.Contains(err.Error(), "requires Nexus credentials") {
+		t.Errorf("resolveLatestSemver() error = %v, want Nexus credentials required", err)
+	}
+}
+
+func TestRequiredCredentialsForLatestSemver(t *testing.T) {
+	for name, cfg := range map[string]config.Config{"skip sync": {SkipSync: true}, "local build": {BuildContext: "."}} {
+		d := &Deployer{config: &cfg}
+		if nexus, _ := d.requiredCredentials(LatestSemverTag); !nexus {
+			t.Errorf("%s: requiredCredentials(%s) skipped Nexus, which lists the tags", name, LatestSemverTag)
+		}
+	}
+}
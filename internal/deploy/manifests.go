@@ -0,0 +1,44 @@
+package deploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// manifestPaths returns the files the current and new manifests of a release are written to
+func manifestPaths(dir, releaseName string) (string, string) {
+	if dir == "" {
+		dir = "."
+	}
+	return filepath.Join(dir, releaseName+"-current.yaml"), filepath.Join(dir, releaseName+"-new.yaml")
+}
+
+// captureManifests writes the live release manifest and the manifest of the pending upgrade
+// so external diff tools can compare them
+func (d *Deployer) captureManifests(chartPath string, summary *Summary) error {
+	releaseName := summary.ReleaseName
+	currentPath, newPath := manifestPaths(d.config.ManifestDir, releaseName)
+	if err := os.MkdirAll(filepath.Dir(currentPath), 0755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+
+	current, err := d.helmClient.CurrentManifest(releaseName, d.config.Namespace)
+	if err != nil {
+		return err
+	}
+	rendered, err := d.helmClient.RenderManifest(d.helmDeployOptions(chartPath, summary))
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(currentPath, []byte(current), 0644); err != nil {
+		return fmt.Errorf("failed to write current manifest: %w", err)
+	}
+	if err := os.WriteFile(newPath, []byte(rendered), 0644); err != nil {
+		return fmt.Errorf("failed to write new manifest: %w", err)
+	}
+
+	d.log.Infof("Captured manifests: %s (current), %s (new)", currentPath, newPath)
+	return nil
+}
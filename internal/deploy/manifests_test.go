@@ -0,0 +1,41 @@
+package deploy
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/testutil"
+)
+
+func TestCaptureManifests(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"helm": `case "$1" in
+get) echo 'kind: Deployment # current' ;;
+template) echo 'kind: Deployment # new' ;;
+esac`})
+	dir := filepath.Join(t.TempDir(), "manifests")
+	d := New(&config.Config{Namespace: "prod", ManifestDir: dir, ImageTagKey: "image.tag"}, false)
+	if err := d.captureManifests("./chart", &Summary{ReleaseName: "web", TargetTag: "v2"}); err != nil {
+		t.Fatalf("captureManifests() error = %v", err)
+	}
+
+	for file, want := range map[string]string{"web-current.yaml": "# current", "web-new.yaml": "# new"} {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil || !strings.Contains(string(data), want) {
+			t.Errorf("%s = %q, %v; want %q", file, data, err, want)
+		}
+	}
+	if got := testutil.Calls(t, log); !slices.Contains(got, "helm template web ./chart --namespace prod --set image.tag=v2") {
+		t.Errorf("calls = %q, want the upgrade rendered with the new tag", got)
+	}
+}
+
+func TestManifestPathsDefaultDir(t *testing.T) {
+	current, next := manifestPaths("", "web")
+	if current != "web-current.yaml" || next != "web-new.yaml" {
+		t.Errorf("manifestPaths() = %q, %q", current, next)
+	}
+}
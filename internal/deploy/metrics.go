@@ -0,0 +1,35 @@
+package deploy
+
+import (
+	"time"
+
+	"sbi-deployment/internal/metrics"
+)
+
+// emitMetrics sends the deploy duration and outcome to STATSD_ADDR; send failures are only logged
+func (d *Deployer) emitMetrics(summary *Summary, deployErr error, elapsed time.Duration) {
+	if d.config.StatsDAddr == "" {
+		return
+	}
+
+	// Plain StatsD servers don't understand DogStatsD tags, so they are opt-in
+	var tags map[string]string
+	if d.config.StatsDTags {
+		tags = map[string]string{"namespace": d.config.Namespace}
+		if summary != nil {
+			tags["release"] = summary.ReleaseName
+		}
+	}
+	result := "success"
+	if deployErr != nil {
+		result = "failure"
+	}
+
+	client := metrics.New(d.config.StatsDAddr, d.config.StatsDPrefix, tags)
+	if err := client.Send(
+		metrics.Timing("deploy.duration", elapsed),
+		metrics.Count("deploy."+result, 1),
+	); err != nil {
+		d.log.Warnf("%v", err)
+	}
+}
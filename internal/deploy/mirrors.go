@@ -0,0 +1,85 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/docker"
+)
+
+// mirrorRef returns image with its primary Harbor registry replaced by mirror
+func mirrorRef(image, primary, mirror string) string {
+	return mirror + strings.TrimPrefix(image, primary)
+}
+
+// mirrorRefs returns the target image's reference in every HARBOR_REGISTRIES mirror
+func (d *Deployer) mirrorRefs(targetImage string) []string {
+	var refs []string
+	for _, mirror := range d.config.HarborMirrors() {
+		refs = append(refs, mirrorRef(targetImage, d.config.HarborRegistry, mirror))
+	}
+	return refs
+}
+
+// pushMirrors copies the synced image to every HARBOR_REGISTRIES mirror with the Harbor
+// credentials; all mirrors are attempted and their failures reported together, retrying from the sync's budget
+func (d *Deployer) pushMirrors(summary *Summary, credentials *config.Credentials, budget *retryBudget) error {
+	refs := d.mirrorRefs(summary.TargetImage)
+	if len(refs) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for i, mirror := range d.config.HarborMirrors() {
+		ref := refs[i]
+		if err := d.pushMirror(mirror, ref, summary, credentials, budget); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", mirror, err))
+			continue
+		}
+		summary.Mirrors = append(summary.Mirrors, ref)
+		d.log.Infof("Mirrored image to %s", ref)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("push to %d of %d Harbor mirrors failed:\n%w", len(errs), len(refs), errors.Join(errs...))
+	}
+	return nil
+}
+
+// pushMirror pushes the local image to one mirror, or copies a manifest list there from the primary
+func (d *Deployer) pushMirror(mirror, ref string, summary *Summary, credentials *config.Credentials, budget *retryBudget) error {
+	if err := budget.do("Login to "+mirror, func() error {
+		return d.dockerClient.Login(mirror, credentials.HarborUsername, credentials.HarborPassword)
+	}); err != nil {
+		return err
+	}
+	if d.config.PreserveManifestList {
+		return budget.do("Manifest list copy to "+mirror, func() error {
+			return d.dockerClient.CopyManifestList(summary.TargetImage, ref)
+		})
+	}
+	if err := d.dockerClient.Tag(d.localImage(summary), ref); err != nil {
+		return err
+	}
+	return budget.do("Push to "+mirror, func() error {
+		return d.dockerClient.Push(ref)
+	})
+}
+
+// planMirrors lists the commands pushMirrors runs
+func (d *Deployer) planMirrors(summary *Summary, credentials *config.Credentials) [][]string {
+	runtime := d.dockerClient.Runtime()
+	var commands [][]string
+	for i, ref := range d.mirrorRefs(summary.TargetImage) {
+		commands = append(commands, d.plannedCommand(runtime, docker.LoginArgs(d.config.HarborMirrors()[i], credentials.HarborUsername)))
+		if d.config.PreserveManifestList {
+			commands = append(commands, d.plannedCommand(runtime, docker.ManifestCopyArgs(summary.TargetImage, ref)))
+		} else {
+			commands = append(commands,
+				d.plannedCommand(runtime, docker.TagArgs(d.localImage(summary), ref)),
+				d.plannedCommand(runtime, docker.PushArgs(ref)))
+		}
+	}
+	return commands
+}
@@ -0,0 +1,51 @@
+package deploy
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/testutil"
+)
+
+func TestMirrorRefs(t *testing.T) {
+	d := &Deployer{config: &config.Config{
+		HarborRegistry:   "harbor.dc1.example.com",
+		HarborRegistries: []string{"harbor.dc1.example.com", "harbor.dc2.example.com", "harbor.dc2.example.com", "harbor.dr.example.com"},
+	}}
+	got := d.mirrorRefs("harbor.dc1.example.com/team/web:v1")
+	want := []string{"harbor.dc2.example.com/team/web:v1", "harbor.dr.example.com/team/web:v1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("mirrorRefs() = %q, want %q", got, want)
+	}
+}
+
+func TestMirrorRefsWithoutMirrors(t *testing.T) {
+	d := &Deployer{config: &config.Config{HarborRegistry: "harbor.example.com"}}
+	if got := d.mirrorRefs("harbor.example.com/team/web:v1"); len(got) != 0 {
+		t.Errorf("mirrorRefs() = %q, want none", got)
+	}
+}
+
+func TestPushMirrorsContinuesPastFailures(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"docker": `case "$*" in "push harbor.dc2."*) exit 1;; esac`})
+	d := New(&config.Config{
+		ContainerRuntime: "docker",
+		HarborRegistry:   "harbor.dc1.example.com",
+		HarborRegistries: []string{"harbor.dc2.example.com", "harbor.dr.example.com"},
+	}, false)
+	summary := &Summary{TargetImage: "harbor.dc1.example.com/web:v1", SourceImage: "nexus.example.com/web:v1"}
+	budget := newRetryBudget(0, 0, nil)
+
+	err := d.pushMirrors(summary, &config.Credentials{HarborUsername: "ci", HarborPassword: "secret"}, budget)
+	if err == nil || !strings.Contains(err.Error(), "push to 1 of 2 Harbor mirrors failed") || !strings.Contains(err.Error(), "harbor.dc2.example.com") {
+		t.Errorf("pushMirrors() error = %v, want the dc2 failure", err)
+	}
+	if !slices.Equal(summary.Mirrors, []string{"harbor.dr.example.com/web:v1"}) {
+		t.Errorf("Mirrors = %q, want only the mirror that succeeded", summary.Mirrors)
+	}
+	if got := testutil.Calls(t, log); !slices.Contains(got, "docker push harbor.dr.example.com/web:v1") {
+		t.Errorf("calls = %q, want the dr push after the dc2 failure", got)
+	}
+}
@@ -0,0 +1,33 @@
+package deploy
+
+import (
+	"sbi-deployment/internal/notify"
+)
+
+// notify posts the deployment outcome to NOTIFY_WEBHOOK_URL; failures to notify are only logged
+func (d *Deployer) notify(imageTag, imageName string, summary *Summary, deployErr error) {
+	if d.config.NotifyWebhookURL == "" {
+		return
+	}
+
+	event := notify.Event{
+		Release:   d.config.ReleaseName,
+		Namespace: d.config.Namespace,
+		Image:     d.resolveImageName(imageName),
+		Tag:       imageTag,
+		Success:   deployErr == nil,
+	}
+	if summary != nil {
+		event.Release = summary.ReleaseName
+		event.Image = summary.ImageName
+		event.Tag = summary.TargetTag
+		event.Duration = summary.Duration
+	}
+	if deployErr != nil {
+		event.Error = deployErr.Error()
+	}
+
+	if err := notify.New(d.config.NotifyWebhookURL, d.config.NotifyFormat).Send(event); err != nil {
+		d.log.Warnf("%v", err)
+	}
+}
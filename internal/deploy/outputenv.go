@@ -0,0 +1,81 @@
+package deploy
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// githubEnvDelimiter closes a multi-line value in the $GITHUB_ENV heredoc syntax
+const githubEnvDelimiter = "SBI_DEPLOY_EOF"
+
+// shellSafe matches values that need no quoting when the file is sourced
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// EnvLines renders a deploy summary as KEY=value lines, shell-quoted for `source` or, with
+// githubEnv, in the $GITHUB_ENV format (taken literally, multi-line values as heredocs);
+// empty values are left out
+func EnvLines(s *Summary, githubEnv bool) []string {
+	pairs := [][2]string{
+		{"DEPLOYED_IMAGE", s.TargetImage},
+		{"DEPLOYED_TAG", s.TargetTag},
+		{"DEPLOYED_DIGEST", s.ImageDigest},
+		{"DEPLOYED_RELEASE", s.ReleaseName},
+		{"DEPLOYED_NAMESPACE", s.Namespace},
+		{"DEPLOYED_CHART", s.ChartPath},
+	}
+	if s.Revision > 0 {
+		pairs = append(pairs, [2]string{"DEPLOYED_REVISION", strconv.Itoa(s.Revision)})
+	}
+	pairs = append(pairs, [2]string{"DEPLOYED_UNCHANGED", strconv.FormatBool(s.Unchanged)})
+
+	var lines []string
+	for _, pair := range pairs {
+		key, value := pair[0], pair[1]
+		switch {
+		case value == "":
+		case githubEnv && strings.Contains(value, "\n"):
+			lines = append(lines, key+"<<"+githubEnvDelimiter+"\n"+value+"\n"+githubEnvDelimiter)
+		case githubEnv:
+			lines = append(lines, key+"="+value)
+		default:
+			lines = append(lines, key+"="+shellQuote(value))
+		}
+	}
+	return lines
+}
+
+// shellQuote single-quotes value for a POSIX shell unless it is already safe
+func shellQuote(value string) string {
+	if shellSafe.MatchString(value) {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// writeOutputEnv appends the deploy summary to OutputEnvFile, so $GITHUB_ENV keeps earlier steps' values
+func (d *Deployer) writeOutputEnv(summary *Summary) error {
+	// Multi-cluster deploys have one revision per cluster, so none is exported
+	if len(summary.Clusters) == 0 && !summary.Unchanged {
+		revision, err := d.helmClient.CurrentRevision(summary.ReleaseName, d.config.Namespace)
+		if err != nil {
+			d.log.Warnf("Could not read deployed revision for --output-env: %v", err)
+		}
+		summary.Revision = revision
+	}
+
+	file, err := os.OpenFile(d.config.OutputEnvFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open output env file: %w", err)
+	}
+	defer file.Close()
+
+	// The runner reads $GITHUB_ENV literally, so quoting is only for files that are sourced
+	githubEnv := d.config.OutputEnvFile == os.Getenv("GITHUB_ENV")
+	if _, err := file.WriteString(strings.Join(EnvLines(summary, githubEnv), "\n") + "\n"); err != nil {
+		return fmt.Errorf("failed to write output env file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package deploy
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEnvLines(t *testing.T) {
+	s := &Summary{
+		TargetImage: "harbor.example.com/team/web:v1",
+		TargetTag:   "v1",
+		ReleaseName: "web",
+		Namespace:   "prod",
+		Revision:    7,
+	}
+	got := EnvLines(s, false)
+	want := []string{
+		"DEPLOYED_IMAGE=harbor.example.com/team/web:v1",
+		"DEPLOYED_TAG=v1",
+		"DEPLOYED_RELEASE=web",
+		"DEPLOYED_NAMESPACE=prod",
+		"DEPLOYED_REVISION=7",
+		"DEPLOYED_UNCHANGED=false",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("EnvLines() = %q, want %q", got, want)
+	}
+}
+
+func TestEnvLinesShellQuotesValues(t *testing.T) {
+	chart := "/charts/it's a chart $(touch pwned)"
+	lines := EnvLines(&Summary{ChartPath: chart}, false)
+
+	file := filepath.Join(t.TempDir(), "deploy.env")
+	if err := os.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("sh", "-c", `. "$1" && printf %s "$DEPLOYED_CHART"`, "sh", file)
+	cmd.Dir = t.TempDir()
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("sourcing %q failed: %v", lines, err)
+	}
+	if string(output) != chart {
+		t.Errorf("sourced DEPLOYED_CHART = %q, want %q", output, chart)
+	}
+	if _, err := os.Stat(filepath.Join(cmd.Dir, "pwned")); err == nil {
+		t.Errorf("sourcing the file ran a command substitution")
+	}
+}
+
+func TestEnvLinesGitHubEnv(t *testing.T) {
+	lines := EnvLines(&Summary{ReleaseName: "web app", ChartPath: "line1\nline2"}, true)
+	want := []string{
+		"DEPLOYED_RELEASE=web app",
+		"DEPLOYED_CHART<<SBI_DEPLOY_EOF\nline1\nline2\nSBI_DEPLOY_EOF",
+		"DEPLOYED_UNCHANGED=false",
+	}
+	if !slices.Equal(lines, want) {
+		t.Errorf("EnvLines() = %q, want %q", lines, want)
+	}
+}
@@ -0,0 +1,39 @@
+package deploy
+
+import "fmt"
+
+// checkOwnerLabel compares a release's owner label against the expected owner
+func checkOwnerLabel(labels map[string]string, key, expected string) error {
+	owner, ok := labels[key]
+	if !ok {
+		return fmt.Errorf("release has no %s label, expected %s=%s", key, key, expected)
+	}
+	if owner != expected {
+		return fmt.Errorf("release is owned by %s=%s, expected %s", key, owner, expected)
+	}
+	return nil
+}
+
+// checkReleaseOwner refuses to upgrade a live release that EXPECTED_RELEASE_OWNER does not own;
+// a release that is not installed yet is created with the owner label
+func (d *Deployer) checkReleaseOwner(releaseName string) error {
+	labels, found, err := d.helmClient.ReleaseLabels(releaseName, d.config.Namespace)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	if err := checkOwnerLabel(labels, d.config.ReleaseOwnerLabel, d.config.ExpectedReleaseOwner); err != nil {
+		return fmt.Errorf("refusing to upgrade %s in %s: %w", releaseName, d.config.Namespace, err)
+	}
+	return nil
+}
+
+// ownerLabels returns the labels that mark a release as owned by EXPECTED_RELEASE_OWNER
+func (d *Deployer) ownerLabels() map[string]string {
+	if d.config.ExpectedReleaseOwner == "" {
+		return nil
+	}
+	return map[string]string{d.config.ReleaseOwnerLabel: d.config.ExpectedReleaseOwner}
+}
@@ -0,0 +1,47 @@
+package deploy
+
+import (
+	"maps"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/testutil"
+)
+
+func TestCheckOwnerLabel(t *testing.T) {
+	if err := checkOwnerLabel(map[string]string{"owner": "payments"}, "owner", "payments"); err != nil {
+		t.Errorf("checkOwnerLabel(match) = %v", err)
+	}
+	if err := checkOwnerLabel(map[string]string{"owner": "search"}, "owner", "payments"); err == nil || !strings.Contains(err.Error(), "owned by owner=search") {
+		t.Errorf("checkOwnerLabel(other owner) = %v", err)
+	}
+	if err := checkOwnerLabel(nil, "owner", "payments"); err == nil || !strings.Contains(err.Error(), "has no owner label") {
+		t.Errorf("checkOwnerLabel(unlabelled) = %v", err)
+	}
+}
+
+func TestCheckReleaseOwner(t *testing.T) {
+	cfg := &config.Config{Namespace: "prod", ReleaseOwnerLabel: "owner", ExpectedReleaseOwner: "payments"}
+
+	testutil.FakeTools(t, map[string]string{"helm": `echo '{"labels": {"owner": "search"}}'`})
+	if err := New(cfg, false).checkReleaseOwner("web"); err == nil || !strings.Contains(err.Error(), "refusing to upgrade web in prod") {
+		t.Errorf("checkReleaseOwner(other owner) = %v", err)
+	}
+
+	testutil.FakeTools(t, map[string]string{"helm": "echo 'Error: release: not found' >&2; exit 1"})
+	if err := New(cfg, false).checkReleaseOwner("web"); err != nil {
+		t.Errorf("checkReleaseOwner(new release) = %v, want it allowed", err)
+	}
+}
+
+func TestOwnerLabels(t *testing.T) {
+	d := &Deployer{config: &config.Config{ReleaseOwnerLabel: "owner"}}
+	if labels := d.ownerLabels(); labels != nil {
+		t.Errorf("ownerLabels() = %v without EXPECTED_RELEASE_OWNER, want nil", labels)
+	}
+	d.config.ExpectedReleaseOwner = "payments"
+	if labels := d.ownerLabels(); !maps.Equal(labels, map[string]string{"owner": "payments"}) {
+		t.Errorf("ownerLabels() = %v, want owner=payments", labels)
+	}
+}
@@ -0,0 +1,52 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"sbi-deployment/internal/logging"
+)
+
+// preflightCheck is a single named prerequisite check
+type preflightCheck struct {
+	name     string
+	run      func() error
+	commands [][]string // argv of the commands run executes, for audits; PATH lookups and TCP waits have none
+}
+
+// runPreflightChecks runs every check and reports all failures together rather than stopping at the first
+func runPreflightChecks(checks []preflightCheck, parallel bool, log *logging.Logger) error {
+	errs := make([]error, len(checks))
+
+	if parallel {
+		var wg sync.WaitGroup
+		for i, check := range checks {
+			wg.Add(1)
+			go func(i int, check preflightCheck) {
+				defer wg.Done()
+				errs[i] = check.run()
+			}(i, check)
+		}
+		wg.Wait()
+	} else {
+		for i, check := range checks {
+			errs[i] = check.run()
+		}
+	}
+
+	var failures []error
+	for i, err := range errs {
+		if err != nil {
+			log.Infof("   ✗ %s: %v", checks[i].name, err)
+			failures = append(failures, fmt.Errorf("%s: %w", checks[i].name, err))
+		} else {
+			log.Infof("   ✓ %s", checks[i].name)
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d checks failed: %w", len(failures), len(checks), errors.Join(failures...))
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package deploy
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunPreflightChecksReportsAllFailures(t *testing.T) {
+	ran := 0
+	checks := []preflightCheck{
+		{name: "docker", run: func() error { ran++; return errors.New("daemon not running") }},
+		{name: "helm", run: func() error { ran++; return nil }},
+		{name: "kubectl", run: func() error { ran++; return errors.New("not found") }},
+	}
+	err := runPreflightChecks(checks, false, nil)
+	if err == nil {
+		t.Fatal("runPreflightChecks() passed with failing checks")
+	}
+	for _, want := range []string{"2 of 3 checks failed", "docker: daemon not running", "kubectl: not found"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q lacks %q", err, want)
+		}
+	}
+	if ran != 3 {
+		t.Errorf("ran %d checks, want all 3", ran)
+	}
+}
+
+func TestRunPreflightChecksParallel(t *testing.T) {
+	// Each check waits for both to start, which only finishes if they run concurrently
+	var started sync.WaitGroup
+	started.Add(2)
+	wait := func() error {
+		started.Done()
+		done := make(chan struct{})
+		go func() { started.Wait(); close(done) }()
+		select {
+		case <-done:
+			return nil
+		case <-time.After(5 * time.Second):
+			return errors.New("checks did not run concurrently")
+		}
+	}
+	checks := []preflightCheck{{name: "a", run: wait}, {name: "b", run: wait}}
+	if err := runPreflightChecks(checks, true, nil); err != nil {
+		t.Errorf("runPreflightChecks() error = %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package deploy
+
+import (
+	"fmt"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/docker"
+)
+
+// ensurePullSecret creates or updates CREATE_PULL_SECRET in the release namespace from the
+// Harbor credentials, so the pods can pull the image helm is about to deploy
+func (d *Deployer) ensurePullSecret(credentials *config.Credentials) error {
+	dockerConfig, err := docker.DockerConfigJSON(d.config.HarborRegistry, credentials.HarborUsername, credentials.HarborPassword)
+	if err != nil {
+		return err
+	}
+	if err := d.helmClient.ApplyPullSecret(d.config.CreatePullSecret, d.config.Namespace, dockerConfig); err != nil {
+		return fmt.Errorf("pull secret setup failed: %w", err)
+	}
+	d.log.Infof("Pull secret %s is up to date in %s", d.config.CreatePullSecret, d.config.Namespace)
+	return nil
+}
@@ -0,0 +1,28 @@
+package deploy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/testutil"
+)
+
+func TestEnsurePullSecret(t *testing.T) {
+	stdin := filepath.Join(t.TempDir(), "manifest.json")
+	testutil.FakeTools(t, map[string]string{"kubectl": "cat > " + stdin})
+	d := New(&config.Config{HarborRegistry: "harbor.example.com", Namespace: "prod", CreatePullSecret: "harbor-pull"}, false)
+	if err := d.ensurePullSecret(&config.Credentials{HarborUsername: "robot", HarborPassword: "hunter2"}); err != nil {
+		t.Fatalf("ensurePullSecret() error = %v", err)
+	}
+	if data, err := os.ReadFile(stdin); err != nil || !strings.Contains(string(data), `"name":"harbor-pull"`) || !strings.Contains(string(data), `"namespace":"prod"`) {
+		t.Errorf("applied %q, %v, want harbor-pull in prod", data, err)
+	}
+
+	testutil.FakeTools(t, map[string]string{"kubectl": "exit 1"})
+	if err := d.ensurePullSecret(&config.Credentials{}); err == nil || !strings.Contains(err.Error(), "pull secret setup failed") {
+		t.Errorf("ensurePullSecret() error = %v", err)
+	}
+}
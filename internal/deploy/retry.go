@@ -0,0 +1,67 @@
+package deploy
+
+import (
+	"fmt"
+	"time"
+
+	"sbi-deployment/internal/logging"
+)
+
+// retryBudget is a pool of retries and an optional deadline shared by every step of a phase
+type retryBudget struct {
+	total     int
+	remaining int
+	deadline  time.Time
+	backoff   time.Duration
+	log       *logging.Logger
+
+	// Called before each retry with the step and the attempt that failed
+	onRetry func(step string, attempt int, err error)
+}
+
+// newRetryBudget creates a budget of retries; a zero timeout means no overall time cap
+func newRetryBudget(retries int, timeout time.Duration, log *logging.Logger) *retryBudget {
+	b := &retryBudget{
+		total:     retries,
+		remaining: retries,
+		backoff:   2 * time.Second,
+		log:       log,
+	}
+	if timeout > 0 {
+		b.deadline = time.Now().Add(timeout)
+	}
+	return b
+}
+
+// expired reports whether the overall time cap has passed
+func (b *retryBudget) expired() bool {
+	return !b.deadline.IsZero() && time.Now().After(b.deadline)
+}
+
+// do runs fn, retrying failures while retries remain in the shared budget and time remains
+func (b *retryBudget) do(step string, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if b.remaining <= 0 {
+			return fmt.Errorf("%s failed after %d attempts, retry budget exhausted: %w", step, attempt, err)
+		}
+		if b.expired() {
+			return fmt.Errorf("%s failed after %d attempts, sync time limit reached: %w", step, attempt, err)
+		}
+
+		b.remaining--
+		if b.onRetry != nil {
+			b.onRetry(step, attempt, err)
+		}
+		b.log.Infof("%s attempt %d failed, retrying (%d of %d retries left)...", step, attempt, b.remaining, b.total)
+		time.Sleep(b.backoff)
+	}
+}
+
+// String describes the remaining budget for logging
+func (b *retryBudget) String() string {
+	return fmt.Sprintf("%d of %d retries remaining", b.remaining, b.total)
+}
@@ -0,0 +1,106 @@
+package deploy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/testutil"
+)
+
+// failing returns a step that fails the first n calls and counts every call
+func failing(n int, calls *int) func() error {
+	return func() error {
+		*calls++
+		if *calls <= n {
+			return errors.New("flaky")
+		}
+		return nil
+	}
+}
+
+func TestRetryBudgetSharedAcrossSteps(t *testing.T) {
+	budget := newRetryBudget(2, 0, nil)
+	budget.backoff = 0
+
+	var build, push int
+	if err := budget.do("Build", failing(1, &build)); err != nil {
+		t.Fatalf("Build: unexpected error %v", err)
+	}
+	err := budget.do("Push", failing(5, &push))
+	if err == nil || !strings.Contains(err.Error(), "retry budget exhausted") {
+		t.Fatalf("Push: got %v, want an exhausted budget", err)
+	}
+	if build != 2 || push != 2 {
+		t.Errorf("calls = build %d, push %d; want 2 and 2 from one budget of 2 retries", build, push)
+	}
+}
+
+func TestRetryBudgetDeadline(t *testing.T) {
+	budget := newRetryBudget(10, time.Nanosecond, nil)
+	budget.backoff = 0
+	time.Sleep(time.Millisecond)
+
+	var calls int
+	err := budget.do("Push", failing(5, &calls))
+	if err == nil || !strings.Contains(err.Error(), "sync time limit reached") {
+		t.Fatalf("got %v, want the sync time limit", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1 after the deadline passed", calls)
+	}
+}
+
+func TestRetryBudgetReportsRetries(t *testing.T) {
+	budget := newRetryBudget(3, 0, nil)
+	budget.backoff = 0
+	var steps []string
+	budget.onRetry = func(step string, attempt int, err error) {
+		steps = append(steps, step)
+	}
+
+	var calls int
+	if err := budget.do("Build", failing(2, &calls)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(steps) != 2 || budget.String() != "1 of 3 retries remaining" {
+		t.Errorf("onRetry called for %v, budget %s", steps, budget)
+	}
+}
+
+func TestCopyManifestListRetriesWithinBudget(t *testing.T) {
+	dir := t.TempDir()
+	log := testutil.FakeTools(t, map[string]string{
+		"docker": `case "$1" in buildx) [ -f ` + dir + `/copied ] || { touch ` + dir + `/copied; exit 1; };; esac`,
+	})
+	d := New(&config.Config{ContainerRuntime: "docker", HarborRegistry: "harbor.example.com"}, false)
+	budget := newRetryBudget(1, 0, nil)
+	budget.backoff = 0
+
+	credentials := &config.Credentials{HarborUsername: "ci", HarborPassword: "secret"}
+	if err := d.copyManifestList("nexus.example.com/web:v1", "harbor.example.com/web:v1", credentials, budget); err != nil {
+		t.Fatalf("copyManifestList() error = %v", err)
+	}
+	got := testutil.Calls(t, log)
+	copies := 0
+	for _, call := range got {
+		if strings.HasPrefix(call, "docker buildx imagetools create --tag harbor.example.com/web:v1 nexus.example.com/web:v1") {
+			copies++
+		}
+	}
+	if copies != 2 || budget.String() != "0 of 1 retries remaining" {
+		t.Errorf("calls = %q, budget %s; want one retried copy", got, budget)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "copied")); err != nil {
+		t.Fatal(err)
+	}
+	err := d.copyManifestList("nexus.example.com/web:v1", "harbor.example.com/web:v1", credentials, budget)
+	if err == nil || !strings.Contains(err.Error(), "retry budget exhausted") {
+		t.Errorf("copyManifestList() with a spent budget error = %v, want an exhausted budget", err)
+	}
+}
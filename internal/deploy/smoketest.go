@@ -0,0 +1,66 @@
+package deploy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// smokeTestBodyLimit caps how much of a smoke test response is read for the body check
+const smokeTestBodyLimit = 1 << 20
+
+// smokeTest GETs url until it answers with expectStatus (and a body containing expectBody,
+// when set) or timeout elapses
+func smokeTest(client *http.Client, url string, expectStatus int, expectBody string, timeout, interval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := probe(client, url, expectStatus, expectBody)
+		if err == nil {
+			return nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return fmt.Errorf("smoke test of %s failed after %s: %w", url, timeout, err)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// probe performs a single smoke test request
+func probe(client *http.Client, url string, expectStatus int, expectBody string) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, smokeTestBodyLimit))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	return checkSmokeResponse(resp.StatusCode, string(body), expectStatus, expectBody)
+}
+
+// checkSmokeResponse compares a smoke test response against the expected status and body substring
+func checkSmokeResponse(status int, body string, expectStatus int, expectBody string) error {
+	if status != expectStatus {
+		return fmt.Errorf("got status %d, want %d", status, expectStatus)
+	}
+	if expectBody != "" && !strings.Contains(body, expectBody) {
+		return fmt.Errorf("response body does not contain %q", expectBody)
+	}
+	return nil
+}
+
+// runSmokeTest probes SMOKE_TEST_URL after the rollout is healthy
+func (d *Deployer) runSmokeTest() error {
+	d.log.Infof("Running smoke test against %s...", d.config.SmokeTestURL)
+	client := &http.Client{Timeout: 10 * time.Second}
+	timeout := time.Duration(d.config.SmokeTestTimeout) * time.Second
+	if err := smokeTest(client, d.config.SmokeTestURL, d.config.SmokeTestExpectStatus, d.config.SmokeTestExpectBody, timeout, 3*time.Second); err != nil {
+		return err
+	}
+	d.log.Infof("Smoke test passed")
+	return nil
+}
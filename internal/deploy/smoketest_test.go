@@ -0,0 +1,55 @@
+package deploy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckSmokeResponse(t *testing.T) {
+	tests := []struct {
+		status  int
+		body    string
+		wantErr string
+	}{
+		{200, `{"status": "ok"}`, ""},
+		{503, `{"status": "ok"}`, "got status 503, want 200"},
+		{200, `{"status": "degraded"}`, `does not contain "\"ok\""`},
+	}
+	for _, tt := range tests {
+		err := checkSmokeResponse(tt.status, tt.body, 200, `"ok"`)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("checkSmokeResponse(%d, %s) error = %v", tt.status, tt.body, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("checkSmokeResponse(%d, %s) error = %v, want %q", tt.status, tt.body, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSmokeTestWaitsForHealthy(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ready"))
+	}))
+	defer server.Close()
+
+	if err := smokeTest(server.Client(), server.URL, 200, "ready", time.Second, time.Millisecond); err != nil {
+		t.Errorf("smokeTest() error = %v", err)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("made %d requests, want 3", requests.Load())
+	}
+
+	err := smokeTest(server.Client(), server.URL, 200, "version 2", 10*time.Millisecond, time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "failed after 10ms") {
+		t.Errorf("smokeTest() error = %v, want a timeout", err)
+	}
+}
@@ -0,0 +1,47 @@
+package deploy
+
+import (
+	"errors"
+)
+
+// step is one stage of a deployment. A deploy calls run; an audit calls plan for the argv of every
+// command run would execute. Both walk the same step lists, so the audit record follows the deploy path.
+type step struct {
+	when  bool              // the configuration enables the step
+	phase string            // lifecycle phase the step reports, if any
+	run   func() error      // performs the step
+	plan  func() [][]string // commands run would execute; nil for steps that run none
+}
+
+// errUnchanged stops a deployment early because the release already runs the target tag
+var errUnchanged = errors.New("release already at the target tag")
+
+// runSteps runs the enabled steps in order, stopping at the first error
+func (d *Deployer) runSteps(steps []step) error {
+	for _, s := range steps {
+		if !s.when {
+			continue
+		}
+		end := func(error) {}
+		if s.phase != "" {
+			end = d.startPhase(s.phase)
+		}
+		err := s.run()
+		end(err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// planSteps lists the commands of the enabled steps in the order runSteps would execute them
+func planSteps(steps []step) [][]string {
+	var commands [][]string
+	for _, s := range steps {
+		if s.when && s.plan != nil {
+			commands = append(commands, s.plan()...)
+		}
+	}
+	return commands
+}
@@ -0,0 +1,37 @@
+package deploy
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"sbi-deployment/internal/config"
+)
+
+func TestRunSteps(t *testing.T) {
+	var ran []string
+	record := func(name string, err error) func() error {
+		return func() error {
+			ran = append(ran, name)
+			return err
+		}
+	}
+	d := &Deployer{config: &config.Config{}}
+	failure := errors.New("fails")
+	steps := []step{
+		{when: true, run: record("first", nil), plan: func() [][]string { return [][]string{{"first"}} }},
+		{when: false, run: record("disabled", nil), plan: func() [][]string { return [][]string{{"disabled"}} }},
+		{when: true, run: record("no commands", nil)},
+		{when: true, phase: PhaseSync, run: record("failing", failure), plan: func() [][]string { return [][]string{{"failing"}} }},
+		{when: true, run: record("after", nil), plan: func() [][]string { return [][]string{{"after"}} }},
+	}
+	if err := d.runSteps(steps); !errors.Is(err, failure) {
+		t.Errorf("runSteps() error = %v, want the failing step's", err)
+	}
+	if want := []string{"first", "no commands", "failing"}; !slices.Equal(ran, want) {
+		t.Errorf("ran %q, want %q", ran, want)
+	}
+	if got, want := planSteps(steps), [][]string{{"first"}, {"failing"}, {"after"}}; !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("planSteps() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,104 @@
+package deploy
+
+import (
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/docker"
+)
+
+// imageSyncer gets the deploy image into Harbor
+type imageSyncer interface {
+	Sync(sourceImage, targetImage string, credentials *config.Credentials, budget *retryBudget) error
+	// Plan lists the commands Sync runs, for audits; retried commands are listed once
+	Plan(sourceImage, targetImage string, credentials *config.Credentials) [][]string
+}
+
+// nexusSyncer copies the image from Nexus to Harbor
+type nexusSyncer struct {
+	d *Deployer
+}
+
+func (s nexusSyncer) Sync(sourceImage, targetImage string, credentials *config.Credentials, budget *retryBudget) error {
+	return s.d.syncImage(sourceImage, targetImage, credentials, budget)
+}
+
+func (s nexusSyncer) Plan(sourceImage, targetImage string, credentials *config.Credentials) [][]string {
+	d := s.d
+	runtime := d.dockerClient.Runtime()
+	commands := [][]string{d.plannedCommand(runtime, docker.LoginArgs(d.config.NexusRegistry, credentials.NexusUsername))}
+	if d.config.PreserveManifestList {
+		if d.config.ScanBeforePush {
+			commands = append(commands, d.plannedCommand(d.scanner.Command(), d.scanner.Args(sourceImage)))
+		}
+		return append(commands,
+			d.plannedCommand(runtime, docker.LoginArgs(d.config.HarborRegistry, credentials.HarborUsername)),
+			d.plannedCommand(runtime, docker.ManifestCopyArgs(sourceImage, targetImage)))
+	}
+	commands = append(commands, d.plannedCommand(runtime, docker.PullArgs(sourceImage, d.config.DockerPlatform)))
+	if d.config.ScanBeforePush {
+		commands = append(commands, d.plannedCommand(d.scanner.Command(), d.scanner.Args(sourceImage)))
+	}
+	return append(commands,
+		d.plannedCommand(runtime, docker.TagArgs(sourceImage, targetImage)),
+		d.plannedCommand(runtime, docker.LoginArgs(d.config.HarborRegistry, credentials.HarborUsername)),
+		d.plannedCommand(runtime, docker.PushArgs(targetImage)))
+}
+
+// buildSyncer builds the image from BUILD_CONTEXT and pushes it to Harbor; Nexus is not used
+type buildSyncer struct {
+	d *Deployer
+}
+
+func (s buildSyncer) Sync(_, targetImage string, credentials *config.Credentials, budget *retryBudget) error {
+	d := s.d
+	d.log.Infof("Building image from %s...", d.config.BuildContext)
+
+	if err := budget.do("Build", func() error {
+		return d.dockerClient.Build(targetImage, d.config.BuildContext, d.config.Dockerfile, d.config.BuildArgs)
+	}); err != nil {
+		return err
+	}
+	if err := d.scanImage(targetImage); err != nil {
+		return err
+	}
+	if err := budget.do("Harbor login", func() error {
+		return d.dockerClient.Login(d.config.HarborRegistry, credentials.HarborUsername, credentials.HarborPassword)
+	}); err != nil {
+		return err
+	}
+	if err := budget.do("Push", func() error {
+		return d.dockerClient.Push(targetImage)
+	}); err != nil {
+		return err
+	}
+
+	d.log.Infof("Image build and push completed successfully (%s)", budget)
+	return nil
+}
+
+func (s buildSyncer) Plan(_, targetImage string, credentials *config.Credentials) [][]string {
+	d := s.d
+	runtime := d.dockerClient.Runtime()
+	commands := [][]string{d.plannedCommand(runtime, docker.BuildArgs(targetImage, d.config.BuildContext, d.config.Dockerfile, d.config.DockerPlatform, d.config.BuildArgs))}
+	if d.config.ScanBeforePush {
+		commands = append(commands, d.plannedCommand(d.scanner.Command(), d.scanner.Args(targetImage)))
+	}
+	return append(commands,
+		d.plannedCommand(runtime, docker.LoginArgs(d.config.HarborRegistry, credentials.HarborUsername)),
+		d.plannedCommand(runtime, docker.PushArgs(targetImage)))
+}
+
+// syncer returns how the deploy image reaches Harbor
+func (d *Deployer) syncer() imageSyncer {
+	if d.config.BuildContext != "" {
+		return buildSyncer{d: d}
+	}
+	return nexusSyncer{d: d}
+}
+
+// localImage returns the local image whose size is reported after the sync
+func (d *Deployer) localImage(summary *Summary) string {
+	if d.config.BuildContext != "" {
+		return summary.TargetImage
+	}
+	return summary.SourceImage
+}
@@ -0,0 +1,62 @@
+package deploy
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/testutil"
+)
+
+func TestSyncer(t *testing.T) {
+	summary := &Summary{SourceImage: "nexus.example.com/web:v1", TargetImage: "harbor.example.com/web:v1"}
+
+	d := &Deployer{config: &config.Config{}}
+	if _, ok := d.syncer().(nexusSyncer); !ok || d.localImage(summary) != summary.SourceImage {
+		t.Errorf("without BUILD_CONTEXT: syncer %T, local image %q", d.syncer(), d.localImage(summary))
+	}
+	d = &Deployer{config: &config.Config{BuildContext: "."}}
+	if _, ok := d.syncer().(buildSyncer); !ok || d.localImage(summary) != summary.TargetImage {
+		t.Errorf("with BUILD_CONTEXT: syncer %T, local image %q", d.syncer(), d.localImage(summary))
+	}
+}
+
+func TestBuildSyncerSkipsNexus(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"docker": "exit 0"})
+	cfg := &config.Config{ContainerRuntime: "docker", HarborRegistry: "harbor.example.com", BuildContext: "./app", Dockerfile: "Dockerfile.prod"}
+	budget := newRetryBudget(0, 0, nil)
+	credentials := &config.Credentials{HarborUsername: "ci", HarborPassword: "secret"}
+	if err := New(cfg, false).syncer().Sync("nexus.example.com/web:v1", "harbor.example.com/web:v1", credentials, budget); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	want := []string{
+		"docker build -t harbor.example.com/web:v1 -f Dockerfile.prod ./app",
+		"docker login harbor.example.com -u ci --password-stdin",
+		"docker push harbor.example.com/web:v1",
+	}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestSyncPhaseRunsPlannedCommands(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	log := testutil.FakeTools(t, map[string]string{
+		"docker": `if [ "$1" = image ]; then echo '[{"Size": 1024, "RepoDigests": ["harbor.example.com/web@` + digest + `"]}]'; fi`,
+	})
+	cfg := &config.Config{ContainerRuntime: "docker", NexusRegistry: "nexus.example.com", HarborRegistry: "harbor.example.com", HarborRegistries: []string{"harbor.example.com", "harbor-dr.example.com"}}
+	d := New(cfg, false)
+	summary := &Summary{ImageTag: "v1", SourceImage: "nexus.example.com/web:v1", TargetImage: "harbor.example.com/web:v1"}
+	credentials := &config.Credentials{NexusUsername: "ci", HarborUsername: "ci"}
+	if err := d.syncPhase(summary, credentials); err != nil {
+		t.Fatalf("syncPhase() error = %v", err)
+	}
+	var planned []string
+	for _, argv := range planSteps(d.syncSteps(&Summary{ImageTag: "v1", SourceImage: summary.SourceImage, TargetImage: summary.TargetImage}, credentials, nil)) {
+		planned = append(planned, strings.Join(argv, " "))
+	}
+	if got := testutil.Calls(t, log); !slices.Equal(got, planned) {
+		t.Errorf("syncPhase ran %q, audit plans %q", got, planned)
+	}
+}
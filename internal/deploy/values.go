@@ -0,0 +1,82 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"sbi-deployment/internal/helm"
+	"sbi-deployment/internal/utils"
+)
+
+// runValuesScript runs VALUES_SCRIPT and writes its stdout to a temp file for helm --values;
+// the caller removes the file. The script sees the release, namespace, and tag in SBI_* variables.
+func (d *Deployer) runValuesScript(summary *Summary) (string, error) {
+	d.log.Infof("Generating values with %s...", d.config.ValuesScript)
+
+	cmd := utils.Command(d.config.ValuesScript)
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env,
+		"SBI_RELEASE="+summary.ReleaseName,
+		"SBI_NAMESPACE="+summary.Namespace,
+		"SBI_TAG="+summary.TargetTag,
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("values script %s failed: %w: %s", d.config.ValuesScript, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("values script %s failed: %w", d.config.ValuesScript, err)
+	}
+	if err := helm.CheckValuesYAML(output); err != nil {
+		return "", fmt.Errorf("values script %s produced invalid YAML: %w", d.config.ValuesScript, err)
+	}
+	return d.writeValuesFile(output)
+}
+
+// fetchConfigMapValues writes the VALUES_CONFIGMAP key (<configmap>/<key>) from the release
+// namespace to a temp file for helm --values; the caller removes the file
+func (d *Deployer) fetchConfigMapValues() (string, error) {
+	name, key, err := helm.ParseConfigMapRef(d.config.ValuesConfigMap)
+	if err != nil {
+		return "", err
+	}
+	d.log.Infof("Reading base values from configmap %s/%s...", name, key)
+
+	values, err := d.helmClient.ConfigMapValue(name, key, d.config.Namespace)
+	if err != nil {
+		return "", err
+	}
+	if err := helm.CheckValuesYAML(values); err != nil {
+		return "", fmt.Errorf("configmap %s key %s is not a values document: %w", name, key, err)
+	}
+	return d.writeValuesFile(values)
+}
+
+// writeValuesFile writes values to a new temp file and returns its path
+func (d *Deployer) writeValuesFile(values []byte) (string, error) {
+	file, err := os.CreateTemp("", "sbi-values-*.yaml")
+	if err != nil {
+		return "", fmt.Errorf("failed to create values file: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(values); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write values file: %w", err)
+	}
+	d.log.Debugf("Wrote values to %s", file.Name())
+	return file.Name(), nil
+}
+
+// withValuesFile returns a copy of the deployer that also passes path to helm with --values;
+// later files override earlier ones
+func (d *Deployer) withValuesFile(path string) *Deployer {
+	clone := *d
+	clone.valuesFiles = append(append([]string(nil), d.valuesFiles...), path)
+	return &clone
+}
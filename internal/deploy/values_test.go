@@ -0,0 +1,84 @@
+package deploy
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/testutil"
+)
+
+// valuesScript writes an executable VALUES_SCRIPT with body and returns its path
+func valuesScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "values.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunValuesScript(t *testing.T) {
+	script := valuesScript(t, `echo "release: $SBI_RELEASE"; echo "namespace: $SBI_NAMESPACE"; echo "tag: $SBI_TAG"`)
+	d := &Deployer{config: &config.Config{ValuesScript: script}}
+	path, err := d.runValuesScript(&Summary{ReleaseName: "web", Namespace: "prod", TargetTag: "v1"})
+	if err != nil {
+		t.Fatalf("runValuesScript() error = %v", err)
+	}
+	defer os.Remove(path)
+	data, err := os.ReadFile(path)
+	if want := "release: web\nnamespace: prod\ntag: v1\n"; err != nil || string(data) != want {
+		t.Errorf("values file = %q, %v, want %q", data, err, want)
+	}
+	if opts := d.withValuesFile(path).helmDeployOptions("./chart", &Summary{TargetTag: "v1"}); len(opts.ValuesFiles) != 1 || opts.ValuesFiles[0] != path {
+		t.Errorf("ValuesFiles = %q, want [%s]", opts.ValuesFiles, path)
+	}
+	if opts := d.helmDeployOptions("./chart", &Summary{TargetTag: "v1"}); len(opts.ValuesFiles) != 0 {
+		t.Errorf("ValuesFiles = %q on the original deployer, want none", opts.ValuesFiles)
+	}
+}
+
+func TestRunValuesScriptErrors(t *testing.T) {
+	tests := map[string]string{
+		"echo 'vault sealed' >&2; exit 1": "vault sealed",
+		"echo '- not a mapping'":          "produced invalid YAML",
+	}
+	for body, want := range tests {
+		d := &Deployer{config: &config.Config{ValuesScript: valuesScript(t, body)}}
+		if path, err := d.runValuesScript(&Summary{}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("runValuesScript(%q) = %s, %v, want %q", body, path, err, want)
+		}
+	}
+}
+
+func TestFetchConfigMapValues(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"kubectl": "printf 'replicaCount: 3\\n'"})
+	d := New(&config.Config{Namespace: "prod", ValuesConfigMap: "base-values/values.yaml"}, false)
+	path, err := d.fetchConfigMapValues()
+	if err != nil {
+		t.Fatalf("fetchConfigMapValues() error = %v", err)
+	}
+	defer os.Remove(path)
+	if data, err := os.ReadFile(path); err != nil || string(data) != "replicaCount: 3\n" {
+		t.Errorf("values file = %q, %v", data, err)
+	}
+
+	testutil.FakeTools(t, map[string]string{"kubectl": "echo '- not a mapping'"})
+	if _, err := d.fetchConfigMapValues(); err == nil || !strings.Contains(err.Error(), "is not a values document") {
+		t.Errorf("fetchConfigMapValues(list) error = %v", err)
+	}
+}
+
+func TestWithValuesFileKeepsOrder(t *testing.T) {
+	base := (&Deployer{config: &config.Config{}}).withValuesFile("base.yaml")
+	generated := base.withValuesFile("generated.yaml")
+	if !slices.Equal(generated.valuesFiles, []string{"base.yaml", "generated.yaml"}) {
+		t.Errorf("valuesFiles = %q, want the base file first", generated.valuesFiles)
+	}
+	if !slices.Equal(base.valuesFiles, []string{"base.yaml"}) {
+		t.Errorf("valuesFiles = %q on the earlier copy, want it unchanged", base.valuesFiles)
+	}
+}
@@ -0,0 +1,58 @@
+package deploy
+
+import (
+	"sort"
+
+	"sbi-deployment/internal/helm"
+)
+
+// ValueChange is a value the upgrade would add to or change on the live release
+type ValueChange struct {
+	Key   string
+	Old   string
+	New   string
+	Added bool
+}
+
+// DiffValues compares the values an upgrade sets against the live release's values, sorted by key.
+// Only keys set by the upgrade are compared: values from files are not known here, so
+// live keys missing from next are not reported as removed.
+func DiffValues(live, next map[string]string) []ValueChange {
+	var changes []ValueChange
+	for key, value := range next {
+		old, ok := live[key]
+		if ok && old == value {
+			continue
+		}
+		changes = append(changes, ValueChange{Key: key, Old: old, New: value, Added: !ok})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// dryRunValuesDiff prints how the upgrade's values differ from the live release; the
+// cluster is only read, and a failed read is just a warning
+func (d *Deployer) dryRunValuesDiff(opts helm.DeployOptions) {
+	live, found, err := d.helmClient.ReleaseValues(opts.ReleaseName, opts.Namespace)
+	if err != nil {
+		d.log.Warnf("Could not compare values with the live release: %v", err)
+		return
+	}
+	if !found {
+		d.log.Infof("   ✓ Release %s is not installed yet, all values are new", opts.ReleaseName)
+		return
+	}
+
+	changes := DiffValues(live, opts.SetPairs())
+	if len(changes) == 0 {
+		d.log.Infof("   ✓ No value changes against the live release")
+		return
+	}
+	for _, change := range changes {
+		if change.Added {
+			d.log.Infof("   + %s=%s", change.Key, change.New)
+		} else {
+			d.log.Infof("   ~ %s: %s -> %s", change.Key, change.Old, change.New)
+		}
+	}
+}
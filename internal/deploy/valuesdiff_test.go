@@ -0,0 +1,21 @@
+package deploy
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffValues(t *testing.T) {
+	live := map[string]string{"image.tag": "v1", "replicaCount": "3", "ingress.enabled": "true"}
+	next := map[string]string{"image.tag": "v2", "replicaCount": "3", "image.digest": "sha256:abc"}
+	want := []ValueChange{
+		{Key: "image.digest", New: "sha256:abc", Added: true},
+		{Key: "image.tag", Old: "v1", New: "v2"},
+	}
+	if got := DiffValues(live, next); !slices.Equal(got, want) {
+		t.Errorf("DiffValues() = %+v, want %+v", got, want)
+	}
+	if got := DiffValues(live, map[string]string{"image.tag": "v1"}); len(got) != 0 {
+		t.Errorf("DiffValues(unchanged) = %+v, want no changes", got)
+	}
+}
@@ -1,117 +1,343 @@
 package docker
 
 import (
+	"encoding/json"
 	"fmt"
 	"os/exec"
+	"sort"
 	"strings"
+
+	"sbi-deployment/internal/logging"
+	"sbi-deployment/internal/utils"
 )
 
+// SupportedRuntimes lists the container CLIs that accept docker-compatible subcommands, in detection order
+var SupportedRuntimes = []string{"docker", "podman", "nerdctl"}
+
 // Client represents a Docker client
 type Client struct {
-	verbose bool
-	dryRun  bool
+	runtime  string
+	platform string
+	dryRun   bool
+	log      *logging.Logger
 }
 
-// New creates a new Docker client
-func New(verbose, dryRun bool) *Client {
+// New creates a new Docker client for the given container runtime CLI
+func New(runtime string, dryRun bool) *Client {
+	if runtime == "" {
+		runtime = DetectRuntime()
+	}
 	return &Client{
-		verbose: verbose,
+		runtime: runtime,
 		dryRun:  dryRun,
+		log:     logging.Default(),
+	}
+}
+
+// WithLogger returns a copy of the client that writes its output through log
+func (c *Client) WithLogger(log *logging.Logger) *Client {
+	clone := *c
+	clone.log = log
+	return &clone
+}
+
+// WithPlatform returns a copy of the client that pulls and builds images for platform (os/arch)
+func (c *Client) WithPlatform(platform string) *Client {
+	clone := *c
+	clone.platform = platform
+	return &clone
+}
+
+// DetectRuntime returns the first supported container runtime found in PATH, defaulting to docker
+func DetectRuntime() string {
+	for _, runtime := range SupportedRuntimes {
+		if _, err := exec.LookPath(runtime); err == nil {
+			return runtime
+		}
+	}
+	return "docker"
+}
+
+// Runtime returns the container runtime CLI used by the client
+func (c *Client) Runtime() string {
+	return c.runtime
+}
+
+// LoginArgs builds the docker login arguments; the password is always supplied on stdin
+func LoginArgs(registry, username string) []string {
+	return []string{"login", registry, "-u", username, "--password-stdin"}
+}
+
+// PullArgs builds the docker pull arguments
+func PullArgs(image, platform string) []string {
+	args := []string{"pull"}
+	if platform != "" {
+		args = append(args, "--platform", platform)
 	}
+	return append(args, image)
+}
+
+// TagArgs builds the docker tag arguments
+func TagArgs(sourceImage, targetImage string) []string {
+	return []string{"tag", sourceImage, targetImage}
+}
+
+// BuildArgs builds the arguments that build an image from a local context, with sorted --build-args
+func BuildArgs(image, context, dockerfile, platform string, buildArgs map[string]string) []string {
+	args := []string{"build", "-t", image}
+	if platform != "" {
+		args = append(args, "--platform", platform)
+	}
+	if dockerfile != "" {
+		args = append(args, "-f", dockerfile)
+	}
+	keys := make([]string, 0, len(buildArgs))
+	for key := range buildArgs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "--build-arg", key+"="+buildArgs[key])
+	}
+	return append(args, context)
+}
+
+// Build builds an image from a local context
+func (c *Client) Build(image, context, dockerfile string, buildArgs map[string]string) error {
+	c.log.Debugf("Building image %s from %s", image, context)
+
+	cmd := utils.Command(c.runtime, BuildArgs(image, context, dockerfile, c.platform, buildArgs)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to build image %s: %w: %s", image, err, strings.TrimSpace(string(output)))
+	}
+
+	c.log.Debugf("Successfully built %s", image)
+	return nil
+}
+
+// PushArgs builds the docker push arguments
+func PushArgs(image string) []string {
+	return []string{"push", image}
+}
+
+// RemoveArgs builds the docker rmi arguments
+func RemoveArgs(image string) []string {
+	return []string{"rmi", image}
+}
+
+// VersionArgs builds the arguments of the runtime check
+func VersionArgs() []string {
+	return []string{"version"}
 }
 
 // CheckDocker verifies that Docker is available and running
 func (c *Client) CheckDocker() error {
-	cmd := exec.Command("docker", "version")
+	cmd := utils.Command(c.runtime, VersionArgs()...)
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("docker is not available or not running: %w", err)
+		return fmt.Errorf("%s is not available or not running: %w", c.runtime, err)
 	}
 	return nil
 }
 
 // Login authenticates with a Docker registry
 func (c *Client) Login(registry, username, password string) error {
-	if c.verbose {
-		fmt.Printf("Logging in to registry: %s\n", registry)
-	}
+	c.log.Debugf("Logging in to registry: %s", registry)
 
-	cmd := exec.Command("docker", "login", registry, "-u", username, "--password-stdin")
+	cmd := utils.Command(c.runtime, LoginArgs(registry, username)...)
 	cmd.Stdin = strings.NewReader(password)
-	
+
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to login to registry %s: %w", registry, err)
 	}
-	
-	if c.verbose {
-		fmt.Printf("Successfully logged in to %s\n", registry)
-	}
+
+	c.log.Debugf("Successfully logged in to %s", registry)
 	return nil
 }
 
 // Pull downloads an image from a registry
 func (c *Client) Pull(image string) error {
-	if c.verbose {
-		fmt.Printf("Pulling image: %s\n", image)
-	}
+	c.log.Debugf("Pulling image: %s", image)
 
-	cmd := exec.Command("docker", "pull", image)
+	cmd := utils.Command(c.runtime, PullArgs(image, c.platform)...)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to pull image %s: %w", image, err)
 	}
 
-	if c.verbose {
-		fmt.Printf("Successfully pulled %s\n", image)
-	}
+	c.log.Debugf("Successfully pulled %s", image)
 	return nil
 }
 
 // Tag creates a new tag for an existing image
 func (c *Client) Tag(sourceImage, targetImage string) error {
-	if c.verbose {
-		fmt.Printf("Tagging image: %s -> %s\n", sourceImage, targetImage)
-	}
+	c.log.Debugf("Tagging image: %s -> %s", sourceImage, targetImage)
 
-	cmd := exec.Command("docker", "tag", sourceImage, targetImage)
+	cmd := utils.Command(c.runtime, TagArgs(sourceImage, targetImage)...)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to tag image %s as %s: %w", sourceImage, targetImage, err)
 	}
 
-	if c.verbose {
-		fmt.Printf("Successfully tagged %s as %s\n", sourceImage, targetImage)
-	}
+	c.log.Debugf("Successfully tagged %s as %s", sourceImage, targetImage)
 	return nil
 }
 
 // Push uploads an image to a registry
 func (c *Client) Push(image string) error {
-	if c.verbose {
-		fmt.Printf("Pushing image: %s\n", image)
-	}
+	c.log.Debugf("Pushing image: %s", image)
 
-	cmd := exec.Command("docker", "push", image)
+	cmd := utils.Command(c.runtime, PushArgs(image)...)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to push image %s: %w", image, err)
 	}
 
-	if c.verbose {
-		fmt.Printf("Successfully pushed %s\n", image)
-	}
+	c.log.Debugf("Successfully pushed %s", image)
 	return nil
 }
 
 // Remove deletes an image from local storage
 func (c *Client) Remove(image string) error {
-	if c.verbose {
-		fmt.Printf("Removing image: %s\n", image)
-	}
+	c.log.Debugf("Removing image: %s", image)
 
-	cmd := exec.Command("docker", "rmi", image)
+	cmd := utils.Command(c.runtime, RemoveArgs(image)...)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to remove image %s: %w", image, err)
 	}
 
-	if c.verbose {
-		fmt.Printf("Successfully removed %s\n", image)
+	c.log.Debugf("Successfully removed %s", image)
+	return nil
+}
+
+// ManifestCopyArgs builds the buildx arguments that copy a full manifest list between registries
+func ManifestCopyArgs(sourceImage, targetImage string) []string {
+	return []string{"buildx", "imagetools", "create", "--tag", targetImage, sourceImage}
+}
+
+// CopyManifestList copies an image with all of its architectures directly between registries
+func (c *Client) CopyManifestList(sourceImage, targetImage string) error {
+	if c.runtime != "docker" {
+		return fmt.Errorf("preserving manifest lists requires docker buildx, not %s", c.runtime)
+	}
+
+	c.log.Debugf("Copying manifest list: %s -> %s", sourceImage, targetImage)
+
+	cmd := utils.Command(c.runtime, ManifestCopyArgs(sourceImage, targetImage)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to copy manifest list %s to %s: %w: %s", sourceImage, targetImage, err, strings.TrimSpace(string(output)))
+	}
+
+	c.log.Debugf("Successfully copied %s to %s", sourceImage, targetImage)
+	return nil
+}
+
+// ManifestInspectArgs builds the arguments that fetch an image manifest from its registry
+func ManifestInspectArgs(image string) []string {
+	return []string{"manifest", "inspect", image}
+}
+
+// ManifestExists checks that an image manifest exists in its remote registry
+func (c *Client) ManifestExists(image string) error {
+	cmd := utils.Command(c.runtime, ManifestInspectArgs(image)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("manifest for %s not found: %w: %s", image, err, strings.TrimSpace(string(output)))
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// ImageInfo is the subset of `docker image inspect` output we use
+type ImageInfo struct {
+	ID          string   `json:"Id"`
+	Size        int64    `json:"Size"`
+	RepoDigests []string `json:"RepoDigests"`
+	Config      struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+}
+
+// Label returns the value of an image label such as org.opencontainers.image.version
+func (i *ImageInfo) Label(key string) (string, bool) {
+	value, ok := i.Config.Labels[key]
+	return value, ok
+}
+
+// parseInspect decodes `docker image inspect` output, which is a JSON array with one entry per image
+func parseInspect(output []byte) (*ImageInfo, error) {
+	var infos []ImageInfo
+	if err := json.Unmarshal(output, &infos); err != nil {
+		return nil, fmt.Errorf("failed to parse inspect output: %w", err)
+	}
+	if len(infos) == 0 {
+		return nil, fmt.Errorf("inspect returned no images")
+	}
+	return &infos[0], nil
+}
+
+// InspectArgs builds the arguments that read a local image's metadata
+func InspectArgs(image string) []string {
+	return []string{"image", "inspect", image}
+}
+
+// Inspect returns metadata about a local image
+func (c *Client) Inspect(image string) (*ImageInfo, error) {
+	cmd := utils.Command(c.runtime, InspectArgs(image)...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect image %s: %w", image, err)
+	}
+	return parseInspect(output)
+}
+
+// IsDigest reports whether a tag argument is actually a sha256 content digest
+func IsDigest(tag string) bool {
+	return strings.HasPrefix(tag, "sha256:") && len(tag) == len("sha256:")+64
+}
+
+// ImageRef builds registry/name:tag, or registry/name@digest when tag is a digest
+func ImageRef(registry, name, tag string) string {
+	if IsDigest(tag) {
+		return fmt.Sprintf("%s/%s@%s", registry, name, tag)
+	}
+	return fmt.Sprintf("%s/%s:%s", registry, name, tag)
+}
+
+// DigestTag derives a pushable tag from a digest, since registries only accept pushes by tag
+func DigestTag(digest string) string {
+	return "sha256-" + strings.TrimPrefix(digest, "sha256:")[:12]
+}
+
+// SplitReference splits an image reference into repository and tag, respecting registry ports
+func SplitReference(ref string) (repository, tag string) {
+	if at := strings.Index(ref, "@"); at >= 0 {
+		ref = ref[:at]
+	}
+	slash := strings.LastIndex(ref, "/")
+	if colon := strings.LastIndex(ref, ":"); colon > slash {
+		return ref[:colon], ref[colon+1:]
+	}
+	return ref, ""
+}
+
+// SplitImageArg splits an image argument such as team/app:1.2.3 or team/app@sha256:<digest> into
+// the image name and its tag or digest; registry ports stay in the name, and an untagged
+// argument returns the whole argument and an empty tag. A tag and a digest together, as in
+// team/app:1.2.3@sha256:<digest>, are rejected since only one of them can be deployed.
+func SplitImageArg(arg string) (name, tag string, err error) {
+	if at := strings.Index(arg, "@"); at >= 0 {
+		name, tag = arg[:at], arg[at+1:]
+		if _, nameTag := SplitReference(name); nameTag != "" {
+			return "", "", fmt.Errorf("%q has both a tag and a digest; give one of them", arg)
+		}
+		return name, tag, nil
+	}
+	name, tag = SplitReference(arg)
+	return name, tag, nil
+}
+
+// DigestFor returns the sha256 digest recorded for the given repository, or "" if none
+func (i *ImageInfo) DigestFor(repository string) string {
+	for _, repoDigest := range i.RepoDigests {
+		parts := strings.SplitN(repoDigest, "@", 2)
+		if len(parts) == 2 && parts[0] == repository {
+			return parts[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,234 @@
+package docker
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/testutil"
+)
+
+func TestCopyManifestList(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"docker": "exit 0"})
+	if err := New("docker", false).CopyManifestList("nexus.example.com/web:v1", "harbor.example.com/web:v1"); err != nil {
+		t.Fatalf("CopyManifestList() error = %v", err)
+	}
+	want := []string{"docker buildx imagetools create --tag harbor.example.com/web:v1 nexus.example.com/web:v1"}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestCopyManifestListNeedsDocker(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"podman": "exit 0"})
+	err := New("podman", false).CopyManifestList("nexus.example.com/web:v1", "harbor.example.com/web:v1")
+	if err == nil || !strings.Contains(err.Error(), "requires docker buildx") {
+		t.Errorf("CopyManifestList() error = %v, want a buildx error", err)
+	}
+	if got := testutil.Calls(t, log); len(got) != 0 {
+		t.Errorf("podman was called: %q", got)
+	}
+}
+
+func TestCopyManifestListReportsOutput(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"docker": "echo 'unauthorized: authentication required' >&2; exit 1"})
+	err := New("docker", false).CopyManifestList("nexus.example.com/web:v1", "harbor.example.com/web:v1")
+	if err == nil || !strings.Contains(err.Error(), "unauthorized") {
+		t.Errorf("CopyManifestList() error = %v, want the registry error", err)
+	}
+}
+
+func TestDetectRuntime(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"nerdctl": "exit 0", "podman": "exit 0"})
+	t.Setenv("PATH", strings.Split(os.Getenv("PATH"), string(os.PathListSeparator))[0])
+	if got := DetectRuntime(); got != "podman" {
+		t.Errorf("DetectRuntime() = %q, want podman ahead of nerdctl", got)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if got := DetectRuntime(); got != "docker" {
+		t.Errorf("DetectRuntime() = %q, want the docker default", got)
+	}
+}
+
+func TestRuntimeRunsItsOwnCLI(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"podman": "exit 0"})
+	client := New("podman", false)
+	if err := client.Pull("nexus.example.com/web:v1"); err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	want := []string{"podman pull nexus.example.com/web:v1"}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+// inspectJSON is `docker image inspect` output for a synced image
+const inspectJSON = `[{"Id": "sha256:abc", "Size": 52428800,
+	"RepoDigests": ["nexus.example.com/web@sha256:1111", "harbor.example.com/web@sha256:2222"],
+	"Config": {"Labels": {"org.opencontainers.image.version": "v1"}}}]`
+
+func TestParseInspect(t *testing.T) {
+	info, err := parseInspect([]byte(inspectJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size != 52428800 {
+		t.Errorf("Size = %d, want 52428800", info.Size)
+	}
+	for _, bad := range []string{"[]", "{"} {
+		if _, err := parseInspect([]byte(bad)); err == nil {
+			t.Errorf("parseInspect(%q) returned no error", bad)
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"docker": "echo '" + inspectJSON + "'"})
+	info, err := New("docker", false).Inspect("nexus.example.com/web:v1")
+	if err != nil || info.Size != 52428800 {
+		t.Fatalf("Inspect() = %+v, %v", info, err)
+	}
+	want := []string{"docker image inspect nexus.example.com/web:v1"}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestSplitReference(t *testing.T) {
+	tests := []struct {
+		ref, repository, tag string
+	}{
+		{"harbor.example.com/team/web:v1", "harbor.example.com/team/web", "v1"},
+		{"harbor.example.com:5000/team/web:v1", "harbor.example.com:5000/team/web", "v1"},
+		{"harbor.example.com:5000/team/web", "harbor.example.com:5000/team/web", ""},
+		{"harbor.example.com/web:v1@sha256:1111", "harbor.example.com/web", "v1"},
+	}
+	for _, tt := range tests {
+		repository, tag := SplitReference(tt.ref)
+		if repository != tt.repository || tag != tt.tag {
+			t.Errorf("SplitReference(%q) = %q, %q; want %q, %q", tt.ref, repository, tag, tt.repository, tt.tag)
+		}
+	}
+}
+
+func TestDigestFor(t *testing.T) {
+	info, err := parseInspect([]byte(inspectJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.DigestFor("harbor.example.com/web"); got != "sha256:2222" {
+		t.Errorf("DigestFor(harbor) = %q, want sha256:2222", got)
+	}
+	if got := info.DigestFor("harbor.example.com/other"); got != "" {
+		t.Errorf("DigestFor(other) = %q, want none", got)
+	}
+}
+
+func TestManifestExists(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"docker": "exit 0"})
+	if err := New("docker", false).ManifestExists("harbor.example.com/web:v1"); err != nil {
+		t.Fatalf("ManifestExists() error = %v", err)
+	}
+	if got, want := testutil.Calls(t, log), []string{"docker manifest inspect harbor.example.com/web:v1"}; !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+
+	testutil.FakeTools(t, map[string]string{"docker": "echo 'no such manifest'; exit 1"})
+	err := New("docker", false).ManifestExists("harbor.example.com/web:v2")
+	if err == nil || !strings.Contains(err.Error(), "no such manifest") {
+		t.Errorf("ManifestExists() error = %v, want the registry output", err)
+	}
+}
+
+func TestImageRef(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	if !IsDigest(digest) || IsDigest("v1.2.0") || IsDigest("sha256:abcd") {
+		t.Errorf("IsDigest() misclassifies digests and tags")
+	}
+	if got := ImageRef("nexus.example.com", "web", "v1.2.0"); got != "nexus.example.com/web:v1.2.0" {
+		t.Errorf("ImageRef() = %q for a tag", got)
+	}
+	if got := ImageRef("nexus.example.com", "web", digest); got != "nexus.example.com/web@"+digest {
+		t.Errorf("ImageRef() = %q for a digest", got)
+	}
+	if got := DigestTag(digest); got != "sha256-abababababab" {
+		t.Errorf("DigestTag() = %q, want sha256- and the first 12 hex digits", got)
+	}
+}
+
+func TestBuildArgs(t *testing.T) {
+	got := BuildArgs("harbor.example.com/web:v1", ".", "", "", map[string]string{"VERSION": "1.2.0", "COMMIT": "abc1234"})
+	want := []string{"build", "-t", "harbor.example.com/web:v1", "--build-arg", "COMMIT=abc1234", "--build-arg", "VERSION=1.2.0", "."}
+	if !slices.Equal(got, want) {
+		t.Errorf("BuildArgs() = %q, want %q with sorted build args", got, want)
+	}
+}
+
+func TestImageInfoLabel(t *testing.T) {
+	info, err := parseInspect([]byte(inspectJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := info.Label("org.opencontainers.image.version"); !ok || value != "v1" {
+		t.Errorf("Label(version) = %q, %v, want v1", value, ok)
+	}
+	if _, ok := info.Label("org.opencontainers.image.revision"); ok {
+		t.Errorf("Label(revision) found on an image without it")
+	}
+	if _, ok := (&ImageInfo{}).Label("org.opencontainers.image.version"); ok {
+		t.Errorf("Label() found on an image without labels")
+	}
+}
+
+func TestPlatformArgs(t *testing.T) {
+	if got, want := PullArgs("nexus.example.com/web:v1", ""), []string{"pull", "nexus.example.com/web:v1"}; !slices.Equal(got, want) {
+		t.Errorf("PullArgs() = %q, want %q", got, want)
+	}
+	if got, want := PullArgs("nexus.example.com/web:v1", "linux/amd64"), []string{"pull", "--platform", "linux/amd64", "nexus.example.com/web:v1"}; !slices.Equal(got, want) {
+		t.Errorf("PullArgs(linux/amd64) = %q, want %q", got, want)
+	}
+	got := BuildArgs("harbor.example.com/web:v1", ".", "Dockerfile.prod", "linux/arm64", nil)
+	want := []string{"build", "-t", "harbor.example.com/web:v1", "--platform", "linux/arm64", "-f", "Dockerfile.prod", "."}
+	if !slices.Equal(got, want) {
+		t.Errorf("BuildArgs(linux/arm64) = %q, want %q", got, want)
+	}
+}
+
+func TestWithPlatform(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"docker": ""})
+	client := New("docker", false)
+	if err := client.WithPlatform("linux/amd64").Pull("nexus.example.com/web:v1"); err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	if err := client.Pull("nexus.example.com/web:v2"); err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	want := []string{"docker pull --platform linux/amd64 nexus.example.com/web:v1", "docker pull nexus.example.com/web:v2"}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestSplitImageArg(t *testing.T) {
+	tests := []struct{ arg, name, tag string }{
+		{"team/app:1.2.3", "team/app", "1.2.3"},
+		{"team/app@sha256:abc", "team/app", "sha256:abc"},
+		{"nexus.example.com:8443/team/app", "nexus.example.com:8443/team/app", ""},
+		{"nexus.example.com:8443/team/app:v1", "nexus.example.com:8443/team/app", "v1"},
+		{"app", "app", ""},
+		{"app:", "app", ""},
+	}
+	for _, tt := range tests {
+		if name, tag, err := SplitImageArg(tt.arg); err != nil || name != tt.name || tag != tt.tag {
+			t.Errorf("SplitImageArg(%q) = %q, %q, %v, want %q, %q", tt.arg, name, tag, err, tt.name, tt.tag)
+		}
+	}
+
+	for _, arg := range []string{"app:1.2@sha256:abc", "nexus.example.com:8443/team/app:v1@sha256:abc"} {
+		if name, tag, err := SplitImageArg(arg); err == nil {
+			t.Errorf("SplitImageArg(%q) = %q, %q, want an error for a tag with a digest", arg, name, tag)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package docker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// dockerConfig is the subset of a .dockerconfigjson document holding registry logins
+type dockerConfig struct {
+	Auths map[string]struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Auth     string `json:"auth"`
+	} `json:"auths"`
+}
+
+// registryHost strips the scheme and path from a dockerconfigjson auths key
+func registryHost(key string) string {
+	key = strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+	if i := strings.Index(key, "/"); i >= 0 {
+		key = key[:i]
+	}
+	return key
+}
+
+// ParseDockerConfig returns the username and password stored for registry in a
+// .dockerconfigjson document, preferring explicit fields over the base64 auth entry
+func ParseDockerConfig(data []byte, registry string) (string, string, error) {
+	var cfg dockerConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return "", "", fmt.Errorf("failed to parse dockerconfigjson: %w", err)
+	}
+
+	for key, entry := range cfg.Auths {
+		if registryHost(key) != registryHost(registry) {
+			continue
+		}
+		if entry.Username != "" && entry.Password != "" {
+			return entry.Username, entry.Password, nil
+		}
+		decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid auth entry for %s: %w", registry, err)
+		}
+		username, password, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			return "", "", fmt.Errorf("auth entry for %s is not username:password", registry)
+		}
+		return username, password, nil
+	}
+	return "", "", fmt.Errorf("no credentials for %s in dockerconfigjson", registry)
+}
+
+// DockerConfigJSON renders a .dockerconfigjson document holding one registry login
+func DockerConfigJSON(registry, username, password string) ([]byte, error) {
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	data, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			registryHost(registry): map[string]string{"username": username, "password": password, "auth": auth},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render dockerconfigjson: %w", err)
+	}
+	return data, nil
+}
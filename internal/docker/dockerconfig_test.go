@@ -0,0 +1,50 @@
+package docker
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestParseDockerConfig(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("robot$ci:s3cr:et"))
+	data := []byte(`{"auths": {
+		"https://harbor.example.com/v2/": {"auth": "` + auth + `"},
+		"nexus.example.com": {"username": "reader", "password": "pw", "auth": "ignored"}
+	}}`)
+
+	tests := []struct {
+		registry, username, password string
+	}{
+		{"harbor.example.com", "robot$ci", "s3cr:et"},
+		{"nexus.example.com/v2", "reader", "pw"},
+	}
+	for _, tt := range tests {
+		username, password, err := ParseDockerConfig(data, tt.registry)
+		if err != nil || username != tt.username || password != tt.password {
+			t.Errorf("ParseDockerConfig(%s) = %q, %q, %v; want %q, %q", tt.registry, username, password, err, tt.username, tt.password)
+		}
+	}
+
+	if _, _, err := ParseDockerConfig(data, "quay.io"); err == nil || !strings.Contains(err.Error(), "no credentials for quay.io") {
+		t.Errorf("ParseDockerConfig(quay.io) error = %v", err)
+	}
+	bad := []byte(`{"auths": {"harbor.example.com": {"auth": "` + base64.StdEncoding.EncodeToString([]byte("token")) + `"}}}`)
+	if _, _, err := ParseDockerConfig(bad, "harbor.example.com"); err == nil {
+		t.Errorf("ParseDockerConfig() accepted an auth entry without a password")
+	}
+}
+
+func TestDockerConfigJSON(t *testing.T) {
+	data, err := DockerConfigJSON("https://harbor.example.com", "robot$ci", "s3cr:et")
+	if err != nil {
+		t.Fatal(err)
+	}
+	username, password, err := ParseDockerConfig(data, "harbor.example.com")
+	if err != nil || username != "robot$ci" || password != "s3cr:et" {
+		t.Errorf("ParseDockerConfig(DockerConfigJSON()) = %q, %q, %v", username, password, err)
+	}
+	if !strings.Contains(string(data), `"harbor.example.com"`) {
+		t.Errorf("DockerConfigJSON() = %s, want the registry host as the auths key", data)
+	}
+}
@@ -0,0 +1,38 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// annotatedKinds are the workload kinds a release's CI annotations are recorded on
+const annotatedKinds = "deployments,statefulsets,daemonsets"
+
+// AnnotateArgs builds the kubectl arguments that set annotations on every workload labelled with the release
+func AnnotateArgs(releaseName, namespace string, annotations map[string]string) []string {
+	args := []string{"annotate", annotatedKinds, "-n", namespace, "-l", ReleaseSelector(releaseName), "--overwrite"}
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, key+"="+annotations[key])
+	}
+	return args
+}
+
+// AnnotateRelease sets annotations on the release's workloads; helm leaves annotations its
+// chart does not manage in place on later upgrades. It reports whether any workload matched.
+func (c *Client) AnnotateRelease(releaseName, namespace string, annotations map[string]string) (bool, error) {
+	cmd := c.command(c.kubeCLI, AnnotateArgs(releaseName, namespace, annotations)...)
+	output, err := c.combinedOutput(cmd)
+	if err != nil {
+		return false, fmt.Errorf("failed to annotate workloads of %s: %w: %s", releaseName, err, strings.TrimSpace(string(output)))
+	}
+	if strings.TrimSpace(string(output)) == "" || strings.Contains(string(output), "No resources found") {
+		return false, nil
+	}
+	return true, nil
+}
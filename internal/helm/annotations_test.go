@@ -0,0 +1,49 @@
+package helm
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/testutil"
+)
+
+func TestAnnotateArgs(t *testing.T) {
+	got := AnnotateArgs("web", "prod", map[string]string{
+		"sbi-deployment/github-run-id": "42",
+		"sbi-deployment/ci-run-url":    "https://ci.example.com/run/42",
+	})
+	want := []string{
+		"annotate", "deployments,statefulsets,daemonsets", "-n", "prod",
+		"-l", "app.kubernetes.io/instance=web", "--overwrite",
+		"sbi-deployment/ci-run-url=https://ci.example.com/run/42",
+		"sbi-deployment/github-run-id=42",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("AnnotateArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestAnnotateRelease(t *testing.T) {
+	annotations := map[string]string{"sbi-deployment/github-run-id": "42"}
+	log := testutil.FakeTools(t, map[string]string{"kubectl": "echo 'deployment.apps/web annotated'"})
+	if found, err := New("", false).AnnotateRelease("web", "prod", annotations); err != nil || !found {
+		t.Errorf("AnnotateRelease() = %v, %v, want true", found, err)
+	}
+	want := []string{"kubectl " + strings.Join(AnnotateArgs("web", "prod", annotations), " ")}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+
+	for _, body := range []string{"exit 0", "echo 'No resources found in prod namespace.'"} {
+		testutil.FakeTools(t, map[string]string{"kubectl": body})
+		if found, err := New("", false).AnnotateRelease("web", "prod", annotations); err != nil || found {
+			t.Errorf("AnnotateRelease(%q) = %v, %v, want no matching workload without an error", body, found, err)
+		}
+	}
+
+	testutil.FakeTools(t, map[string]string{"kubectl": "echo 'Error from server (Forbidden)'; exit 1"})
+	if _, err := New("", false).AnnotateRelease("web", "prod", annotations); err == nil || !strings.Contains(err.Error(), "Forbidden") {
+		t.Errorf("AnnotateRelease() error = %v, want the kubectl output", err)
+	}
+}
@@ -1,119 +1,642 @@
 package helm
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"sbi-deployment/internal/logging"
+	"sbi-deployment/internal/utils"
 )
 
+// ErrOperationInProgress is returned when a previous helm operation left the release in a pending state
+var ErrOperationInProgress = errors.New("another helm operation (install/upgrade/rollback) is in progress")
+
+// ErrRollbackTimeout is returned when a bounded rollback is still running at its deadline
+var ErrRollbackTimeout = errors.New("helm rollback did not finish in time")
+
+// isOperationInProgress reports whether helm output indicates a pending install, upgrade, or rollback
+func isOperationInProgress(output string) bool {
+	return strings.Contains(output, "another operation (install/upgrade/rollback) is in progress")
+}
+
 // Client represents a Helm client
 type Client struct {
-	verbose bool
+	kubeCLI string
 	dryRun  bool
+	log     *logging.Logger
+
+	rolloutSuccessText string
+	kubeconfig         string
+	skipTLSVerify      bool
+	debug              bool
 }
 
-// New creates a new Helm client
-func New(verbose, dryRun bool) *Client {
+// New creates a new Helm client; kubeCLI selects kubectl or oc for cluster operations
+func New(kubeCLI string, dryRun bool) *Client {
+	if kubeCLI == "" {
+		kubeCLI = "kubectl"
+	}
 	return &Client{
-		verbose: verbose,
+		kubeCLI: kubeCLI,
 		dryRun:  dryRun,
+		log:     logging.Default(),
+
+		rolloutSuccessText: DefaultRolloutSuccessText,
 	}
 }
 
+// WithRolloutSuccessText returns a copy of the client that expects text in successful rollout output
+func (c *Client) WithRolloutSuccessText(text string) *Client {
+	clone := *c
+	clone.rolloutSuccessText = text
+	return &clone
+}
+
+// WithKubeconfig returns a copy of the client whose helm and kubectl calls target the cluster in path
+func (c *Client) WithKubeconfig(path string) *Client {
+	clone := *c
+	clone.kubeconfig = path
+	return &clone
+}
+
+// WithSkipTLSVerify returns a copy of the client that does not verify the cluster's TLS certificate
+func (c *Client) WithSkipTLSVerify(skip bool) *Client {
+	clone := *c
+	clone.skipTLSVerify = skip
+	return &clone
+}
+
+// WithDebug returns a copy of the client that runs helm with --debug and logs its output at debug level
+func (c *Client) WithDebug(debug bool) *Client {
+	clone := *c
+	clone.debug = debug
+	return &clone
+}
+
+// InsecureArgs returns the flag that disables cluster TLS verification for helm or kubectl/oc
+func InsecureArgs(tool string) []string {
+	if tool == "helm" {
+		return []string{"--kube-insecure-skip-tls-verify"}
+	}
+	return []string{"--insecure-skip-tls-verify"}
+}
+
+// command builds a helm or kubectl invocation against the client's cluster
+func (c *Client) command(name string, args ...string) *exec.Cmd {
+	return c.commandContext(context.Background(), name, args...)
+}
+
+// commandContext is command with a context that kills the process when it is done
+func (c *Client) commandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	if c.skipTLSVerify {
+		args = append(args, InsecureArgs(name)...)
+	}
+	if c.debug && name == "helm" {
+		args = append(args, "--debug")
+	}
+	cmd := utils.CommandContext(ctx, name, args...)
+	if c.kubeconfig != "" {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, "KUBECONFIG="+c.kubeconfig)
+	}
+	return cmd
+}
+
+// combinedOutput runs cmd and returns its combined output, logging it at debug level under HELM_DEBUG
+func (c *Client) combinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	output, err := cmd.CombinedOutput()
+	if c.debug {
+		for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+			c.log.Debugf("   %s", line)
+		}
+	}
+	return output, err
+}
+
+// WithLogger returns a copy of the client that writes its output through log
+func (c *Client) WithLogger(log *logging.Logger) *Client {
+	clone := *c
+	clone.log = log
+	return &clone
+}
+
+// KubeCLI returns the Kubernetes CLI used for cluster operations
+func (c *Client) KubeCLI() string {
+	return c.kubeCLI
+}
+
+// Kubeconfig returns the kubeconfig the client's calls target, or "" for the environment's
+func (c *Client) Kubeconfig() string {
+	return c.kubeconfig
+}
+
+// VersionArgs builds the helm arguments of the helm check
+func VersionArgs() []string {
+	return []string{"version"}
+}
+
+// KubeVersionArgs builds the kubectl/oc arguments of the Kubernetes CLI check
+func KubeVersionArgs() []string {
+	return []string{"version", "--client"}
+}
+
 // CheckHelm verifies that Helm is available
 func (c *Client) CheckHelm() error {
-	cmd := exec.Command("helm", "version")
+	cmd := c.command("helm", VersionArgs()...)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("helm is not available: %w", err)
 	}
 	return nil
 }
 
-// CheckKubectl verifies that kubectl is available
+// CheckKubectl verifies that the configured Kubernetes CLI is available
 func (c *Client) CheckKubectl() error {
-	cmd := exec.Command("kubectl", "version", "--client")
+	cmd := c.command(c.kubeCLI, KubeVersionArgs()...)
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("kubectl is not available: %w", err)
+		return fmt.Errorf("%s is not available: %w", c.kubeCLI, err)
 	}
 	return nil
 }
 
 // CheckChartPath verifies that the Helm chart path exists
 func (c *Client) CheckChartPath(chartPath string) error {
+	if IsRemoteChart(chartPath) {
+		return nil
+	}
 	if _, err := os.Stat(chartPath); os.IsNotExist(err) {
-		return fmt.Errorf("helm chart path does not exist: %s", chartPath)
+		if strings.TrimSpace(chartPath) != chartPath {
+			return fmt.Errorf("helm chart path not found (check for stray whitespace: %q)", chartPath)
+		}
+		return fmt.Errorf("helm chart path does not exist: %q", chartPath)
 	}
 	return nil
 }
 
-// Deploy deploys an application using Helm
-func (c *Client) Deploy(chartPath, releaseName, namespace, imageTag string, timeout int) error {
-	if c.verbose {
-		fmt.Printf("Deploying with Helm: chart=%s, release=%s, namespace=%s, tag=%s\n", 
-			chartPath, releaseName, namespace, imageTag)
+// DeployOptions describes a single helm upgrade --install invocation
+type DeployOptions struct {
+	ChartPath       string
+	ReleaseName     string
+	Namespace       string
+	ImageTag        string
+	ImageTagKey     string
+	ImageDigest     string
+	ImageDigestKey  string
+	Timeout         int
+	ChartVersion    string
+	SetValues       map[string]string
+	CreateNamespace bool
+
+	// Registry/repository path (no tag) set alongside the tag, e.g. image.repository
+	ImageRepository    string
+	ImageRepositoryKey string
+
+	// Values files passed with --values; --set arguments still take precedence
+	ValuesFiles []string
+
+	// Submit the upgrade without --wait/--atomic and return before the rollout finishes
+	NoWait bool
+
+	// Labels stored on the release itself (helm upgrade --labels), e.g. its owner
+	Labels map[string]string
+	// imagePullSecret set as imagePullSecrets[0].name
+	PullSecret string
+}
+
+// IsRemoteChart reports whether chartPath refers to a repo or OCI chart rather than a local directory
+func IsRemoteChart(chartPath string) bool {
+	if strings.HasPrefix(chartPath, "oci://") || strings.HasPrefix(chartPath, "http://") || strings.HasPrefix(chartPath, "https://") {
+		return true
 	}
+	if strings.HasPrefix(chartPath, "/") || strings.HasPrefix(chartPath, ".") {
+		return false
+	}
+	// A bare "repo/chart" reference only counts as remote if nothing exists at that path locally
+	_, err := os.Stat(chartPath)
+	return os.IsNotExist(err) && strings.Count(chartPath, "/") == 1
+}
 
+// DeployArgs builds the helm upgrade arguments for the given options
+func DeployArgs(opts DeployOptions) []string {
 	args := []string{
 		"upgrade", "--install",
-		releaseName,
-		chartPath,
-		"--namespace", namespace,
-		"--set", fmt.Sprintf("image.tag=%s", imageTag),
-		"--wait",
-		"--timeout", fmt.Sprintf("%ds", timeout),
-		"--atomic",
+		opts.ReleaseName,
+		opts.ChartPath,
+		"--namespace", opts.Namespace,
+		"--set", fmt.Sprintf("%s=%s", opts.tagKey(), opts.ImageTag),
+	}
+	if !opts.NoWait {
+		args = append(args, "--wait", "--timeout", fmt.Sprintf("%ds", opts.Timeout), "--atomic")
 	}
 
-	cmd := exec.Command("helm", args...)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("helm deployment failed: %w", err)
+	if opts.CreateNamespace {
+		args = append(args, "--create-namespace")
+	}
+	if len(opts.Labels) > 0 {
+		keys := make([]string, 0, len(opts.Labels))
+		for key := range opts.Labels {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		labels := make([]string, 0, len(keys))
+		for _, key := range keys {
+			labels = append(labels, key+"="+opts.Labels[key])
+		}
+		args = append(args, "--labels", strings.Join(labels, ","))
 	}
 
-	if c.verbose {
-		fmt.Printf("Successfully deployed %s\n", releaseName)
+	return append(args, valueArgs(opts)...)
+}
+
+// TemplateArgs builds the helm template arguments that render the manifest DeployArgs would apply
+func TemplateArgs(opts DeployOptions) []string {
+	args := []string{
+		"template",
+		opts.ReleaseName,
+		opts.ChartPath,
+		"--namespace", opts.Namespace,
+		"--set", fmt.Sprintf("%s=%s", opts.tagKey(), opts.ImageTag),
 	}
-	return nil
+	return append(args, valueArgs(opts)...)
 }
 
-// Rollback performs a Helm rollback
-func (c *Client) Rollback(releaseName string) error {
-	if c.verbose {
-		fmt.Printf("Rolling back release: %s\n", releaseName)
+// tagKey returns the values path that receives the image tag
+func (opts DeployOptions) tagKey() string {
+	if opts.ImageTagKey == "" {
+		return "image.tag"
+	}
+	return opts.ImageTagKey
+}
+
+// SetPairs returns every value the upgrade sets on the command line, keyed by --set path
+func (opts DeployOptions) SetPairs() map[string]string {
+	pairs := map[string]string{opts.tagKey(): opts.ImageTag}
+	if opts.ImageRepository != "" {
+		pairs[opts.ImageRepositoryKey] = opts.ImageRepository
+	}
+	if opts.ImageDigest != "" {
+		digestKey := opts.ImageDigestKey
+		if digestKey == "" {
+			digestKey = "image.digest"
+		}
+		pairs[digestKey] = opts.ImageDigest
+	}
+	if opts.PullSecret != "" {
+		pairs[PullSecretValueKey] = opts.PullSecret
+	}
+	for key, value := range opts.SetValues {
+		pairs[key] = value
+	}
+	return pairs
+}
+
+// valueArgs builds the --values, digest, --set, and --version arguments shared by upgrade and template
+func valueArgs(opts DeployOptions) []string {
+	var args []string
+	for _, file := range opts.ValuesFiles {
+		args = append(args, "--values", file)
+	}
+	if opts.ImageRepository != "" {
+		args = append(args, "--set", fmt.Sprintf("%s=%s", opts.ImageRepositoryKey, opts.ImageRepository))
+	}
+	if opts.ImageDigest != "" {
+		digestKey := opts.ImageDigestKey
+		if digestKey == "" {
+			digestKey = "image.digest"
+		}
+		args = append(args, "--set", fmt.Sprintf("%s=%s", digestKey, opts.ImageDigest))
+	}
+	if opts.PullSecret != "" {
+		args = append(args, "--set", fmt.Sprintf("%s=%s", PullSecretValueKey, opts.PullSecret))
+	}
+
+	// Sort keys so the generated command is deterministic
+	keys := make([]string, 0, len(opts.SetValues))
+	for key := range opts.SetValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "--set", fmt.Sprintf("%s=%s", key, opts.SetValues[key]))
+	}
+
+	// Version pinning only applies to charts pulled from a repository
+	if opts.ChartVersion != "" && IsRemoteChart(opts.ChartPath) {
+		args = append(args, "--version", opts.ChartVersion)
+	}
+	return args
+}
+
+// Deploy deploys an application using Helm and returns the chart's rendered NOTES, if any
+func (c *Client) Deploy(opts DeployOptions) (string, error) {
+	c.log.Debugf("Deploying with Helm: chart=%s, release=%s, namespace=%s, tag=%s",
+		opts.ChartPath, opts.ReleaseName, opts.Namespace, opts.ImageTag)
+
+	cmd := c.command("helm", DeployArgs(opts)...)
+	output, err := c.combinedOutput(cmd)
+	if err != nil {
+		if isOperationInProgress(string(output)) {
+			return "", fmt.Errorf("release %s: %w", opts.ReleaseName, ErrOperationInProgress)
+		}
+		return "", fmt.Errorf("helm deployment failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	c.log.Debugf("Successfully deployed %s", opts.ReleaseName)
+	return ExtractNotes(string(output)), nil
+}
+
+// ExtractNotes returns the NOTES section that helm prints at the end of install/upgrade output
+func ExtractNotes(output string) string {
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "NOTES:" {
+			return strings.TrimSpace(strings.Join(lines[i+1:], "\n"))
+		}
+	}
+	return ""
+}
+
+// PullChartArgs builds the helm pull arguments that download and unpack a chart into destDir
+func PullChartArgs(chartRef, version, destDir string) []string {
+	args := []string{"pull", chartRef, "--untar", "--destination", destDir}
+	if version != "" {
+		args = append(args, "--version", version)
+	}
+	return args
+}
+
+// PullChart downloads a repository or OCI chart and returns the path of the unpacked chart directory
+func (c *Client) PullChart(chartRef, version, destDir string) (string, error) {
+	c.log.Debugf("Pulling chart: %s", chartRef)
+
+	cmd := c.command("helm", PullChartArgs(chartRef, version, destDir)...)
+	if output, err := c.combinedOutput(cmd); err != nil {
+		return "", fmt.Errorf("failed to pull chart %s: %w: %s", chartRef, err, strings.TrimSpace(string(output)))
+	}
+
+	entries, err := os.ReadDir(destDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read pulled chart: %w", err)
 	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			return filepath.Join(destDir, entry.Name()), nil
+		}
+	}
+	return "", fmt.Errorf("pulled chart %s did not unpack a chart directory", chartRef)
+}
+
+// RollbackArgs builds the helm rollback arguments; revision 0 rolls back to the previous revision
+func RollbackArgs(releaseName, namespace string, revision int) []string {
+	args := []string{"rollback", releaseName}
+	if revision > 0 {
+		args = append(args, strconv.Itoa(revision))
+	}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+	return args
+}
+
+// Rollback performs a Helm rollback
+func (c *Client) Rollback(releaseName, namespace string, revision int) error {
+	return c.RollbackWithin(releaseName, namespace, revision, 0)
+}
+
+// RollbackWithin performs a Helm rollback, killing it with ErrRollbackTimeout if it is still
+// running after timeout; a zero timeout waits for helm however long it takes
+func (c *Client) RollbackWithin(releaseName, namespace string, revision int, timeout time.Duration) error {
+	c.log.Debugf("Rolling back release: %s", releaseName)
 
-	cmd := exec.Command("helm", "rollback", releaseName)
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	cmd := c.commandContext(ctx, "helm", RollbackArgs(releaseName, namespace, revision)...)
+	cmd.WaitDelay = time.Second
 	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w: %s still rolling back after %s", ErrRollbackTimeout, releaseName, timeout)
+		}
 		return fmt.Errorf("helm rollback failed: %w", err)
 	}
 
-	if c.verbose {
-		fmt.Printf("Successfully rolled back %s\n", releaseName)
+	c.log.Debugf("Successfully rolled back %s", releaseName)
+	return nil
+}
+
+// TestArgs builds the helm test arguments; --logs makes helm print test pod logs
+func TestArgs(releaseName, namespace string) []string {
+	return []string{"test", releaseName, "--namespace", namespace, "--logs"}
+}
+
+// Test runs the chart's helm test hooks and prints the test pod logs on failure
+func (c *Client) Test(releaseName, namespace string) error {
+	c.log.Debugf("Running helm tests for %s", releaseName)
+
+	cmd := c.command("helm", TestArgs(releaseName, namespace)...)
+	output, err := c.combinedOutput(cmd)
+	if err != nil {
+		c.log.Infof("Helm test output for %s:\n%s", releaseName, strings.TrimSpace(string(output)))
+		return fmt.Errorf("helm test failed for %s: %w", releaseName, err)
 	}
+
+	c.log.Debugf("Helm tests passed for %s", releaseName)
 	return nil
 }
 
+// releaseStatus is the subset of `helm status -o json` output we inspect
+type releaseStatus struct {
+	Version int `json:"version"`
+	Info    struct {
+		Status string `json:"status"`
+	} `json:"info"`
+}
+
+// CurrentRevision returns the current revision number of a release
+func (c *Client) CurrentRevision(releaseName, namespace string) (int, error) {
+	cmd := c.command("helm", "status", releaseName, "--namespace", namespace, "-o", "json")
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get status of release %s: %w", releaseName, err)
+	}
+
+	var status releaseStatus
+	if err := json.Unmarshal(output, &status); err != nil {
+		return 0, fmt.Errorf("failed to parse status of release %s: %w", releaseName, err)
+	}
+	return status.Version, nil
+}
+
+// releaseHistory is the subset of `helm history -o json` output we inspect
+type releaseHistory []struct {
+	Revision int    `json:"revision"`
+	Status   string `json:"status"`
+}
+
+// lastDeployed returns the newest revision that reached the deployed or superseded state
+func (h releaseHistory) lastDeployed() int {
+	last := 0
+	for _, entry := range h {
+		if (entry.Status == "deployed" || entry.Status == "superseded") && entry.Revision > last {
+			last = entry.Revision
+		}
+	}
+	return last
+}
+
+// LastDeployedRevision returns the most recent successfully deployed revision of a release
+func (c *Client) LastDeployedRevision(releaseName, namespace string) (int, error) {
+	cmd := c.command("helm", "history", releaseName, "--namespace", namespace, "-o", "json")
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get history of release %s: %w", releaseName, err)
+	}
+
+	var history releaseHistory
+	if err := json.Unmarshal(output, &history); err != nil {
+		return 0, fmt.Errorf("failed to parse history of release %s: %w", releaseName, err)
+	}
+
+	revision := history.lastDeployed()
+	if revision == 0 {
+		return 0, fmt.Errorf("release %s has no deployed revision", releaseName)
+	}
+	return revision, nil
+}
+
+// RolloutStatusArgs builds the kubectl rollout status arguments
+func RolloutStatusArgs(releaseName, namespace string) []string {
+	return []string{"rollout", "status", fmt.Sprintf("deployment/%s", releaseName), "-n", namespace}
+}
+
+// RolloutRestartArgs builds the kubectl rollout restart arguments for the release's deployment
+func RolloutRestartArgs(releaseName, namespace string) []string {
+	return []string{"rollout", "restart", fmt.Sprintf("deployment/%s", releaseName), "-n", namespace}
+}
+
+// RolloutRestart restarts the release's pods, e.g. to pick up changed config the chart does not
+// checksum, and waits for the restarted rollout to complete
+func (c *Client) RolloutRestart(releaseName, namespace string) error {
+	c.log.Infof("Restarting deployment %s...", releaseName)
+
+	cmd := c.command(c.kubeCLI, RolloutRestartArgs(releaseName, namespace)...)
+	if output, err := c.combinedOutput(cmd); err != nil {
+		return fmt.Errorf("rollout restart of %s failed: %w: %s", releaseName, err, strings.TrimSpace(string(output)))
+	}
+	return c.CheckRolloutStatus(releaseName, namespace)
+}
+
+// ErrRolloutNotFound is returned when the deployment still does not exist after the grace period
+var ErrRolloutNotFound = errors.New("deployment not found")
+
+// rolloutNotFoundGrace is how long a missing deployment is tolerated right after helm upgrade
+const (
+	rolloutNotFoundGrace    = 15 * time.Second
+	rolloutNotFoundInterval = 2 * time.Second
+)
+
+// isNotFound reports whether kubectl output says the deployment does not exist (yet)
+func isNotFound(output string) bool {
+	return strings.Contains(output, "NotFound") || strings.Contains(output, "not found")
+}
+
 // CheckRolloutStatus verifies the deployment status in Kubernetes
 func (c *Client) CheckRolloutStatus(releaseName, namespace string) error {
-	if c.verbose {
-		fmt.Printf("Checking rollout status for %s in namespace %s\n", releaseName, namespace)
+	c.log.Debugf("Checking rollout status for %s in namespace %s", releaseName, namespace)
+
+	run := func() ([]byte, error) {
+		cmd := c.command(c.kubeCLI, RolloutStatusArgs(releaseName, namespace)...)
+		return runStreaming(cmd, func(line string) {
+			c.log.Infof("   %s", line)
+		})
+	}
+	output, err := waitForRollout(run, rolloutNotFoundGrace, rolloutNotFoundInterval, c.log)
+	if err != nil {
+		return fmt.Errorf("rollout status check failed for %s: %w", releaseName, err)
 	}
 
-	cmd := exec.Command("kubectl", "rollout", "status", 
-		fmt.Sprintf("deployment/%s", releaseName), 
-		"-n", namespace)
-	
-	output, err := cmd.CombinedOutput()
+	// The exit code decides; the success text only catches surprising output, e.g. other locales
+	if !matchesSuccessText(string(output), c.rolloutSuccessText) {
+		c.log.Warnf("rollout of %s exited successfully but its output does not contain %q", releaseName, c.rolloutSuccessText)
+	}
+
+	c.log.Debugf("Rollout status check passed for %s", releaseName)
+	return nil
+}
+
+// DefaultRolloutSuccessText is the text kubectl prints when a rollout completes
+const DefaultRolloutSuccessText = "successfully rolled out"
+
+// matchesSuccessText reports whether rollout output contains the success text; an empty text always matches
+func matchesSuccessText(output, successText string) bool {
+	return successText == "" || strings.Contains(output, successText)
+}
+
+// runStreaming runs cmd, passing each stdout line to onLine as it arrives so long-running
+// commands report progress. It returns stdout followed by stderr, like CombinedOutput.
+func runStreaming(cmd *exec.Cmd, onLine func(string)) ([]byte, error) {
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return fmt.Errorf("rollout status check failed: %w", err)
+		return nil, err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
 	}
+	output := streamLines(stdout, onLine)
+	err = cmd.Wait()
+	return append(output, stderr.Bytes()...), err
+}
 
-	if !strings.Contains(string(output), "successfully rolled out") {
-		return fmt.Errorf("deployment did not roll out successfully")
+// streamLines reads r line by line, calling onLine for each non-empty line, and returns everything read
+func streamLines(r io.Reader, onLine func(string)) []byte {
+	var all bytes.Buffer
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		all.WriteString(line + "\n")
+		if strings.TrimSpace(line) != "" {
+			onLine(line)
+		}
 	}
+	return all.Bytes()
+}
 
-	if c.verbose {
-		fmt.Printf("Rollout status check passed for %s\n", releaseName)
+// waitForRollout runs the rollout status command, retrying while the deployment is not found
+// until the grace period ends. A not-found past the grace period returns ErrRolloutNotFound;
+// any other failure is a failed rollout and is returned immediately.
+func waitForRollout(run func() ([]byte, error), grace, interval time.Duration, log *logging.Logger) ([]byte, error) {
+	deadline := time.Now().Add(grace)
+	for {
+		output, err := run()
+		if err == nil {
+			return output, nil
+		}
+		if !isNotFound(string(output)) {
+			return output, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return output, fmt.Errorf("%w after %s: %s", ErrRolloutNotFound, grace, strings.TrimSpace(string(output)))
+		}
+		log.Debugf("Deployment not found yet, retrying in %s", interval)
+		time.Sleep(interval)
 	}
-	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,384 @@
+package helm
+
+import (
+	"bytes"
+	"errors"
+	stdlog "log"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"sbi-deployment/internal/logging"
+	"sbi-deployment/internal/testutil"
+)
+
+func TestIsRemoteChart(t *testing.T) {
+	tests := []struct {
+		chart string
+		want  bool
+	}{
+		{"oci://harbor.example.com/charts/web", true},
+		{"https://charts.example.com/web-1.0.0.tgz", true},
+		{"bitnami/nginx", true},
+		{"./charts/web", false},
+		{"/srv/charts/web", false},
+		{"charts/team/web", false},
+	}
+	for _, tt := range tests {
+		if got := IsRemoteChart(tt.chart); got != tt.want {
+			t.Errorf("IsRemoteChart(%q) = %v, want %v", tt.chart, got, tt.want)
+		}
+	}
+}
+
+func TestDeployArgsPinsVersionForRemoteCharts(t *testing.T) {
+	opts := DeployOptions{
+		ChartPath:    "oci://harbor.example.com/charts/web",
+		ReleaseName:  "web",
+		Namespace:    "prod",
+		ImageTag:     "v1",
+		Timeout:      300,
+		ChartVersion: "1.2.3",
+	}
+	want := []string{
+		"upgrade", "--install", "web", "oci://harbor.example.com/charts/web",
+		"--namespace", "prod", "--set", "image.tag=v1",
+		"--wait", "--timeout", "300s", "--atomic",
+		"--version", "1.2.3",
+	}
+	if got := DeployArgs(opts); !slices.Equal(got, want) {
+		t.Errorf("DeployArgs() = %q, want %q", got, want)
+	}
+
+	opts.ChartPath = "./charts/web"
+	if got := DeployArgs(opts); slices.Contains(got, "--version") {
+		t.Errorf("DeployArgs() pins a version for a local chart: %q", got)
+	}
+}
+
+func TestDeployArgsImageTagKey(t *testing.T) {
+	args := DeployArgs(DeployOptions{ChartPath: "./chart", ReleaseName: "web", Namespace: "prod", ImageTag: "v1", ImageTagKey: "app.image.tag"})
+	if !slices.Contains(args, "app.image.tag=v1") || slices.Contains(args, "image.tag=v1") {
+		t.Errorf("DeployArgs() = %q, want the tag set at app.image.tag only", args)
+	}
+	if args := DeployArgs(DeployOptions{ImageTag: "v1"}); !slices.Contains(args, "image.tag=v1") {
+		t.Errorf("DeployArgs() = %q, want image.tag by default", args)
+	}
+}
+
+func TestRollbackArgs(t *testing.T) {
+	tests := []struct {
+		revision  int
+		namespace string
+		want      []string
+	}{
+		{0, "prod", []string{"rollback", "web", "--namespace", "prod"}},
+		{5, "prod", []string{"rollback", "web", "5", "--namespace", "prod"}},
+		{5, "", []string{"rollback", "web", "5"}},
+	}
+	for _, tt := range tests {
+		if got := RollbackArgs("web", tt.namespace, tt.revision); !slices.Equal(got, tt.want) {
+			t.Errorf("RollbackArgs(web, %q, %d) = %q, want %q", tt.namespace, tt.revision, got, tt.want)
+		}
+	}
+}
+
+func TestDeployDetectsPendingOperation(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"helm": "echo 'Error: UPGRADE FAILED: another operation (install/upgrade/rollback) is in progress' >&2; exit 1"})
+	_, err := New("", false).Deploy(DeployOptions{ChartPath: "./chart", ReleaseName: "web", Namespace: "prod", ImageTag: "v1"})
+	if !errors.Is(err, ErrOperationInProgress) {
+		t.Errorf("Deploy() error = %v, want ErrOperationInProgress", err)
+	}
+}
+
+func TestLastDeployedRevision(t *testing.T) {
+	history := `[{"revision": 3, "status": "superseded"}, {"revision": 4, "status": "deployed"}, {"revision": 5, "status": "pending-upgrade"}]`
+	testutil.FakeTools(t, map[string]string{"helm": "echo '" + history + "'"})
+	revision, err := New("", false).LastDeployedRevision("web", "prod")
+	if err != nil || revision != 4 {
+		t.Errorf("LastDeployedRevision() = %d, %v; want 4", revision, err)
+	}
+
+	testutil.FakeTools(t, map[string]string{"helm": `echo '[{"revision": 1, "status": "pending-install"}]'`})
+	if _, err := New("", false).LastDeployedRevision("web", "prod"); err == nil {
+		t.Errorf("LastDeployedRevision() found a revision in a history that was never deployed")
+	}
+}
+
+func TestHelmTest(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"helm": "exit 0"})
+	if err := New("", false).Test("web", "prod"); err != nil {
+		t.Fatalf("Test() error = %v", err)
+	}
+	want := []string{"helm test web --namespace prod --logs"}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+
+	testutil.FakeTools(t, map[string]string{"helm": "echo 'TEST SUITE: web-test-connection FAILED'; exit 1"})
+	if err := New("", false).Test("web", "prod"); err == nil || !strings.Contains(err.Error(), "helm test failed for web") {
+		t.Errorf("Test() error = %v, want a failed test", err)
+	}
+}
+
+func TestPullChart(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"helm": `mkdir "$5/web"`})
+	dest := t.TempDir()
+	path, err := New("kubectl", false).PullChart("oci://harbor.example.com/charts/web", "1.4.0", dest)
+	if err != nil {
+		t.Fatalf("PullChart() error = %v", err)
+	}
+	if want := filepath.Join(dest, "web"); path != want {
+		t.Errorf("PullChart() = %q, want %q", path, want)
+	}
+	want := []string{"helm pull oci://harbor.example.com/charts/web --untar --destination " + dest + " --version 1.4.0"}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+
+	testutil.FakeTools(t, map[string]string{"helm": "exit 0"})
+	if _, err := New("kubectl", false).PullChart("repo/web", "", t.TempDir()); err == nil {
+		t.Errorf("PullChart() passed without an unpacked chart directory")
+	}
+}
+
+func TestDeployArgsImageDigest(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	args := DeployArgs(DeployOptions{ChartPath: "./chart", ReleaseName: "web", ImageTag: "sha256-abababababab", ImageDigest: digest})
+	if !slices.Contains(args, "image.digest="+digest) {
+		t.Errorf("DeployArgs() = %q, want the digest at image.digest", args)
+	}
+	args = DeployArgs(DeployOptions{ImageTag: "v1", ImageDigest: digest, ImageDigestKey: "app.image.digest"})
+	if !slices.Contains(args, "app.image.digest="+digest) {
+		t.Errorf("DeployArgs() = %q, want the digest at app.image.digest", args)
+	}
+}
+
+func TestWaitForRollout(t *testing.T) {
+	notFound := []byte(`Error from server (NotFound): deployments.apps "web" not found`)
+	attempts := 0
+	run := func() ([]byte, error) {
+		attempts++
+		if attempts < 3 {
+			return notFound, errors.New("exit status 1")
+		}
+		return []byte(`deployment "web" successfully rolled out`), nil
+	}
+	if _, err := waitForRollout(run, time.Second, time.Millisecond, nil); err != nil || attempts != 3 {
+		t.Errorf("waitForRollout() = %v after %d attempts, want success on the third", err, attempts)
+	}
+
+	missing := func() ([]byte, error) { return notFound, errors.New("exit status 1") }
+	if _, err := waitForRollout(missing, 5*time.Millisecond, time.Millisecond, nil); !errors.Is(err, ErrRolloutNotFound) {
+		t.Errorf("waitForRollout() error = %v, want ErrRolloutNotFound after the grace period", err)
+	}
+
+	attempts = 0
+	failed := func() ([]byte, error) {
+		attempts++
+		return []byte("error: deployment \"web\" exceeded its progress deadline"), errors.New("exit status 1")
+	}
+	if _, err := waitForRollout(failed, time.Second, time.Millisecond, nil); err == nil || errors.Is(err, ErrRolloutNotFound) || attempts != 1 {
+		t.Errorf("waitForRollout() error = %v after %d attempts, want an immediate rollout failure", err, attempts)
+	}
+}
+
+func TestDeployArgsCreateNamespace(t *testing.T) {
+	opts := DeployOptions{ChartPath: "./chart", ReleaseName: "web", Namespace: "preview-42", ImageTag: "v1"}
+	if args := DeployArgs(opts); slices.Contains(args, "--create-namespace") {
+		t.Errorf("DeployArgs() = %q, want no --create-namespace by default", args)
+	}
+	opts.CreateNamespace = true
+	if args := DeployArgs(opts); !slices.Contains(args, "--create-namespace") {
+		t.Errorf("DeployArgs() = %q, want --create-namespace", args)
+	}
+}
+
+func TestDeployReturnsNotes(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"helm": `printf 'Release "web" has been upgraded.\nSTATUS: deployed\nNOTES:\n  Visit https://web.example.com\n\n'`})
+	notes, err := New("", false).Deploy(DeployOptions{ChartPath: "./chart", ReleaseName: "web", Namespace: "prod", ImageTag: "v1"})
+	if err != nil || notes != "Visit https://web.example.com" {
+		t.Errorf("Deploy() = %q, %v; want the chart notes", notes, err)
+	}
+	if got := ExtractNotes("Release \"web\" has been upgraded.\nSTATUS: deployed\n"); got != "" {
+		t.Errorf("ExtractNotes() = %q, want nothing for a chart without notes", got)
+	}
+}
+
+func TestRunStreaming(t *testing.T) {
+	var lines []string
+	cmd := exec.Command("sh", "-c", `echo 'Waiting for 1 of 2 pods'; echo; echo 'successfully rolled out'; echo 'warning' >&2`)
+	output, err := runStreaming(cmd, func(line string) { lines = append(lines, line) })
+	if err != nil {
+		t.Fatalf("runStreaming() error = %v", err)
+	}
+	if want := []string{"Waiting for 1 of 2 pods", "successfully rolled out"}; !slices.Equal(lines, want) {
+		t.Errorf("streamed lines = %q, want %q", lines, want)
+	}
+	if got := string(output); got != "Waiting for 1 of 2 pods\n\nsuccessfully rolled out\nwarning\n" {
+		t.Errorf("output = %q, want stdout followed by stderr", got)
+	}
+}
+
+func TestMatchesSuccessText(t *testing.T) {
+	tests := []struct {
+		output, text string
+		want         bool
+	}{
+		{`deployment "web" successfully rolled out`, "successfully rolled out", true},
+		{`Bereitstellung "web" erfolgreich ausgerollt`, "successfully rolled out", false},
+		{`Bereitstellung "web" erfolgreich ausgerollt`, "erfolgreich", true},
+		{"anything", "", true},
+	}
+	for _, tt := range tests {
+		if got := matchesSuccessText(tt.output, tt.text); got != tt.want {
+			t.Errorf("matchesSuccessText(%q, %q) = %v, want %v", tt.output, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCheckRolloutStatusTrustsExitCode(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"kubectl": `echo 'Bereitstellung "web" erfolgreich ausgerollt'`})
+	client := New("kubectl", false).WithRolloutSuccessText("successfully rolled out")
+	if err := client.CheckRolloutStatus("web", "prod"); err != nil {
+		t.Errorf("CheckRolloutStatus() error = %v, want success from the exit code", err)
+	}
+}
+
+func TestDeployArgsImageRepository(t *testing.T) {
+	args := DeployArgs(DeployOptions{ChartPath: "./chart", ReleaseName: "web", ImageTag: "v1", ImageRepository: "harbor.example.com/web", ImageRepositoryKey: "image.repository"})
+	if !slices.Contains(args, "image.repository=harbor.example.com/web") {
+		t.Errorf("DeployArgs() = %q, want the repository set", args)
+	}
+}
+
+func TestWithDebug(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"helm": "echo 'upgrade.go:142: [debug] preparing upgrade'", "kubectl": "echo prod"})
+	var buf bytes.Buffer
+	stdlog.SetOutput(&buf)
+	logging.SetLevel(logging.Debug)
+	t.Cleanup(func() {
+		stdlog.SetOutput(os.Stderr)
+		logging.SetLevel(logging.Info)
+	})
+
+	client := New("kubectl", false).WithDebug(true)
+	if err := client.BuildDependencies("./chart"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CurrentContext(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"helm dependency build ./chart --debug", "kubectl config current-context"}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want --debug on helm only", got)
+	}
+	if !strings.Contains(buf.String(), "[debug] preparing upgrade") {
+		t.Errorf("log = %q, want the helm output at debug level", buf.String())
+	}
+}
+
+func TestCheckChartPath(t *testing.T) {
+	dir := t.TempDir()
+	c := New("", false)
+	if err := c.CheckChartPath(dir); err != nil {
+		t.Errorf("CheckChartPath(existing) = %v", err)
+	}
+	if err := c.CheckChartPath("oci://harbor.example.com/charts/web"); err != nil {
+		t.Errorf("CheckChartPath(oci) = %v, want remote charts skipped", err)
+	}
+	missing := filepath.Join(dir, "chart")
+	if err := c.CheckChartPath(missing); err == nil || !strings.Contains(err.Error(), `"`+missing+`"`) {
+		t.Errorf("CheckChartPath(missing) = %v, want the quoted path", err)
+	}
+	if err := c.CheckChartPath(missing + "\t"); err == nil || !strings.Contains(err.Error(), "stray whitespace") {
+		t.Errorf("CheckChartPath(trailing tab) = %v, want a whitespace hint", err)
+	}
+}
+
+func TestDeployArgsValuesFiles(t *testing.T) {
+	args := DeployArgs(DeployOptions{ChartPath: "./chart", ReleaseName: "web", ImageTag: "v1", ValuesFiles: []string{"/tmp/generated.yaml"}})
+	if i := slices.Index(args, "--values"); i < 0 || args[i+1] != "/tmp/generated.yaml" {
+		t.Errorf("DeployArgs() = %q, want --values /tmp/generated.yaml", args)
+	}
+}
+
+func TestDeployArgsLabels(t *testing.T) {
+	args := DeployArgs(DeployOptions{ChartPath: "./chart", ReleaseName: "web", ImageTag: "v1", Labels: map[string]string{"owner": "payments", "env": "prod"}})
+	if i := slices.Index(args, "--labels"); i < 0 || args[i+1] != "env=prod,owner=payments" {
+		t.Errorf("DeployArgs() = %q, want sorted --labels env=prod,owner=payments", args)
+	}
+	if args := DeployArgs(DeployOptions{ChartPath: "./chart", ReleaseName: "web", ImageTag: "v1"}); slices.Contains(args, "--labels") {
+		t.Errorf("DeployArgs() = %q, want no --labels without labels", args)
+	}
+}
+
+func TestRolloutRestart(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"kubectl": `echo 'deployment "web" successfully rolled out'`})
+	if err := New("kubectl", false).RolloutRestart("web", "prod"); err != nil {
+		t.Fatalf("RolloutRestart() error = %v", err)
+	}
+	got := testutil.Calls(t, log)
+	if len(got) != 2 || got[0] != "kubectl rollout restart deployment/web -n prod" || !strings.HasPrefix(got[1], "kubectl rollout status deployment/web -n prod") {
+		t.Errorf("calls = %q, want a restart followed by a rollout status", got)
+	}
+
+	log = testutil.FakeTools(t, map[string]string{"kubectl": `echo 'Error from server (Forbidden): deployments.apps "web" is forbidden'; exit 1`})
+	if err := New("kubectl", false).RolloutRestart("web", "prod"); err == nil || !strings.Contains(err.Error(), "Forbidden") {
+		t.Errorf("RolloutRestart() error = %v, want the kubectl output", err)
+	}
+	if got := testutil.Calls(t, log); len(got) != 1 {
+		t.Errorf("calls = %q, want no rollout status after a failed restart", got)
+	}
+}
+
+func TestSetPairs(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	opts := DeployOptions{
+		ImageTag:           "v1",
+		ImageDigest:        digest,
+		ImageRepository:    "harbor.example.com/web",
+		ImageRepositoryKey: "image.repository",
+		SetValues:          map[string]string{"replicaCount": "3"},
+	}
+	want := map[string]string{"image.tag": "v1", "image.digest": digest, "image.repository": "harbor.example.com/web", "replicaCount": "3"}
+	if got := opts.SetPairs(); !maps.Equal(got, want) {
+		t.Errorf("SetPairs() = %v, want %v", got, want)
+	}
+}
+
+func TestDeployArgsNoWait(t *testing.T) {
+	args := DeployArgs(DeployOptions{ChartPath: "./chart", ReleaseName: "web", ImageTag: "v1", Timeout: 300})
+	if !slices.Contains(args, "--wait") || !slices.Contains(args, "--atomic") || !slices.Contains(args, "300s") {
+		t.Errorf("DeployArgs() = %q, want --wait --timeout 300s --atomic", args)
+	}
+	args = DeployArgs(DeployOptions{ChartPath: "./chart", ReleaseName: "web", ImageTag: "v1", Timeout: 300, NoWait: true})
+	if slices.Contains(args, "--wait") || slices.Contains(args, "--atomic") || slices.Contains(args, "--timeout") {
+		t.Errorf("DeployArgs(NoWait) = %q, want no wait flags", args)
+	}
+}
+
+func TestRollbackWithin(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"helm": "exec sleep 10"})
+	start := time.Now()
+	err := New("", false).RollbackWithin("web", "prod", 3, 100*time.Millisecond)
+	if !errors.Is(err, ErrRollbackTimeout) {
+		t.Errorf("RollbackWithin() error = %v, want ErrRollbackTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("RollbackWithin() ran for %s, want the rollback killed", elapsed)
+	}
+
+	log := testutil.FakeTools(t, map[string]string{"helm": ""})
+	if err := New("", false).RollbackWithin("web", "prod", 3, time.Minute); err != nil {
+		t.Errorf("RollbackWithin() error = %v", err)
+	}
+	if got := testutil.Calls(t, log); len(got) != 1 || !strings.HasPrefix(got[0], "helm rollback web 3 --namespace prod") {
+		t.Errorf("calls = %q, want a rollback to revision 3", got)
+	}
+}
@@ -0,0 +1,44 @@
+package helm
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ConfigMapKeyArgs builds the kubectl arguments that print one data key of a ConfigMap
+func ConfigMapKeyArgs(name, key, namespace string) []string {
+	// Keys such as values.yaml need their dots escaped to stay a single jsonpath segment
+	path := strings.ReplaceAll(key, ".", `\.`)
+	return []string{"get", "configmap", name, "-n", namespace, "-o", "jsonpath={.data." + path + "}"}
+}
+
+// ParseConfigMapRef splits a <configmap>/<key> reference such as base-values/values.yaml
+func ParseConfigMapRef(ref string) (name, key string, err error) {
+	name, key, found := strings.Cut(ref, "/")
+	if !found || name == "" || key == "" {
+		return "", "", fmt.Errorf("configmap reference must be <name>/<key>, got %q", ref)
+	}
+	return name, key, nil
+}
+
+// ConfigMapValue returns the value of key in a ConfigMap; jsonpath prints nothing for a
+// missing key, so an empty value is reported as missing
+func (c *Client) ConfigMapValue(name, key, namespace string) ([]byte, error) {
+	output, err := c.command(c.kubeCLI, ConfigMapKeyArgs(name, key, namespace)...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if isNotFound(string(exitErr.Stderr)) {
+				return nil, fmt.Errorf("configmap %s not found in namespace %s", name, namespace)
+			}
+			return nil, fmt.Errorf("failed to read configmap %s/%s: %w: %s", namespace, name, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("failed to read configmap %s/%s: %w", namespace, name, err)
+	}
+	if strings.TrimSpace(string(output)) == "" {
+		return nil, fmt.Errorf("configmap %s/%s has no key %q (or it is empty)", namespace, name, key)
+	}
+	return output, nil
+}
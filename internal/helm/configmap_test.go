@@ -0,0 +1,54 @@
+package helm
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/testutil"
+)
+
+func TestConfigMapKeyArgs(t *testing.T) {
+	want := []string{"get", "configmap", "base-values", "-n", "prod", "-o", `jsonpath={.data.values\.yaml}`}
+	if got := ConfigMapKeyArgs("base-values", "values.yaml", "prod"); !slices.Equal(got, want) {
+		t.Errorf("ConfigMapKeyArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestParseConfigMapRef(t *testing.T) {
+	name, key, err := ParseConfigMapRef("base-values/values.yaml")
+	if err != nil || name != "base-values" || key != "values.yaml" {
+		t.Errorf("ParseConfigMapRef() = %q, %q, %v", name, key, err)
+	}
+	for _, ref := range []string{"base-values", "/values.yaml", "base-values/", ""} {
+		if _, _, err := ParseConfigMapRef(ref); err == nil {
+			t.Errorf("ParseConfigMapRef(%q) passed", ref)
+		}
+	}
+}
+
+func TestConfigMapValue(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"kubectl": "printf 'replicaCount: 3\\n'"})
+	values, err := New("", false).ConfigMapValue("base-values", "values.yaml", "prod")
+	if err != nil || string(values) != "replicaCount: 3\n" {
+		t.Errorf("ConfigMapValue() = %q, %v", values, err)
+	}
+	want := []string{`kubectl get configmap base-values -n prod -o jsonpath={.data.values\.yaml}`}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestConfigMapValueErrors(t *testing.T) {
+	tests := map[string]string{
+		"exit 0": `has no key "values.yaml"`,
+		`echo 'Error from server (NotFound): configmaps "base-values" not found' >&2; exit 1`: "configmap base-values not found in namespace prod",
+		`echo 'Error from server (Forbidden): configmaps is forbidden' >&2; exit 1`:           "Forbidden",
+	}
+	for body, want := range tests {
+		testutil.FakeTools(t, map[string]string{"kubectl": body})
+		if _, err := New("", false).ConfigMapValue("base-values", "values.yaml", "prod"); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ConfigMapValue(%q) error = %v, want %q", body, err, want)
+		}
+	}
+}
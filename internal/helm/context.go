@@ -0,0 +1,66 @@
+package helm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CurrentContextArgs builds the kubectl arguments that print the active context
+func CurrentContextArgs() []string {
+	return []string{"config", "current-context"}
+}
+
+// ClusterServerArgs builds the kubectl arguments that print the active context's API server URL
+func ClusterServerArgs() []string {
+	return []string{"config", "view", "--minify", "-o", "jsonpath={.clusters[0].cluster.server}"}
+}
+
+// CurrentContext returns the active kubectl context name
+func (c *Client) CurrentContext() (string, error) {
+	output, err := c.command(c.kubeCLI, CurrentContextArgs()...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read current kube context: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CurrentClusterServer returns the API server URL of the active kubectl context
+func (c *Client) CurrentClusterServer() (string, error) {
+	output, err := c.command(c.kubeCLI, ClusterServerArgs()...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read current cluster server: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CheckContext verifies that kubectl targets the expected context and/or cluster server
+func (c *Client) CheckContext(expectedContext, expectedCluster string) error {
+	if expectedContext != "" {
+		current, err := c.CurrentContext()
+		if err != nil {
+			return err
+		}
+		if err := matchContext("context", current, expectedContext); err != nil {
+			return err
+		}
+	}
+
+	if expectedCluster != "" {
+		current, err := c.CurrentClusterServer()
+		if err != nil {
+			return err
+		}
+		if err := matchContext("cluster", strings.TrimSuffix(current, "/"), strings.TrimSuffix(expectedCluster, "/")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchContext compares an actual kube context value with the expected one
+func matchContext(kind, actual, expected string) error {
+	if actual != expected {
+		return fmt.Errorf("current kube %s is %q but %q is expected; switch with 'kubectl config use-context' before deploying", kind, actual, expected)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package helm
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/testutil"
+)
+
+func TestCheckContext(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"kubectl": `case "$2" in
+current-context) echo prod-eu ;;
+view) echo https://prod-eu.example.com:6443 ;;
+esac`})
+	client := New("kubectl", false)
+
+	tests := []struct {
+		name            string
+		context, server string
+		wantErr         string
+	}{
+		{name: "unchecked"},
+		{name: "matching context", context: "prod-eu"},
+		{name: "matching server with trailing slash", server: "https://prod-eu.example.com:6443/"},
+		{name: "wrong context", context: "staging", wantErr: `current kube context is "prod-eu" but "staging" is expected`},
+		{name: "wrong server", context: "prod-eu", server: "https://staging.example.com", wantErr: "current kube cluster"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.CheckContext(tt.context, tt.server)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("CheckContext() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("CheckContext() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKubeCLI(t *testing.T) {
+	if got := New("", false).KubeCLI(); got != "kubectl" {
+		t.Errorf("KubeCLI() = %q, want kubectl by default", got)
+	}
+
+	log := testutil.FakeTools(t, map[string]string{"oc": "echo prod-eu"})
+	client := New("oc", false)
+	if got, err := client.CurrentContext(); err != nil || got != "prod-eu" {
+		t.Errorf("CurrentContext() = %q, %v", got, err)
+	}
+	if got := testutil.Calls(t, log); len(got) != 1 || got[0] != "oc config current-context" {
+		t.Errorf("calls = %q, want the context read through oc", got)
+	}
+}
+
+func TestSkipTLSVerify(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"helm": "echo '[]'", "kubectl": "echo prod"})
+	client := New("kubectl", false).WithSkipTLSVerify(true)
+	if _, err := client.ListReleases("prod"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CurrentContext(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"helm list --namespace prod -o json --kube-insecure-skip-tls-verify",
+		"kubectl config current-context --insecure-skip-tls-verify",
+	}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,56 @@
+package helm
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DependencyBuildArgs builds the helm arguments that fetch a chart's subcharts into charts/
+func DependencyBuildArgs(chartPath string) []string {
+	return []string{"dependency", "build", chartPath}
+}
+
+// HasDependencies reports whether a local chart declares subcharts, either in a
+// requirements.yaml (apiVersion v1) or a non-empty dependencies list in Chart.yaml
+func HasDependencies(chartPath string) (bool, error) {
+	if _, err := os.Stat(filepath.Join(chartPath, "requirements.yaml")); err == nil {
+		return true, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(chartPath, "Chart.yaml"))
+	if err != nil {
+		return false, fmt.Errorf("failed to read chart metadata: %w", err)
+	}
+	return declaresDependencies(data), nil
+}
+
+// declaresDependencies reports whether Chart.yaml content has a top-level, non-empty dependencies key
+func declaresDependencies(chartYAML []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(chartYAML))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "dependencies:") {
+			continue
+		}
+		value := strings.TrimSpace(strings.TrimPrefix(line, "dependencies:"))
+		if i := strings.Index(value, "#"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+		return value != "[]" && value != "null" && value != "~"
+	}
+	return false
+}
+
+// BuildDependencies runs `helm dependency build` for a local chart
+func (c *Client) BuildDependencies(chartPath string) error {
+	c.log.Infof("Building chart dependencies for %s...", chartPath)
+	cmd := c.command("helm", DependencyBuildArgs(chartPath)...)
+	if output, err := c.combinedOutput(cmd); err != nil {
+		return fmt.Errorf("failed to build dependencies for %s: %w: %s", chartPath, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package helm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeclaresDependencies(t *testing.T) {
+	tests := []struct {
+		chart string
+		want  bool
+	}{
+		{"apiVersion: v2\nname: web\ndependencies:\n  - name: redis\n    version: 17.x\n", true},
+		{"apiVersion: v2\nname: web\ndependencies: [] # none yet\n", false},
+		{"apiVersion: v2\nname: web\ndependencies: ~\n", false},
+		{"apiVersion: v2\nname: web\nannotations:\n  dependencies: redis\n", false},
+		{"apiVersion: v2\nname: web\n", false},
+	}
+	for _, tt := range tests {
+		if got := declaresDependencies([]byte(tt.chart)); got != tt.want {
+			t.Errorf("declaresDependencies(%q) = %v, want %v", tt.chart, got, tt.want)
+		}
+	}
+}
+
+func TestHasDependencies(t *testing.T) {
+	chart := t.TempDir()
+	if _, err := HasDependencies(chart); err == nil {
+		t.Errorf("HasDependencies() passed without a Chart.yaml")
+	}
+	if err := os.WriteFile(filepath.Join(chart, "Chart.yaml"), []byte("apiVersion: v1\nname: web\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if has, err := HasDependencies(chart); err != nil || has {
+		t.Errorf("HasDependencies() = %v, %v; want false", has, err)
+	}
+	if err := os.WriteFile(filepath.Join(chart, "requirements.yaml"), []byte("dependencies: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if has, err := HasDependencies(chart); err != nil || !has {
+		t.Errorf("HasDependencies() with requirements.yaml = %v, %v; want true", has, err)
+	}
+}
@@ -0,0 +1,82 @@
+package helm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONToSetValues flattens a JSON object of value overrides into --set pairs, e.g.
+// {"image":{"pullPolicy":"Always"},"hosts":["a"]} becomes image.pullPolicy=Always and hosts[0]=a
+func JSONToSetValues(data string) (map[string]string, error) {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+
+	var root interface{}
+	if err := decoder.Decode(&root); err != nil {
+		return nil, fmt.Errorf("invalid values JSON: %w", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("invalid values JSON: unexpected data after the top-level object")
+	}
+	object, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("values JSON must be an object, got %s", jsonKind(root))
+	}
+
+	values := make(map[string]string)
+	flattenJSON("", object, values)
+	return values, nil
+}
+
+// flattenJSON writes the leaves under value into values, keyed by their --set path
+func flattenJSON(path string, value interface{}, values map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := escapeSetKey(key)
+			if path != "" {
+				childPath = path + "." + childPath
+			}
+			flattenJSON(childPath, child, values)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flattenJSON(path+"["+strconv.Itoa(i)+"]", child, values)
+		}
+	case string:
+		values[path] = escapeSetValue(v)
+	case nil:
+		values[path] = "null"
+	default:
+		values[path] = fmt.Sprint(v)
+	}
+}
+
+// escapeSetKey escapes the characters --set treats as path separators inside a key
+func escapeSetKey(key string) string {
+	return strings.NewReplacer(".", `\.`, "[", `\[`, "]", `\]`).Replace(key)
+}
+
+// escapeSetValue escapes the characters --set treats as value separators
+func escapeSetValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`).Replace(value)
+}
+
+// jsonKind names the JSON type of a decoded value for error messages
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return "object"
+}
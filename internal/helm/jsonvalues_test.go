@@ -0,0 +1,51 @@
+package helm
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestJSONToSetValues(t *testing.T) {
+	values, err := JSONToSetValues(`{
+		"image": {"pullPolicy": "Always"},
+		"hosts": ["a.example.com", "b.example.com"],
+		"replicaCount": 3,
+		"cpu": 0.5,
+		"debug": false,
+		"tolerations": null,
+		"annotations": {"prometheus.io/scrape": "true"},
+		"env": "A=1,B=2"
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"image.pullPolicy":                  "Always",
+		"hosts[0]":                          "a.example.com",
+		"hosts[1]":                          "b.example.com",
+		"replicaCount":                      "3",
+		"cpu":                               "0.5",
+		"debug":                             "false",
+		"tolerations":                       "null",
+		`annotations.prometheus\.io/scrape`: "true",
+		"env":                               `A=1\,B=2`,
+	}
+	if !maps.Equal(values, want) {
+		t.Errorf("JSONToSetValues() = %v, want %v", values, want)
+	}
+}
+
+func TestJSONToSetValuesRejects(t *testing.T) {
+	tests := map[string]string{
+		`["a"]`:       "must be an object, got array",
+		`"text"`:      "must be an object, got string",
+		`{"a": 1} {}`: "unexpected data",
+		`{"a": `:      "invalid values JSON",
+	}
+	for data, want := range tests {
+		if _, err := JSONToSetValues(data); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("JSONToSetValues(%s) error = %v, want %q", data, err, want)
+		}
+	}
+}
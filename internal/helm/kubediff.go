@@ -0,0 +1,48 @@
+package helm
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// KubeDiffArgs builds the kubectl arguments that diff the manifest read from stdin against the cluster
+func KubeDiffArgs(namespace string) []string {
+	return []string{"diff", "-n", namespace, "-f", "-"}
+}
+
+// KubeDiffResult interprets the exit of kubectl diff: 0 means no differences and 1 means
+// differences were found; any other exit is a kubectl or API server error
+func KubeDiffResult(err error) (bool, error) {
+	if err == nil {
+		return false, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	return false, err
+}
+
+// KubeDiff renders the chart with helm template and pipes it to kubectl diff, returning the
+// server-side diff and whether anything would change
+func (c *Client) KubeDiff(opts DeployOptions) (string, bool, error) {
+	manifest, err := c.RenderManifest(opts)
+	if err != nil {
+		return "", false, err
+	}
+
+	cmd := c.command(c.kubeCLI, KubeDiffArgs(opts.Namespace)...)
+	cmd.Stdin = strings.NewReader(manifest)
+	output, err := cmd.Output()
+	changed, err := KubeDiffResult(err)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", false, fmt.Errorf("%s diff for %s failed: %w: %s", c.kubeCLI, opts.ReleaseName, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", false, fmt.Errorf("%s diff for %s failed: %w", c.kubeCLI, opts.ReleaseName, err)
+	}
+	return string(output), changed, nil
+}
@@ -0,0 +1,54 @@
+package helm
+
+import (
+	"errors"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/testutil"
+)
+
+func TestKubeDiffResult(t *testing.T) {
+	if changed, err := KubeDiffResult(nil); changed || err != nil {
+		t.Errorf("KubeDiffResult(exit 0) = %v, %v, want no changes", changed, err)
+	}
+	if changed, err := KubeDiffResult(exec.Command("sh", "-c", "exit 1").Run()); !changed || err != nil {
+		t.Errorf("KubeDiffResult(exit 1) = %v, %v, want changes", changed, err)
+	}
+	if _, err := KubeDiffResult(exec.Command("sh", "-c", "exit 2").Run()); err == nil {
+		t.Errorf("KubeDiffResult(exit 2) returned no error")
+	}
+	if _, err := KubeDiffResult(errors.New("executable file not found")); err == nil {
+		t.Errorf("KubeDiffResult(not found) returned no error")
+	}
+}
+
+func TestKubeDiff(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{
+		"helm":    "echo 'kind: Deployment'",
+		"kubectl": "cat > /dev/null; echo '-  image: web:v1'; echo '+  image: web:v2'; exit 1",
+	})
+	diff, changed, err := New("", false).KubeDiff(DeployOptions{ChartPath: "./chart", ReleaseName: "web", Namespace: "prod", ImageTag: "v2"})
+	if err != nil || !changed || !strings.Contains(diff, "+  image: web:v2") {
+		t.Errorf("KubeDiff() = %q, %v, %v", diff, changed, err)
+	}
+	if got := testutil.Calls(t, log); len(got) != 2 || !strings.HasPrefix(got[0], "helm template") || got[1] != "kubectl diff -n prod -f -" {
+		t.Errorf("calls = %q, want helm template piped to kubectl diff", got)
+	}
+
+	testutil.FakeTools(t, map[string]string{
+		"helm":    "echo 'kind: Deployment'",
+		"kubectl": "echo 'error: the server has asked for the client to provide credentials' >&2; exit 2",
+	})
+	if _, _, err := New("", false).KubeDiff(DeployOptions{ChartPath: "./chart", ReleaseName: "web", Namespace: "prod"}); err == nil || !strings.Contains(err.Error(), "provide credentials") {
+		t.Errorf("KubeDiff() error = %v, want the kubectl error", err)
+	}
+}
+
+func TestKubeDiffArgs(t *testing.T) {
+	if got, want := KubeDiffArgs("prod"), []string{"diff", "-n", "prod", "-f", "-"}; !slices.Equal(got, want) {
+		t.Errorf("KubeDiffArgs() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package helm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LintArgs builds the helm lint arguments for a local chart with the values the upgrade would use
+func LintArgs(opts DeployOptions) []string {
+	args := []string{
+		"lint",
+		opts.ChartPath,
+		"--namespace", opts.Namespace,
+		"--set", fmt.Sprintf("%s=%s", opts.tagKey(), opts.ImageTag),
+	}
+	return append(args, valueArgs(opts)...)
+}
+
+// lintFindings returns the [ERROR] and [WARNING] lines of helm lint output
+func lintFindings(output string) (errors, warnings []string) {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "[ERROR]"):
+			errors = append(errors, line)
+		case strings.HasPrefix(line, "[WARNING]"):
+			warnings = append(warnings, line)
+		}
+	}
+	return errors, warnings
+}
+
+// Lint runs helm lint on the chart; warnings are logged and any lint error fails with the findings.
+// It takes the upgrade's DeployOptions rather than just the values files because the tag, --set
+// values and namespace change what the templates render, and the lint must see what the upgrade will.
+func (c *Client) Lint(opts DeployOptions) error {
+	c.log.Infof("Linting chart %s...", opts.ChartPath)
+
+	output, err := c.combinedOutput(c.command("helm", LintArgs(opts)...))
+	lintErrors, warnings := lintFindings(string(output))
+	for _, warning := range warnings {
+		c.log.Warnf("%s", warning)
+	}
+	if err != nil {
+		details := strings.Join(lintErrors, "\n")
+		if details == "" {
+			details = strings.TrimSpace(string(output))
+		}
+		return fmt.Errorf("helm lint of %s failed: %w:\n%s", opts.ChartPath, err, details)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package helm
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/testutil"
+)
+
+const lintOutput = `==> Linting ./chart
+[INFO] Chart.yaml: icon is recommended
+[WARNING] templates/deployment.yaml: object name does not conform to Kubernetes naming requirements
+[ERROR] templates/: template: web/templates/service.yaml:7:14: executing "web/templates/service.yaml" at <.Values.service.port>: nil pointer evaluating interface {}.port
+
+Error: 1 chart(s) linted, 1 chart(s) failed`
+
+func TestLintArgs(t *testing.T) {
+	args := LintArgs(DeployOptions{ChartPath: "./chart", Namespace: "prod", ImageTag: "v1", SetValues: map[string]string{"replicaCount": "3"}})
+	want := []string{"lint", "./chart", "--namespace", "prod", "--set", "image.tag=v1"}
+	if !slices.Equal(args[:len(want)], want) || !slices.Contains(args, "replicaCount=3") {
+		t.Errorf("LintArgs() = %q, want %q followed by the values", args, want)
+	}
+}
+
+func TestLintFindings(t *testing.T) {
+	errors, warnings := lintFindings(lintOutput)
+	if len(errors) != 1 || !strings.HasPrefix(errors[0], "[ERROR] templates/: template: web/templates/service.yaml:7:14") {
+		t.Errorf("errors = %q", errors)
+	}
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "[WARNING] templates/deployment.yaml") {
+		t.Errorf("warnings = %q", warnings)
+	}
+}
+
+func TestLint(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"helm": "cat <<'EOF'\n" + lintOutput + "\nEOF\nexit 1"})
+	err := New("", false).Lint(DeployOptions{ChartPath: "./chart", Namespace: "prod", ImageTag: "v1"})
+	if err == nil || !strings.Contains(err.Error(), "service.yaml:7:14") || strings.Contains(err.Error(), "[INFO]") {
+		t.Errorf("Lint() error = %v, want only the lint errors", err)
+	}
+
+	testutil.FakeTools(t, map[string]string{"helm": "echo '[WARNING] icon is recommended'"})
+	if err := New("", false).Lint(DeployOptions{ChartPath: "./chart", Namespace: "prod", ImageTag: "v1"}); err != nil {
+		t.Errorf("Lint() error = %v, want warnings to pass", err)
+	}
+}
@@ -0,0 +1,46 @@
+package helm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LogsArgs builds the kubectl arguments that follow the logs of every pod in a release
+func LogsArgs(releaseName, namespace string) []string {
+	return []string{"logs",
+		"-n", namespace,
+		"-l", ReleaseSelector(releaseName),
+		"--all-containers",
+		"--prefix",
+		"--follow",
+		"--since", "1m",
+		"--max-log-requests", "20",
+	}
+}
+
+// WatchLogs streams the release's pod logs for duration, then stops following them;
+// reaching the cutoff is the normal way for it to end and is not an error
+func (c *Client) WatchLogs(releaseName, namespace string, duration time.Duration) error {
+	c.log.Infof("Streaming logs for %s for %s...", releaseName, duration)
+
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+
+	cmd := c.commandContext(ctx, c.kubeCLI, LogsArgs(releaseName, namespace)...)
+	cmd.WaitDelay = time.Second
+	output, err := runStreaming(cmd, func(line string) {
+		c.log.Infof("   %s", line)
+	})
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil
+	}
+	if err != nil {
+		// kubectl's complaint comes last, after whatever logs were streamed
+		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+		return fmt.Errorf("failed to stream logs for %s: %w: %s", releaseName, err, lines[len(lines)-1])
+	}
+	return nil
+}
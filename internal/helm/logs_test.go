@@ -0,0 +1,48 @@
+package helm
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"sbi-deployment/internal/testutil"
+)
+
+func TestLogsArgs(t *testing.T) {
+	want := []string{"logs", "-n", "prod", "-l", "app.kubernetes.io/instance=web",
+		"--all-containers", "--prefix", "--follow", "--since", "1m", "--max-log-requests", "20"}
+	if got := LogsArgs("web", "prod"); !slices.Equal(got, want) {
+		t.Errorf("LogsArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestWatchLogs(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"oc": "echo '[pod/web-1/app] started'"})
+	if err := New("oc", false).WatchLogs("web", "prod", time.Minute); err != nil {
+		t.Fatalf("WatchLogs() error = %v", err)
+	}
+	want := []string{"oc " + strings.Join(LogsArgs("web", "prod"), " ")}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestWatchLogsCutoffIsNotAnError(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"kubectl": "echo started; exec sleep 10"})
+	start := time.Now()
+	if err := New("", false).WatchLogs("web", "prod", 100*time.Millisecond); err != nil {
+		t.Errorf("WatchLogs() error = %v, want nil at the cutoff", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("WatchLogs() ran for %s, want it stopped at the cutoff", elapsed)
+	}
+}
+
+func TestWatchLogsReportsLastLine(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"kubectl": "echo started; echo 'error: no pods found' >&2; exit 1"})
+	err := New("", false).WatchLogs("web", "prod", time.Minute)
+	if err == nil || !strings.HasSuffix(err.Error(), ": error: no pods found") {
+		t.Errorf("WatchLogs() error = %v, want kubectl's last line", err)
+	}
+}
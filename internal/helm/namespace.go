@@ -0,0 +1,43 @@
+package helm
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// GetNamespaceArgs builds the kubectl arguments that look up a namespace
+func GetNamespaceArgs(namespace string) []string {
+	return []string{"get", "namespace", namespace, "-o", "name"}
+}
+
+// NamespaceExists reports whether the namespace exists in the current cluster
+func (c *Client) NamespaceExists(namespace string) (bool, error) {
+	output, err := c.command(c.kubeCLI, GetNamespaceArgs(namespace)...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && isNotFound(string(exitErr.Stderr)) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to look up namespace %s: %w", namespace, err)
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// CheckNamespaceExists fails when the namespace is missing instead of letting it be created
+func (c *Client) CheckNamespaceExists(namespace string) error {
+	exists, err := c.NamespaceExists(namespace)
+	if err != nil {
+		return err
+	}
+	return namespaceError(namespace, exists)
+}
+
+// namespaceError returns the error reported for an absent namespace
+func namespaceError(namespace string, exists bool) error {
+	if exists {
+		return nil
+	}
+	return fmt.Errorf("namespace %q does not exist; create it first or fix NAMESPACE (REQUIRE_EXISTING_NAMESPACE is set)", namespace)
+}
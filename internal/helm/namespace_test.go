@@ -0,0 +1,35 @@
+package helm
+
+import (
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/testutil"
+)
+
+func TestCheckNamespaceExists(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+	}{
+		{"exists", "echo namespace/prod", ""},
+		{"missing", `echo 'Error from server (NotFound): namespaces "prod" not found' >&2; exit 1`, `namespace "prod" does not exist`},
+		{"unreachable cluster", "echo 'Unable to connect to the server' >&2; exit 1", "failed to look up namespace prod"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := testutil.FakeTools(t, map[string]string{"kubectl": tt.script})
+			err := New("kubectl", false).CheckNamespaceExists("prod")
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("CheckNamespaceExists() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("CheckNamespaceExists() error = %v, want %q", err, tt.wantErr)
+			}
+			if got := testutil.Calls(t, log); len(got) != 1 || got[0] != "kubectl get namespace prod -o name" {
+				t.Errorf("calls = %q", got)
+			}
+		})
+	}
+}
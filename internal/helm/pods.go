@@ -0,0 +1,203 @@
+package helm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// podList is the subset of `kubectl get pods -o json` output we inspect
+type podList struct {
+	Items []pod `json:"items"`
+}
+
+type pod struct {
+	Metadata struct {
+		Name              string     `json:"name"`
+		DeletionTimestamp *time.Time `json:"deletionTimestamp"`
+	} `json:"metadata"`
+	Status struct {
+		Phase      string `json:"phase"`
+		Conditions []struct {
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		} `json:"conditions"`
+		ContainerStatuses []struct {
+			Name  string `json:"name"`
+			State struct {
+				Waiting *struct {
+					Reason string `json:"reason"`
+				} `json:"waiting"`
+			} `json:"state"`
+		} `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+// ReleaseSelector returns the label selector matching pods that belong to a Helm release
+func ReleaseSelector(releaseName string) string {
+	return fmt.Sprintf("app.kubernetes.io/instance=%s", releaseName)
+}
+
+// ready reports whether the pod has a true Ready condition
+func (p pod) ready() bool {
+	for _, cond := range p.Status.Conditions {
+		if cond.Type == "Ready" {
+			return cond.Status == "True"
+		}
+	}
+	return false
+}
+
+// active reports whether the pod counts toward the release's readiness: completed pods
+// (e.g. hook or job pods) and pods being deleted by the rollout do not
+func (p pod) active() bool {
+	return p.Status.Phase != "Succeeded" && p.Metadata.DeletionTimestamp == nil
+}
+
+// parsePodList decodes kubectl pod list JSON output
+func parsePodList(output []byte) ([]pod, error) {
+	var list podList
+	if err := json.Unmarshal(output, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse pod list: %w", err)
+	}
+	return list.Items, nil
+}
+
+// ReleasePodsArgs builds the kubectl arguments that list a release's pods as JSON
+func ReleasePodsArgs(releaseName, namespace string) []string {
+	return []string{"get", "pods", "-n", namespace, "-l", ReleaseSelector(releaseName), "-o", "json"}
+}
+
+// getReleasePods lists the pods belonging to a release
+func (c *Client) getReleasePods(releaseName, namespace string) ([]pod, error) {
+	cmd := c.command(c.kubeCLI, ReleasePodsArgs(releaseName, namespace)...)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods for release %s: %w", releaseName, err)
+	}
+	return parsePodList(output)
+}
+
+// CheckPodsReady verifies that every running pod of a release is Ready, ignoring completed and terminating pods
+func (c *Client) CheckPodsReady(releaseName, namespace string) error {
+	pods, err := c.getReleasePods(releaseName, namespace)
+	if err != nil {
+		return err
+	}
+
+	var active, notReady []string
+	for _, p := range pods {
+		if !p.active() {
+			continue
+		}
+		active = append(active, p.Metadata.Name)
+		if !p.ready() {
+			notReady = append(notReady, p.Metadata.Name)
+		}
+	}
+	if len(active) == 0 {
+		return fmt.Errorf("no pods found for release %s", releaseName)
+	}
+	if len(notReady) > 0 {
+		return fmt.Errorf("pods not ready for release %s: %s", releaseName, strings.Join(notReady, ", "))
+	}
+
+	c.log.Debugf("All %d pods ready for %s", len(active), releaseName)
+	return nil
+}
+
+// imagePullFailureReasons are container waiting reasons left behind by an unpullable image
+var imagePullFailureReasons = map[string]bool{
+	"ImagePullBackOff": true,
+	"ErrImagePull":     true,
+}
+
+// imagePullFailed reports whether any container in the pod is stuck pulling its image
+func (p pod) imagePullFailed() bool {
+	for _, cs := range p.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && imagePullFailureReasons[cs.State.Waiting.Reason] {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteFailedPods removes release pods stuck in ImagePullBackOff or ErrImagePull
+func (c *Client) DeleteFailedPods(releaseName, namespace string) error {
+	pods, err := c.getReleasePods(releaseName, namespace)
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, p := range pods {
+		if p.imagePullFailed() {
+			failed = append(failed, p.Metadata.Name)
+		}
+	}
+	if len(failed) == 0 {
+		c.log.Debugf("No failed pods found for %s", releaseName)
+		return nil
+	}
+
+	c.log.Infof("Deleting %d failed pods for %s: %s", len(failed), releaseName, strings.Join(failed, ", "))
+	args := append([]string{"delete", "pod", "-n", namespace}, failed...)
+	cmd := c.command(c.kubeCLI, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete pods: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ForceDeletePodsArgs builds the kubectl arguments that delete pods immediately, skipping graceful termination
+func ForceDeletePodsArgs(namespace string, pods []string) []string {
+	args := []string{"delete", "pod", "-n", namespace, "--grace-period=0", "--force"}
+	return append(args, pods...)
+}
+
+// stuck reports whether the pod keeps a rollback from finishing: still terminating after its
+// grace period ran out (the deletion timestamp), or crash-looping or unable to pull its image
+func (p pod) stuck(now time.Time) bool {
+	if ts := p.Metadata.DeletionTimestamp; ts != nil {
+		return now.After(*ts)
+	}
+	if p.Status.Phase == "Succeeded" {
+		return false
+	}
+	for _, cs := range p.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+			return true
+		}
+	}
+	return p.imagePullFailed()
+}
+
+// ForceDeleteStuckPods force-deletes the pods of a release that are stuck terminating past their
+// grace period or stuck in CrashLoopBackOff or ImagePullBackOff; starting and completed pods are left alone
+func (c *Client) ForceDeleteStuckPods(releaseName, namespace string) error {
+	pods, err := c.getReleasePods(releaseName, namespace)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var stuck []string
+	for _, p := range pods {
+		if p.stuck(now) {
+			stuck = append(stuck, p.Metadata.Name)
+		}
+	}
+	if len(stuck) == 0 {
+		c.log.Debugf("No stuck pods found for %s", releaseName)
+		return nil
+	}
+
+	c.log.Infof("Force-deleting %d stuck pods for %s: %s", len(stuck), releaseName, strings.Join(stuck, ", "))
+	cmd := c.command(c.kubeCLI, ForceDeletePodsArgs(namespace, stuck)...)
+	if output, err := c.combinedOutput(cmd); err != nil {
+		return fmt.Errorf("failed to force-delete pods: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
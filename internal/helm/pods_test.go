@@ -0,0 +1,155 @@
+package helm
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/testutil"
+)
+
+// podListJSON has one ready pod, one that is not ready yet, and one stuck pulling its image
+const podListJSON = `{"items": [
+	{"metadata": {"name": "web-1"}, "status": {"phase": "Running", "conditions": [{"type": "Ready", "status": "True"}]}},
+	{"metadata": {"name": "web-2"}, "status": {"phase": "Pending", "conditions": [{"type": "Ready", "status": "False"}]}},
+	{"metadata": {"name": "web-3"}, "status": {"phase": "Pending",
+		"conditions": [{"type": "Ready", "status": "False"}],
+		"containerStatuses": [{"name": "web", "state": {"waiting": {"reason": "ImagePullBackOff"}}}]}}
+]}`
+
+// fakePods makes kubectl get pods print list and every other kubectl call succeed
+func fakePods(t *testing.T, list string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "pods.json")
+	if err := os.WriteFile(file, []byte(list), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return testutil.FakeTools(t, map[string]string{"kubectl": `if [ "$1" = get ]; then cat ` + file + `; fi`})
+}
+
+func TestParsePodList(t *testing.T) {
+	pods, err := parsePodList([]byte(podListJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ready []bool
+	for _, p := range pods {
+		ready = append(ready, p.ready())
+	}
+	if want := []bool{true, false, false}; !slices.Equal(ready, want) {
+		t.Errorf("ready = %v, want %v", ready, want)
+	}
+	if _, err := parsePodList([]byte("not json")); err == nil {
+		t.Errorf("parsePodList() accepted invalid JSON")
+	}
+}
+
+func TestCheckPodsReady(t *testing.T) {
+	log := fakePods(t, podListJSON)
+	err := New("", false).CheckPodsReady("web", "prod")
+	if err == nil || !strings.Contains(err.Error(), "web-2, web-3") {
+		t.Errorf("CheckPodsReady() error = %v, want web-2 and web-3 not ready", err)
+	}
+	want := []string{"kubectl get pods -n prod -l app.kubernetes.io/instance=web -o json"}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+
+	fakePods(t, `{"items": [{"metadata": {"name": "web-1"}, "status": {"conditions": [{"type": "Ready", "status": "True"}]}}]}`)
+	if err := New("", false).CheckPodsReady("web", "prod"); err != nil {
+		t.Errorf("CheckPodsReady() error = %v for a ready release", err)
+	}
+
+	fakePods(t, `{"items": [
+	{"metadata": {"name": "web-1"}, "status": {"phase": "Running", "conditions": [{"type": "Ready", "status": "True"}]}},
+	{"metadata": {"name": "web-2"}, "status": {"phase": "Running", "conditions": [{"type": "Ready", "status": "True"}]}},
+	{"metadata": {"name": "web-migrate"}, "status": {"phase": "Succeeded", "conditions": [{"type": "Ready", "status": "False"}]}},
+	{"metadata": {"name": "web-0", "deletionTimestamp": "2026-01-01T00:00:00Z"}, "status": {"phase": "Running", "conditions": [{"type": "Ready", "status": "False"}]}}
+]}`)
+	if err := New("", false).CheckPodsReady("web", "prod"); err != nil {
+		t.Errorf("CheckPodsReady() error = %v, want completed and terminating pods ignored", err)
+	}
+
+	for _, list := range []string{
+		`{"items": []}`,
+		`{"items": [{"metadata": {"name": "web-migrate"}, "status": {"phase": "Succeeded"}}]}`,
+	} {
+		fakePods(t, list)
+		if err := New("", false).CheckPodsReady("web", "prod"); err == nil {
+			t.Errorf("CheckPodsReady(%s) passed a release without running pods", list)
+		}
+	}
+}
+
+func TestImagePullFailed(t *testing.T) {
+	pods, err := parsePodList([]byte(podListJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var failed []string
+	for _, p := range pods {
+		if p.imagePullFailed() {
+			failed = append(failed, p.Metadata.Name)
+		}
+	}
+	if want := []string{"web-3"}; !slices.Equal(failed, want) {
+		t.Errorf("image pull failures = %q, want %q", failed, want)
+	}
+}
+
+func TestDeleteFailedPods(t *testing.T) {
+	log := fakePods(t, podListJSON)
+	if err := New("", false).DeleteFailedPods("web", "prod"); err != nil {
+		t.Fatalf("DeleteFailedPods() error = %v", err)
+	}
+	got := testutil.Calls(t, log)
+	if want := "kubectl delete pod -n prod web-3"; len(got) != 2 || got[1] != want {
+		t.Errorf("calls = %q, want the list followed by %q", got, want)
+	}
+
+	log = fakePods(t, `{"items": [{"metadata": {"name": "web-1"}, "status": {"conditions": [{"type": "Ready", "status": "True"}]}}]}`)
+	if err := New("", false).DeleteFailedPods("web", "prod"); err != nil {
+		t.Fatalf("DeleteFailedPods() error = %v", err)
+	}
+	if got := testutil.Calls(t, log); len(got) != 1 {
+		t.Errorf("calls = %q, want no delete without failed pods", got)
+	}
+}
+
+func TestForceDeleteStuckPods(t *testing.T) {
+	log := fakePods(t, podListJSON)
+	if err := New("", false).ForceDeleteStuckPods("web", "prod"); err != nil {
+		t.Fatalf("ForceDeleteStuckPods() error = %v", err)
+	}
+	got := testutil.Calls(t, log)
+	if want := "kubectl delete pod -n prod --grace-period=0 --force web-3"; len(got) != 2 || got[1] != want {
+		t.Errorf("calls = %q, want the list followed by %q", got, want)
+	}
+
+	log = fakePods(t, `{"items": [
+	{"metadata": {"name": "web-1", "deletionTimestamp": "2026-01-01T00:00:00Z"}, "status": {"phase": "Running"}},
+	{"metadata": {"name": "web-2", "deletionTimestamp": "2999-01-01T00:00:00Z"}, "status": {"phase": "Running"}},
+	{"metadata": {"name": "web-3"}, "status": {"phase": "Running",
+		"containerStatuses": [{"name": "web", "state": {"waiting": {"reason": "CrashLoopBackOff"}}}]}},
+	{"metadata": {"name": "web-4"}, "status": {"phase": "Running",
+		"containerStatuses": [{"name": "web", "state": {"waiting": {"reason": "ContainerCreating"}}}]}},
+	{"metadata": {"name": "web-migrate"}, "status": {"phase": "Succeeded"}}
+]}`)
+	if err := New("", false).ForceDeleteStuckPods("web", "prod"); err != nil {
+		t.Fatalf("ForceDeleteStuckPods() error = %v", err)
+	}
+	got = testutil.Calls(t, log)
+	if want := "kubectl delete pod -n prod --grace-period=0 --force web-1 web-3"; len(got) != 2 || got[1] != want {
+		t.Errorf("calls = %q, want only the overdue terminating and crash-looping pods deleted: %q", got, want)
+	}
+
+	log = fakePods(t, `{"items": [{"metadata": {"name": "web-1"}, "status": {"conditions": [{"type": "Ready", "status": "True"}]}}]}`)
+	if err := New("", false).ForceDeleteStuckPods("web", "prod"); err != nil {
+		t.Fatalf("ForceDeleteStuckPods() error = %v", err)
+	}
+	if got := testutil.Calls(t, log); len(got) != 1 {
+		t.Errorf("calls = %q, want no delete without stuck pods", got)
+	}
+}
@@ -0,0 +1,48 @@
+package helm
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// PullSecretValueKey is the chart value that names the release's imagePullSecret
+const PullSecretValueKey = "imagePullSecrets[0].name"
+
+// PullSecretManifest renders a kubernetes.io/dockerconfigjson Secret holding dockerConfigJSON
+func PullSecretManifest(name, namespace string, dockerConfigJSON []byte) ([]byte, error) {
+	manifest, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"type":       "kubernetes.io/dockerconfigjson",
+		"metadata":   map[string]string{"name": name, "namespace": namespace},
+		"data":       map[string]string{".dockerconfigjson": base64.StdEncoding.EncodeToString(dockerConfigJSON)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render secret %s/%s: %w", namespace, name, err)
+	}
+	return manifest, nil
+}
+
+// ApplyArgs builds the kubectl arguments that create or update the manifest read from stdin
+func ApplyArgs(namespace string) []string {
+	return []string{"apply", "-n", namespace, "-f", "-"}
+}
+
+// ApplyPullSecret creates or updates an imagePullSecret; the manifest goes in on stdin so
+// the registry password never appears in the process list
+func (c *Client) ApplyPullSecret(name, namespace string, dockerConfigJSON []byte) error {
+	c.log.Debugf("Applying pull secret %s/%s", namespace, name)
+
+	manifest, err := PullSecretManifest(name, namespace, dockerConfigJSON)
+	if err != nil {
+		return err
+	}
+	cmd := c.command(c.kubeCLI, ApplyArgs(namespace)...)
+	cmd.Stdin = strings.NewReader(string(manifest))
+	if output, err := c.combinedOutput(cmd); err != nil {
+		return fmt.Errorf("failed to apply pull secret %s/%s: %w: %s", namespace, name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package helm
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/testutil"
+)
+
+func TestPullSecretManifest(t *testing.T) {
+	manifest, err := PullSecretManifest("harbor-pull", "prod", []byte(`{"auths": {}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var secret struct {
+		Kind     string            `json:"kind"`
+		Type     string            `json:"type"`
+		Metadata map[string]string `json:"metadata"`
+		Data     map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(manifest, &secret); err != nil {
+		t.Fatal(err)
+	}
+	if secret.Kind != "Secret" || secret.Type != "kubernetes.io/dockerconfigjson" || secret.Metadata["name"] != "harbor-pull" || secret.Metadata["namespace"] != "prod" {
+		t.Errorf("PullSecretManifest() = %s", manifest)
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(secret.Data[".dockerconfigjson"]); err != nil || string(decoded) != `{"auths": {}}` {
+		t.Errorf(".dockerconfigjson = %q, %v", decoded, err)
+	}
+}
+
+func TestApplyPullSecret(t *testing.T) {
+	stdin := filepath.Join(t.TempDir(), "manifest.json")
+	log := testutil.FakeTools(t, map[string]string{"kubectl": "cat > " + stdin})
+	if err := New("", false).ApplyPullSecret("harbor-pull", "prod", []byte(`{"auths": {"harbor.example.com": {"password": "hunter2"}}}`)); err != nil {
+		t.Fatalf("ApplyPullSecret() error = %v", err)
+	}
+	want := []string{"kubectl apply -n prod -f -"}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q with the secret on stdin", got, want)
+	}
+	if data, err := os.ReadFile(stdin); err != nil || !strings.Contains(string(data), `"harbor-pull"`) {
+		t.Errorf("stdin = %q, %v, want the secret manifest", data, err)
+	}
+
+	testutil.FakeTools(t, map[string]string{"kubectl": "echo 'Error from server (Forbidden): secrets is forbidden'; exit 1"})
+	if err := New("", false).ApplyPullSecret("harbor-pull", "prod", []byte("{}")); err == nil || !strings.Contains(err.Error(), "Forbidden") {
+		t.Errorf("ApplyPullSecret() error = %v, want the kubectl output", err)
+	}
+}
+
+func TestDeployArgsPullSecret(t *testing.T) {
+	args := DeployArgs(DeployOptions{ChartPath: "./chart", ReleaseName: "web", ImageTag: "v1", PullSecret: "harbor-pull"})
+	if !slices.Contains(args, "imagePullSecrets[0].name=harbor-pull") {
+		t.Errorf("DeployArgs() = %q, want the pull secret set", args)
+	}
+	if pairs := (DeployOptions{ImageTag: "v1", PullSecret: "harbor-pull"}).SetPairs(); pairs[PullSecretValueKey] != "harbor-pull" {
+		t.Errorf("SetPairs() = %v, want the pull secret", pairs)
+	}
+}
@@ -0,0 +1,39 @@
+package helm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RegistryHost returns the host of a registry reference such as oci://harbor.example.com/charts/app
+// or harbor.example.com/project, without scheme or path
+func RegistryHost(ref string) string {
+	for _, scheme := range []string{"oci://", "https://", "http://"} {
+		ref = strings.TrimPrefix(ref, scheme)
+	}
+	host, _, _ := strings.Cut(ref, "/")
+	return host
+}
+
+// OCIChartOnRegistry reports whether chartRef is an OCI chart hosted on the same host as registry
+func OCIChartOnRegistry(chartRef, registry string) bool {
+	return strings.HasPrefix(chartRef, "oci://") && registry != "" &&
+		strings.EqualFold(RegistryHost(chartRef), RegistryHost(registry))
+}
+
+// RegistryLoginArgs builds the helm registry login arguments; the password is read from stdin
+func RegistryLoginArgs(host, username string) []string {
+	return []string{"registry", "login", host, "--username", username, "--password-stdin"}
+}
+
+// RegistryLogin logs helm in to an OCI registry so charts can be pulled from it
+func (c *Client) RegistryLogin(host, username, password string) error {
+	c.log.Debugf("Logging helm in to registry: %s", host)
+
+	cmd := c.command("helm", RegistryLoginArgs(host, username)...)
+	cmd.Stdin = strings.NewReader(password)
+	if output, err := c.combinedOutput(cmd); err != nil {
+		return fmt.Errorf("helm registry login to %s failed: %w: %s", host, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
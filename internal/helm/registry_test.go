@@ -0,0 +1,40 @@
+package helm
+
+import (
+	"slices"
+	"testing"
+
+	"sbi-deployment/internal/testutil"
+)
+
+func TestOCIChartOnRegistry(t *testing.T) {
+	tests := []struct {
+		chart, registry string
+		want            bool
+	}{
+		{"oci://harbor.example.com/charts/web", "harbor.example.com", true},
+		{"oci://Harbor.Example.com:8443/charts/web", "https://harbor.example.com:8443/project", true},
+		{"oci://ghcr.io/org/web", "harbor.example.com", false},
+		{"harbor/web", "harbor.example.com", false},
+		{"oci://harbor.example.com/charts/web", "", false},
+	}
+	for _, tt := range tests {
+		if got := OCIChartOnRegistry(tt.chart, tt.registry); got != tt.want {
+			t.Errorf("OCIChartOnRegistry(%q, %q) = %v, want %v", tt.chart, tt.registry, got, tt.want)
+		}
+	}
+}
+
+func TestRegistryLogin(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"helm": `read password; [ "$password" = secret ]`})
+	if err := New("", false).RegistryLogin("harbor.example.com", "ci", "secret"); err != nil {
+		t.Fatalf("RegistryLogin() error = %v", err)
+	}
+	want := []string{"helm registry login harbor.example.com --username ci --password-stdin"}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+	if err := New("", false).RegistryLogin("harbor.example.com", "ci", "wrong"); err == nil {
+		t.Errorf("RegistryLogin() passed with the wrong password")
+	}
+}
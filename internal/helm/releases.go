@@ -0,0 +1,94 @@
+package helm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+// Release describes a Helm release as reported by helm list
+type Release struct {
+	Name       string
+	Namespace  string
+	Revision   int
+	Status     string
+	Chart      string
+	AppVersion string
+}
+
+// listEntry mirrors one element of `helm list -o json`, where revision is a string
+type listEntry struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Revision   string `json:"revision"`
+	Status     string `json:"status"`
+	Chart      string `json:"chart"`
+	AppVersion string `json:"app_version"`
+}
+
+// parseReleaseList decodes `helm list -o json` output
+func parseReleaseList(output []byte) ([]Release, error) {
+	var entries []listEntry
+	if err := json.Unmarshal(output, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse release list: %w", err)
+	}
+
+	releases := make([]Release, 0, len(entries))
+	for _, e := range entries {
+		revision, err := strconv.Atoi(e.Revision)
+		if err != nil {
+			return nil, fmt.Errorf("invalid revision %q for release %s", e.Revision, e.Name)
+		}
+		releases = append(releases, Release{
+			Name:       e.Name,
+			Namespace:  e.Namespace,
+			Revision:   revision,
+			Status:     e.Status,
+			Chart:      e.Chart,
+			AppVersion: e.AppVersion,
+		})
+	}
+	return releases, nil
+}
+
+// ListReleases returns all releases in a namespace
+func (c *Client) ListReleases(namespace string) ([]Release, error) {
+	cmd := c.command("helm", "list", "--namespace", namespace, "-o", "json")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases in namespace %s: %w", namespace, err)
+	}
+	return parseReleaseList(output)
+}
+
+// MetadataArgs builds the helm arguments that print a release's metadata, including its labels, as JSON
+func MetadataArgs(releaseName, namespace string) []string {
+	return []string{"get", "metadata", releaseName, "--namespace", namespace, "-o", "json"}
+}
+
+// ParseReleaseLabels decodes the labels from `helm get metadata -o json` output
+func ParseReleaseLabels(output []byte) (map[string]string, error) {
+	var metadata struct {
+		Labels map[string]string `json:"labels"`
+	}
+	if err := json.Unmarshal(output, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse release metadata: %w", err)
+	}
+	return metadata.Labels, nil
+}
+
+// ReleaseLabels returns the labels of a live release; found is false if it is not installed yet
+func (c *Client) ReleaseLabels(releaseName, namespace string) (map[string]string, bool, error) {
+	output, err := c.command("helm", MetadataArgs(releaseName, namespace)...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && isNotFound(string(exitErr.Stderr)) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get metadata of release %s: %w", releaseName, err)
+	}
+	labels, err := ParseReleaseLabels(output)
+	return labels, err == nil, err
+}
@@ -0,0 +1,65 @@
+package helm
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"sbi-deployment/internal/testutil"
+)
+
+func TestParseReleaseList(t *testing.T) {
+	output := []byte(`[
+		{"name": "web", "namespace": "prod", "revision": "4", "status": "deployed", "chart": "web-1.4.0", "app_version": "1.4.0"},
+		{"name": "worker", "namespace": "prod", "revision": "1", "status": "failed", "chart": "worker-0.2.0", "app_version": ""}
+	]`)
+	releases, err := parseReleaseList(output)
+	if err != nil {
+		t.Fatalf("parseReleaseList() error = %v", err)
+	}
+	want := []Release{
+		{Name: "web", Namespace: "prod", Revision: 4, Status: "deployed", Chart: "web-1.4.0", AppVersion: "1.4.0"},
+		{Name: "worker", Namespace: "prod", Revision: 1, Status: "failed", Chart: "worker-0.2.0"},
+	}
+	if !slices.Equal(releases, want) {
+		t.Errorf("parseReleaseList() = %+v, want %+v", releases, want)
+	}
+
+	for _, bad := range []string{`not json`, `[{"name": "web", "revision": "latest"}]`} {
+		if _, err := parseReleaseList([]byte(bad)); err == nil {
+			t.Errorf("parseReleaseList(%s) passed", bad)
+		}
+	}
+}
+
+func TestParseReleaseLabels(t *testing.T) {
+	labels, err := ParseReleaseLabels([]byte(`{"name": "web", "revision": 4, "labels": {"owner": "team-payments"}}`))
+	if err != nil || !maps.Equal(labels, map[string]string{"owner": "team-payments"}) {
+		t.Errorf("ParseReleaseLabels() = %v, %v", labels, err)
+	}
+	if _, err := ParseReleaseLabels([]byte("Error: release: not found")); err == nil {
+		t.Errorf("ParseReleaseLabels(not json) passed")
+	}
+}
+
+func TestReleaseLabels(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"helm": `echo '{"labels": {"owner": "team-payments"}}'`})
+	labels, found, err := New("", false).ReleaseLabels("web", "prod")
+	if err != nil || !found || labels["owner"] != "team-payments" {
+		t.Errorf("ReleaseLabels() = %v, %v, %v", labels, found, err)
+	}
+	want := []string{"helm get metadata web --namespace prod -o json"}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+
+	testutil.FakeTools(t, map[string]string{"helm": "echo 'Error: release: not found' >&2; exit 1"})
+	if _, found, err := New("", false).ReleaseLabels("web", "prod"); err != nil || found {
+		t.Errorf("ReleaseLabels(missing) = %v, %v, want not found without an error", found, err)
+	}
+
+	testutil.FakeTools(t, map[string]string{"helm": "echo 'Error: Kubernetes cluster unreachable' >&2; exit 1"})
+	if _, _, err := New("", false).ReleaseLabels("web", "prod"); err == nil {
+		t.Errorf("ReleaseLabels(unreachable) returned no error")
+	}
+}
@@ -0,0 +1,25 @@
+package helm
+
+// Resources holds optional container resource overrides; empty fields are left to the chart
+type Resources struct {
+	CPURequest    string
+	CPULimit      string
+	MemoryRequest string
+	MemoryLimit   string
+}
+
+// SetValues translates the overrides into helm values under the resources key,
+// e.g. resources.limits.memory=512Mi
+func (r Resources) SetValues() map[string]string {
+	values := map[string]string{}
+	add := func(path, value string) {
+		if value != "" {
+			values["resources."+path] = value
+		}
+	}
+	add("requests.cpu", r.CPURequest)
+	add("limits.cpu", r.CPULimit)
+	add("requests.memory", r.MemoryRequest)
+	add("limits.memory", r.MemoryLimit)
+	return values
+}
@@ -0,0 +1,17 @@
+package helm
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestResourcesSetValues(t *testing.T) {
+	got := Resources{CPURequest: "250m", MemoryLimit: "512Mi"}.SetValues()
+	want := map[string]string{"resources.requests.cpu": "250m", "resources.limits.memory": "512Mi"}
+	if !maps.Equal(got, want) {
+		t.Errorf("SetValues() = %v, want %v", got, want)
+	}
+	if got := (Resources{}).SetValues(); len(got) != 0 {
+		t.Errorf("SetValues() = %v, want nothing for empty overrides", got)
+	}
+}
@@ -0,0 +1,29 @@
+package helm
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// GetDockerConfigSecretArgs builds the kubectl arguments that print the base64 .dockerconfigjson of a secret
+func GetDockerConfigSecretArgs(name, namespace string) []string {
+	return []string{"get", "secret", name, "-n", namespace, "-o", `jsonpath={.data.\.dockerconfigjson}`}
+}
+
+// DockerConfigSecret reads and decodes the .dockerconfigjson of a kubernetes.io/dockerconfigjson secret
+func (c *Client) DockerConfigSecret(name, namespace string) ([]byte, error) {
+	output, err := c.command(c.kubeCLI, GetDockerConfigSecretArgs(name, namespace)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %s/%s: %w", namespace, name, err)
+	}
+	encoded := strings.TrimSpace(string(output))
+	if encoded == "" {
+		return nil, fmt.Errorf("secret %s/%s has no .dockerconfigjson", namespace, name)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret %s/%s: %w", namespace, name, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,78 @@
+package helm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ActiveColorAnnotation records which blue/green color a Service currently routes to
+const ActiveColorAnnotation = "sbi-deployment/active-color"
+
+// service is the subset of `kubectl get service -o json` output we inspect
+type service struct {
+	Metadata struct {
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+}
+
+// GetServiceArgs builds the kubectl arguments that read a Service as JSON
+func GetServiceArgs(name, namespace string) []string {
+	return []string{"get", "service", name, "-n", namespace, "-o", "json"}
+}
+
+// PatchServiceArgs builds the kubectl arguments that apply a merge patch to a Service
+func PatchServiceArgs(name, namespace, patch string) []string {
+	return []string{"patch", "service", name, "-n", namespace, "--type", "merge", "-p", patch}
+}
+
+// ServiceSwitchPatch builds the merge patch pointing a Service's selectorKey at release and
+// recording color as the active color
+func ServiceSwitchPatch(selectorKey, release, color string) (string, error) {
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{ActiveColorAnnotation: color},
+		},
+		"spec": map[string]interface{}{
+			"selector": map[string]string{selectorKey: release},
+		},
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return "", fmt.Errorf("failed to build service patch: %w", err)
+	}
+	return string(data), nil
+}
+
+// parseActiveColor reads the active color annotation from Service JSON; "" means none recorded yet
+func parseActiveColor(output []byte) (string, error) {
+	var svc service
+	if err := json.Unmarshal(output, &svc); err != nil {
+		return "", fmt.Errorf("failed to parse service: %w", err)
+	}
+	return svc.Metadata.Annotations[ActiveColorAnnotation], nil
+}
+
+// ActiveColor returns the blue/green color the Service currently routes to
+func (c *Client) ActiveColor(name, namespace string) (string, error) {
+	output, err := c.command(c.kubeCLI, GetServiceArgs(name, namespace)...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read service %s: %w", name, err)
+	}
+	return parseActiveColor(output)
+}
+
+// SwitchService points the Service at release and records color as active
+func (c *Client) SwitchService(name, namespace, selectorKey, release, color string) error {
+	patch, err := ServiceSwitchPatch(selectorKey, release, color)
+	if err != nil {
+		return err
+	}
+
+	c.log.Infof("Switching service %s to %s (%s)...", name, release, color)
+	cmd := c.command(c.kubeCLI, PatchServiceArgs(name, namespace, patch)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to switch service %s: %w: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package helm
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/testutil"
+)
+
+func TestServiceSwitchPatch(t *testing.T) {
+	patch, err := ServiceSwitchPatch("app.kubernetes.io/instance", "web-green", "green")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"metadata":{"annotations":{"sbi-deployment/active-color":"green"}},"spec":{"selector":{"app.kubernetes.io/instance":"web-green"}}}`
+	if patch != want {
+		t.Errorf("ServiceSwitchPatch() = %s, want %s", patch, want)
+	}
+}
+
+func TestActiveColor(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"kubectl": `echo '{"metadata": {"annotations": {"sbi-deployment/active-color": "blue"}}}'`})
+	if color, err := New("kubectl", false).ActiveColor("web", "prod"); err != nil || color != "blue" {
+		t.Errorf("ActiveColor() = %q, %v; want blue", color, err)
+	}
+	if color, err := parseActiveColor([]byte(`{"metadata": {}}`)); err != nil || color != "" {
+		t.Errorf("parseActiveColor() without the annotation = %q, %v", color, err)
+	}
+}
+
+func TestSwitchService(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"kubectl": "exit 0"})
+	if err := New("kubectl", false).SwitchService("web", "prod", "release", "web-blue", "blue"); err != nil {
+		t.Fatal(err)
+	}
+	patch, _ := ServiceSwitchPatch("release", "web-blue", "blue")
+	want := []string{"kubectl " + strings.Join(PatchServiceArgs("web", "prod", patch), " ")}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,232 @@
+package helm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ShowValuesArgs builds the helm arguments that print a chart's default values
+func ShowValuesArgs(chartRef, version string) []string {
+	args := []string{"show", "values", chartRef}
+	if version != "" && IsRemoteChart(chartRef) {
+		args = append(args, "--version", version)
+	}
+	return args
+}
+
+// ChartValue returns the default value at a dotted path (e.g. image.repository) in a chart's values
+func (c *Client) ChartValue(chartRef, version, path string) (string, bool, error) {
+	cmd := c.command("helm", ShowValuesArgs(chartRef, version)...)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read values of chart %s: %w", chartRef, err)
+	}
+	value, ok := LookupValue(output, path)
+	return value, ok, nil
+}
+
+// LookupValue finds the scalar at a dotted path in block-style values YAML.
+// Only nested mappings are followed; flow mappings and lists are not supported.
+func LookupValue(values []byte, path string) (string, bool) {
+	keys := strings.Split(path, ".")
+	depth := 0
+	parentIndent := -1
+	childIndent := -1
+
+	scanner := bufio.NewScanner(bytes.NewReader(values))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if indent <= parentIndent {
+			// Left the block of the last matched key without finding the next one
+			return "", false
+		}
+		if childIndent == -1 {
+			childIndent = indent
+		}
+		if indent != childIndent {
+			continue
+		}
+
+		key, value, found := strings.Cut(trimmed, ":")
+		if !found || strings.Trim(key, `"'`) != keys[depth] {
+			continue
+		}
+		if depth == len(keys)-1 {
+			return scalarValue(value), true
+		}
+		depth++
+		parentIndent = indent
+		childIndent = -1
+	}
+	return "", false
+}
+
+// CheckValuesYAML rejects output that cannot be a values document: empty output, tab
+// indentation, a top-level list or scalar, or a JSON document that does not parse.
+// It is a structural check, not a full YAML parser; helm reports anything subtler.
+func CheckValuesYAML(values []byte) error {
+	trimmed := bytes.TrimSpace(values)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var object map[string]interface{}
+		if err := json.Unmarshal(trimmed, &object); err != nil {
+			return fmt.Errorf("invalid JSON values: %w", err)
+		}
+		return nil
+	}
+
+	keys := 0
+	lineNumber := 0
+	scanner := bufio.NewScanner(bytes.NewReader(values))
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		content := strings.TrimSpace(line)
+		if content == "" || strings.HasPrefix(content, "#") || content == "---" || content == "..." {
+			continue
+		}
+		if indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]; strings.Contains(indent, "\t") {
+			return fmt.Errorf("line %d: tabs are not allowed in YAML indentation", lineNumber)
+		}
+		if line[0] == ' ' {
+			continue
+		}
+		key, _, found := strings.Cut(content, ":")
+		if !found || content == "-" || strings.HasPrefix(content, "- ") || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("line %d: expected a top-level key: value mapping, got %q", lineNumber, content)
+		}
+		keys++
+	}
+	if keys == 0 {
+		return fmt.Errorf("no values found")
+	}
+	return nil
+}
+
+// scalarValue strips inline comments and quotes from a YAML scalar
+func scalarValue(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "~" || value == "null" {
+		return ""
+	}
+	if value[0] == '"' || value[0] == '\'' {
+		if end := strings.IndexByte(value[1:], value[0]); end >= 0 {
+			return value[1 : end+1]
+		}
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value
+}
+
+// GetValuesArgs builds the helm arguments that print a release's user-supplied values as JSON
+func GetValuesArgs(releaseName, namespace string) []string {
+	return []string{"get", "values", releaseName, "--namespace", namespace, "-o", "json"}
+}
+
+// GetDeployedTag returns the value at key (e.g. image.tag) in the live release's values.
+// An empty tag and no error means the release or the key does not exist.
+func (c *Client) GetDeployedTag(releaseName, namespace, key string) (string, error) {
+	cmd := c.command("helm", GetValuesArgs(releaseName, namespace)...)
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && isNotFound(string(exitErr.Stderr)) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get values of release %s: %w", releaseName, err)
+	}
+	return ParseDeployedValue(output, key)
+}
+
+// ReleaseValues returns the user-supplied values of a live release flattened to --set paths;
+// found is false if the release is not installed yet
+func (c *Client) ReleaseValues(releaseName, namespace string) (map[string]string, bool, error) {
+	output, err := c.command("helm", GetValuesArgs(releaseName, namespace)...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && isNotFound(string(exitErr.Stderr)) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get values of release %s: %w", releaseName, err)
+	}
+	values, err := ParseReleaseValues(output)
+	return values, err == nil, err
+}
+
+// ParseReleaseValues flattens `helm get values -o json` output to --set paths; a release
+// without user-supplied values prints null
+func ParseReleaseValues(output []byte) (map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	decoder.UseNumber()
+
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, fmt.Errorf("failed to parse release values: %w", err)
+	}
+	values := make(map[string]string)
+	flattenJSON("", object, values)
+	return values, nil
+}
+
+// ParseDeployedValue finds the scalar at a dotted path in `helm get values -o json` output
+func ParseDeployedValue(output []byte, key string) (string, error) {
+	var values map[string]interface{}
+	if err := json.Unmarshal(output, &values); err != nil {
+		return "", fmt.Errorf("failed to parse release values: %w", err)
+	}
+
+	var current interface{} = values
+	for _, part := range strings.Split(key, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return "", nil
+		}
+		current = m[part]
+	}
+	if _, nested := current.(map[string]interface{}); nested || current == nil {
+		return "", nil
+	}
+	return fmt.Sprint(current), nil
+}
+
+// GetManifestArgs builds the helm arguments that print the manifest of the live release
+func GetManifestArgs(releaseName, namespace string) []string {
+	return []string{"get", "manifest", releaseName, "--namespace", namespace}
+}
+
+// CurrentManifest returns the rendered manifest of the live release, or "" if it is not installed yet
+func (c *Client) CurrentManifest(releaseName, namespace string) (string, error) {
+	output, err := c.command("helm", GetManifestArgs(releaseName, namespace)...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && isNotFound(string(exitErr.Stderr)) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get manifest of release %s: %w", releaseName, err)
+	}
+	return string(output), nil
+}
+
+// RenderManifest renders the manifest the upgrade described by opts would apply
+func (c *Client) RenderManifest(opts DeployOptions) (string, error) {
+	output, err := c.command("helm", TemplateArgs(opts)...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("failed to render chart %s: %w: %s", opts.ChartPath, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("failed to render chart %s: %w", opts.ChartPath, err)
+	}
+	return string(output), nil
+}
@@ -0,0 +1,121 @@
+package helm
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/testutil"
+)
+
+const chartValues = `# Default values for web
+replicaCount: 2
+image:
+  repository: "harbor.example.com/web"   # pushed by sbi-deploy
+  tag: ~
+  pullPolicy: IfNotPresent # keep
+sidecar:
+  image:
+    repository: busybox
+`
+
+func TestLookupValue(t *testing.T) {
+	tests := []struct {
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{"replicaCount", "2", true},
+		{"image.repository", "harbor.example.com/web", true},
+		{"image.tag", "", true},
+		{"image.pullPolicy", "IfNotPresent", true},
+		{"sidecar.image.repository", "busybox", true},
+		{"image.digest", "", false},
+		{"ingress.host", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := LookupValue([]byte(chartValues), tt.path)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("LookupValue(%q) = %q, %v; want %q, %v", tt.path, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestParseDeployedValue(t *testing.T) {
+	output := []byte(`{"image": {"tag": "v1.2.0", "pullPolicy": "Always"}, "replicaCount": 3}`)
+	tests := map[string]string{"image.tag": "v1.2.0", "replicaCount": "3", "image": "", "image.digest": "", "replicaCount.value": ""}
+	for key, want := range tests {
+		if got, err := ParseDeployedValue(output, key); err != nil || got != want {
+			t.Errorf("ParseDeployedValue(%q) = %q, %v; want %q", key, got, err, want)
+		}
+	}
+	if got, err := ParseDeployedValue([]byte("null"), "image.tag"); err != nil || got != "" {
+		t.Errorf("ParseDeployedValue() of a release without values = %q, %v", got, err)
+	}
+}
+
+func TestGetDeployedTagForMissingRelease(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"helm": "echo 'Error: release: not found' >&2; exit 1"})
+	if got, err := New("", false).GetDeployedTag("web", "prod", "image.tag"); err != nil || got != "" {
+		t.Errorf("GetDeployedTag() = %q, %v; want no tag for a new release", got, err)
+	}
+}
+
+func TestCheckValuesYAML(t *testing.T) {
+	valid := []string{
+		chartValues,
+		"---\nreplicaCount: 3\n...\n",
+		`{"replicaCount": 3}`,
+	}
+	for _, values := range valid {
+		if err := CheckValuesYAML([]byte(values)); err != nil {
+			t.Errorf("CheckValuesYAML(%q) = %v", values, err)
+		}
+	}
+	invalid := map[string]string{
+		"":                       "no values found",
+		"# only a comment\n":     "no values found",
+		"image:\n\ttag: v1\n":    "line 2: tabs",
+		"- one\n- two\n":         "line 1: expected a top-level key",
+		"just a scalar\n":        "line 1: expected a top-level key",
+		`{"replicaCount": 3`:     "invalid JSON values",
+		"ok: 1\n: missing key\n": "line 2",
+	}
+	for values, want := range invalid {
+		if err := CheckValuesYAML([]byte(values)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("CheckValuesYAML(%q) = %v, want %q", values, err, want)
+		}
+	}
+}
+
+func TestParseReleaseValues(t *testing.T) {
+	values, err := ParseReleaseValues([]byte(`{"image": {"tag": "v1"}, "replicaCount": 3}`))
+	want := map[string]string{"image.tag": "v1", "replicaCount": "3"}
+	if err != nil || !maps.Equal(values, want) {
+		t.Errorf("ParseReleaseValues() = %v, %v, want %v", values, err, want)
+	}
+	if values, err := ParseReleaseValues([]byte("null\n")); err != nil || len(values) != 0 {
+		t.Errorf("ParseReleaseValues(null) = %v, %v, want no values", values, err)
+	}
+	if _, err := ParseReleaseValues([]byte("image:\n  tag: v1\n")); err == nil {
+		t.Errorf("ParseReleaseValues(yaml) passed")
+	}
+}
+
+func TestReleaseValues(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"helm": `echo '{"image": {"tag": "v1"}}'`})
+	values, found, err := New("", false).ReleaseValues("web", "prod")
+	if err != nil || !found || values["image.tag"] != "v1" {
+		t.Errorf("ReleaseValues() = %v, %v, %v", values, found, err)
+	}
+	want := []string{"helm get values web --namespace prod -o json"}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+
+	testutil.FakeTools(t, map[string]string{"helm": "echo 'Error: release: not found' >&2; exit 1"})
+	if _, found, err := New("", false).ReleaseValues("web", "prod"); err != nil || found {
+		t.Errorf("ReleaseValues(missing) = %v, %v, want not found without an error", found, err)
+	}
+}
@@ -0,0 +1,136 @@
+package logging
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+)
+
+// Level controls which messages are printed
+type Level int32
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+	Error
+)
+
+var levelNames = map[Level]string{
+	Debug: "debug",
+	Info:  "info",
+	Warn:  "warn",
+	Error: "error",
+}
+
+var current atomic.Int32
+
+func init() {
+	current.Store(int32(Info))
+}
+
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", int32(l))
+}
+
+// ParseLevel converts a level name such as "debug" or "warn" to a Level
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return Debug, nil
+	case "info", "":
+		return Info, nil
+	case "warn", "warning":
+		return Warn, nil
+	case "error":
+		return Error, nil
+	}
+	return Info, fmt.Errorf("unknown log level %q (expected debug, info, warn or error)", name)
+}
+
+// SetLevel sets the minimum level that is printed
+func SetLevel(l Level) {
+	current.Store(int32(l))
+}
+
+// CurrentLevel returns the minimum level that is printed
+func CurrentLevel() Level {
+	return Level(current.Load())
+}
+
+// Enabled reports whether messages at level l are printed
+func Enabled(l Level) bool {
+	return l >= CurrentLevel()
+}
+
+// Logger prefixes every line, e.g. with the image a goroutine is processing,
+// so output from parallel work stays readable. The zero prefix matches the package functions.
+type Logger struct {
+	prefix string
+}
+
+var std = &Logger{}
+
+// Default returns the unprefixed logger used by the package-level functions
+func Default() *Logger {
+	return std
+}
+
+// WithPrefix returns a logger that starts every line with prefix followed by a space
+func WithPrefix(prefix string) *Logger {
+	return &Logger{prefix: prefix + " "}
+}
+
+func (l *Logger) printf(level Level, format string, args ...interface{}) {
+	if !Enabled(level) {
+		return
+	}
+	if l == nil {
+		l = std
+	}
+	log.Printf(l.prefix+format, args...)
+}
+
+// Debugf prints detailed progress output from the docker/helm clients
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.printf(Debug, format, args...)
+}
+
+// Infof prints normal deployment progress
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.printf(Info, format, args...)
+}
+
+// Warnf prints a non-fatal problem prefixed with "Warning:"
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.printf(Warn, "Warning: "+format, args...)
+}
+
+// Errorf prints a failure that is about to be reported
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.printf(Error, "Error: "+format, args...)
+}
+
+// Debugf prints detailed progress output from the docker/helm clients
+func Debugf(format string, args ...interface{}) {
+	std.Debugf(format, args...)
+}
+
+// Infof prints normal deployment progress
+func Infof(format string, args ...interface{}) {
+	std.Infof(format, args...)
+}
+
+// Warnf prints a non-fatal problem prefixed with "Warning:"
+func Warnf(format string, args ...interface{}) {
+	std.Warnf(format, args...)
+}
+
+// Errorf prints a failure that is about to be reported
+func Errorf(format string, args ...interface{}) {
+	std.Errorf(format, args...)
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+)
+
+// captureLog redirects the standard logger into a buffer at the given level for the rest of the test
+func captureLog(t *testing.T, level Level) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	flags, previous := log.Flags(), CurrentLevel()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	SetLevel(level)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+		SetLevel(previous)
+	})
+	return &buf
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]Level{"debug": Debug, "INFO": Info, "": Info, " warning ": Warn, "warn": Warn, "error": Error}
+	for name, want := range tests {
+		if got, err := ParseLevel(name); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Errorf("ParseLevel(verbose) passed")
+	}
+}
+
+func TestLevelFiltering(t *testing.T) {
+	buf := captureLog(t, Warn)
+	Debugf("pulling %s", "web")
+	Infof("deploying %s", "web")
+	Warnf("slow registry")
+	Errorf("deploy failed")
+
+	want := "Warning: slow registry\nError: deploy failed\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if Enabled(Info) || !Enabled(Error) {
+		t.Errorf("Enabled() disagrees with level %s", CurrentLevel())
+	}
+}
+
+func TestLevelString(t *testing.T) {
+	if Warn.String() != "warn" || Level(9).String() != "level(9)" {
+		t.Errorf("String() = %q, %q", Warn, Level(9))
+	}
+}
+
+func TestWithPrefix(t *testing.T) {
+	buf := captureLog(t, Info)
+	WithPrefix("[web]").Infof("pushing %s", "v1")
+	WithPrefix("[worker]").Warnf("slow registry")
+	Default().Infof("done")
+	var unset *Logger
+	unset.Infof("nil logger")
+
+	want := "[web] pushing v1\n[worker] Warning: slow registry\ndone\nnil logger\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StatsD sends metrics over UDP in StatsD format, with DogStatsD tags when any are set
+type StatsD struct {
+	addr   string
+	prefix string
+	tags   map[string]string
+}
+
+// New creates a StatsD client for addr (host:port); metric names are prefixed with prefix
+func New(addr, prefix string, tags map[string]string) *StatsD {
+	return &StatsD{addr: addr, prefix: prefix, tags: tags}
+}
+
+// Metric is a single StatsD sample
+type Metric struct {
+	Name  string
+	Value string
+	Type  string // "c" for counters, "ms" for timings
+}
+
+// Count returns a counter sample
+func Count(name string, n int) Metric {
+	return Metric{Name: name, Value: fmt.Sprint(n), Type: "c"}
+}
+
+// Timing returns a timing sample in milliseconds
+func Timing(name string, d time.Duration) Metric {
+	return Metric{Name: name, Value: fmt.Sprint(d.Milliseconds()), Type: "ms"}
+}
+
+// Format renders metrics as newline-separated StatsD lines, e.g. sbi.deploy.duration:1520|ms|#env:prod
+func (s *StatsD) Format(metrics ...Metric) string {
+	tags := s.tagSuffix()
+	lines := make([]string, 0, len(metrics))
+	for _, m := range metrics {
+		name := m.Name
+		if s.prefix != "" {
+			name = s.prefix + "." + name
+		}
+		lines = append(lines, fmt.Sprintf("%s:%s|%s%s", name, m.Value, m.Type, tags))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tagSuffix renders the DogStatsD tag section, sorted so output is deterministic
+func (s *StatsD) tagSuffix() string {
+	if len(s.tags) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(s.tags))
+	for key, value := range s.tags {
+		pairs = append(pairs, key+":"+value)
+	}
+	sort.Strings(pairs)
+	return "|#" + strings.Join(pairs, ",")
+}
+
+// Send writes metrics to the StatsD endpoint in a single datagram
+func (s *StatsD) Send(metrics ...Metric) error {
+	conn, err := net.DialTimeout("udp", s.addr, 2*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to reach statsd at %s: %w", s.addr, err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(s.Format(metrics...))); err != nil {
+		return fmt.Errorf("failed to send metrics to %s: %w", s.addr, err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	s := New("127.0.0.1:8125", "sbi", map[string]string{"release": "web", "env": "prod"})
+	got := s.Format(Count("deploy.success", 1), Timing("deploy.duration", 1520*time.Millisecond))
+	want := "sbi.deploy.success:1|c|#env:prod,release:web\nsbi.deploy.duration:1520|ms|#env:prod,release:web"
+	if got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+	if got := New("127.0.0.1:8125", "", nil).Format(Count("deploy.failure", 1)); got != "deploy.failure:1|c" {
+		t.Errorf("Format() without prefix or tags = %q", got)
+	}
+}
+
+func TestSend(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := New(conn.LocalAddr().String(), "sbi", nil).Send(Count("deploy.success", 1)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	buf := make([]byte, 512)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil || string(buf[:n]) != "sbi.deploy.success:1|c" {
+		t.Errorf("received %q, %v", buf[:n], err)
+	}
+}
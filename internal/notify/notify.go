@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Event describes a finished deployment
+type Event struct {
+	Release   string  `json:"release"`
+	Namespace string  `json:"namespace"`
+	Image     string  `json:"image"`
+	Tag       string  `json:"tag"`
+	Success   bool    `json:"success"`
+	Error     string  `json:"error,omitempty"`
+	Duration  float64 `json:"duration_seconds"`
+}
+
+// Text returns a one-line human readable description of the event
+func (e Event) Text() string {
+	if e.Success {
+		return fmt.Sprintf("✅ Deployed %s:%s to %s/%s in %.0fs", e.Image, e.Tag, e.Namespace, e.Release, e.Duration)
+	}
+	return fmt.Sprintf("❌ Deploy of %s:%s to %s/%s failed: %s", e.Image, e.Tag, e.Namespace, e.Release, e.Error)
+}
+
+// Formatter builds the webhook payload for a chat tool
+type Formatter func(Event) interface{}
+
+// formatters maps NOTIFY_FORMAT values to payload builders
+var formatters = map[string]Formatter{
+	"slack":      slackPayload,
+	"teams":      teamsPayload,
+	"mattermost": mattermostPayload,
+	"raw":        rawPayload,
+}
+
+// Formats returns the supported NOTIFY_FORMAT values
+func Formats() []string {
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func slackPayload(e Event) interface{} {
+	return map[string]string{"text": e.Text()}
+}
+
+func mattermostPayload(e Event) interface{} {
+	return map[string]string{"text": e.Text(), "username": "sbi-deploy"}
+}
+
+// teamsPayload builds a legacy Office 365 connector MessageCard
+func teamsPayload(e Event) interface{} {
+	color := "2EB886"
+	if !e.Success {
+		color = "D00000"
+	}
+	return map[string]string{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    e.Text(),
+		"themeColor": color,
+		"title":      fmt.Sprintf("Deployment of %s", e.Release),
+		"text":       e.Text(),
+	}
+}
+
+func rawPayload(e Event) interface{} {
+	return e
+}
+
+// Payload encodes the event in the given format
+func Payload(format string, e Event) ([]byte, error) {
+	formatter, ok := formatters[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown notify format %q (expected one of %s)", format, strings.Join(Formats(), ", "))
+	}
+	return json.Marshal(formatter(e))
+}
+
+// Notifier posts deployment events to a chat webhook
+type Notifier struct {
+	url        string
+	format     string
+	httpClient *http.Client
+}
+
+// New creates a new Notifier; format defaults to slack
+func New(url, format string) *Notifier {
+	if format == "" {
+		format = "slack"
+	}
+	return &Notifier{
+		url:        url,
+		format:     format,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send posts the event to the webhook
+func (n *Notifier) Send(e Event) error {
+	payload, err := Payload(n.format, e)
+	if err != nil {
+		return err
+	}
+	resp, err := n.httpClient.Post(n.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notification webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var event = Event{Release: "web", Namespace: "prod", Image: "web", Tag: "v1.2.0", Success: true, Duration: 42}
+
+func TestPayload(t *testing.T) {
+	tests := []struct {
+		format string
+		key    string
+		want   string
+	}{
+		{"slack", "text", "✅ Deployed web:v1.2.0 to prod/web in 42s"},
+		{"Mattermost", "username", "sbi-deploy"},
+		{"teams", "themeColor", "2EB886"},
+		{"raw", "tag", "v1.2.0"},
+	}
+	for _, tt := range tests {
+		payload, err := Payload(tt.format, event)
+		if err != nil {
+			t.Fatalf("Payload(%s) error = %v", tt.format, err)
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(payload, &fields); err != nil {
+			t.Fatal(err)
+		}
+		if fields[tt.key] != tt.want {
+			t.Errorf("Payload(%s)[%s] = %v, want %q", tt.format, tt.key, fields[tt.key], tt.want)
+		}
+	}
+
+	if _, err := Payload("discord", event); err == nil || !strings.Contains(err.Error(), "mattermost, raw, slack, teams") {
+		t.Errorf("Payload(discord) error = %v, want the supported formats listed", err)
+	}
+}
+
+func TestFailureText(t *testing.T) {
+	failed := Event{Release: "web", Namespace: "prod", Image: "web", Tag: "v1.2.0", Error: "rollout timed out"}
+	if got := failed.Text(); got != "❌ Deploy of web:v1.2.0 to prod/web failed: rollout timed out" {
+		t.Errorf("Text() = %q", got)
+	}
+}
+
+func TestSend(t *testing.T) {
+	var body string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	if err := New(server.URL, "").Send(event); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if !strings.HasPrefix(body, `{"text":`) {
+		t.Errorf("posted %s, want a slack payload by default", body)
+	}
+
+	status = http.StatusNotFound
+	if err := New(server.URL, "slack").Send(event); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Errorf("Send() error = %v, want the webhook status", err)
+	}
+}
@@ -0,0 +1,216 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"sbi-deployment/internal/utils"
+)
+
+// maxTagPages bounds how many tags/list pages are followed for one repository
+const maxTagPages = 50
+
+// Client reads from the Docker Registry HTTP API v2 of one registry host, using basic
+// auth or the bearer token flow the registry asks for
+type Client struct {
+	baseURL    string
+	username   string
+	password   string
+	token      string
+	httpClient *http.Client
+}
+
+// New creates a registry client for host (e.g. nexus.example.com:8443); empty credentials read anonymously.
+// Requests go through the configured HTTP_PROXY/HTTPS_PROXY/NO_PROXY, like the docker and helm commands.
+func New(host, username, password string) *Client {
+	return &Client{
+		baseURL:    "https://" + strings.TrimSuffix(host, "/"),
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{Timeout: 15 * time.Second, Transport: utils.ProxyTransport()},
+	}
+}
+
+// tagList is a /v2/<repository>/tags/list response
+type tagList struct {
+	Tags []string `json:"tags"`
+}
+
+// Tags lists every tag of repository, following the registry's pagination links
+func (c *Client) Tags(repository string) ([]string, error) {
+	var tags []string
+	next := c.baseURL + "/v2/" + repository + "/tags/list"
+	for page := 0; next != "" && page < maxTagPages; page++ {
+		body, link, err := c.get(next)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags of %s: %w", repository, err)
+		}
+		var list tagList
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, fmt.Errorf("failed to parse tags of %s: %w", repository, err)
+		}
+		tags = append(tags, list.Tags...)
+		if next, err = c.nextPage(next, link); err != nil {
+			return nil, err
+		}
+	}
+	// A truncated list could hide the newest tags, so it is an error rather than a partial answer
+	if next != "" {
+		return nil, fmt.Errorf("%s has more than %d pages of tags", repository, maxTagPages)
+	}
+	return tags, nil
+}
+
+// get fetches url, answering a bearer challenge once, and returns the body and Link header
+func (c *Client) get(url string) ([]byte, string, error) {
+	resp, err := c.do(url)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && c.token == "" {
+		if params, ok := ParseBearerChallenge(resp.Header.Get("WWW-Authenticate")); ok {
+			resp.Body.Close()
+			if c.token, err = c.fetchToken(params); err != nil {
+				return nil, "", err
+			}
+			if resp, err = c.do(url); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read registry response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("registry returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, resp.Header.Get("Link"), nil
+}
+
+// do sends an authenticated GET request
+func (c *Client) do(url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build registry request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return c.httpClient.Do(req)
+}
+
+// fetchToken exchanges the credentials for a bearer token at the challenge's realm
+func (c *Client) fetchToken(params map[string]string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Scheme == "" {
+		return "", fmt.Errorf("registry sent an invalid token realm %q", params["realm"])
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build token request: %w", err)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get registry token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry token request returned HTTP %d", resp.StatusCode)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to parse registry token: %w", err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	if token.AccessToken != "" {
+		return token.AccessToken, nil
+	}
+	return "", fmt.Errorf("registry token response has no token")
+}
+
+// nextPage resolves the rel="next" target of a Link header against the current page URL
+func (c *Client) nextPage(current, link string) (string, error) {
+	target, ok := nextLink(link)
+	if !ok {
+		return "", nil
+	}
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(target)
+	if err != nil {
+		return "", fmt.Errorf("registry sent an invalid Link header %q: %w", link, err)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// nextLink returns the URL of a Link header such as </v2/app/tags/list?last=1.2&n=100>; rel="next"
+func nextLink(link string) (string, bool) {
+	for _, part := range strings.Split(link, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(part), ";")
+		if ok && strings.Contains(strings.ReplaceAll(params, " ", ""), `rel="next"`) {
+			return strings.Trim(strings.TrimSpace(target), "<>"), true
+		}
+	}
+	return "", false
+}
+
+// ParseBearerChallenge parses a WWW-Authenticate header such as
+// Bearer realm="https://harbor/service/token",service="harbor-registry",scope="repository:app:pull"
+func ParseBearerChallenge(header string) (map[string]string, bool) {
+	scheme, rest, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	for rest = strings.TrimSpace(rest); rest != ""; {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if strings.HasPrefix(value, `"`) {
+			// Quoted values such as scopes may contain commas
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				return nil, false
+			}
+			params[key] = value[1 : end+1]
+			rest = value[end+2:]
+		} else {
+			value, rest, _ = strings.Cut(value, ",")
+			params[key] = strings.TrimSpace(value)
+		}
+		rest = strings.TrimLeft(rest, ", ")
+	}
+	return params, params["realm"] != ""
+}
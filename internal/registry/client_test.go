@@ -0,0 +1,139 @@
+package registry
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/utils"
+)
+
+// testClient returns a client for server that trusts its test certificate
+func testClient(server *httptest.Server, username, password string) *Client {
+	c := New(strings.TrimPrefix(server.URL, "https://"), username, password)
+	c.httpClient = server.Client()
+	return c
+}
+
+func TestTagsFollowsPages(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "ci" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("last") == "" {
+			w.Header().Set("Link", `</v2/team/web/tags/list?last=1.1.0&n=2>; rel="next"`)
+			fmt.Fprint(w, `{"tags": ["1.0.0", "1.1.0"]}`)
+			return
+		}
+		fmt.Fprint(w, `{"tags": ["1.2.0"]}`)
+	}))
+	defer server.Close()
+
+	tags, err := testClient(server, "ci", "secret").Tags("team/web")
+	if want := []string{"1.0.0", "1.1.0", "1.2.0"}; err != nil || !slices.Equal(tags, want) {
+		t.Errorf("Tags() = %q, %v, want %q", tags, err, want)
+	}
+}
+
+func TestTagsPageLimit(t *testing.T) {
+	pages := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		w.Header().Set("Link", fmt.Sprintf(`</v2/team/web/tags/list?last=%d&n=1>; rel="next"`, pages))
+		fmt.Fprintf(w, `{"tags": ["1.0.%d"]}`, pages)
+	}))
+	defer server.Close()
+
+	if _, err := testClient(server, "", "").Tags("team/web"); err == nil || !strings.Contains(err.Error(), "more than 50 pages") {
+		t.Errorf("Tags() error = %v, want the page limit reported", err)
+	}
+	if pages != maxTagPages {
+		t.Errorf("fetched %d pages, want %d", pages, maxTagPages)
+	}
+}
+
+func TestClientUsesConfiguredProxy(t *testing.T) {
+	t.Cleanup(func() { utils.SetProxyEnv("", "", "") })
+	utils.SetProxyEnv("", "http://proxy.example.com:3128", "harbor.example.com")
+
+	transport, ok := New("nexus.example.com", "", "").httpClient.Transport.(*http.Transport)
+	if !ok || transport.Proxy == nil {
+		t.Fatalf("transport = %T without a proxy function", New("nexus.example.com", "", "").httpClient.Transport)
+	}
+	for url, want := range map[string]string{
+		"https://nexus.example.com/v2/":  "http://proxy.example.com:3128",
+		"https://harbor.example.com/v2/": "",
+	} {
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		proxy, err := transport.Proxy(req)
+		if got := fmt.Sprint(proxy); err != nil || (want == "" && proxy != nil) || (want != "" && got != want) {
+			t.Errorf("proxy for %s = %v, %v, want %q", url, proxy, err, want)
+		}
+	}
+}
+
+func TestTagsBearerToken(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/service/token":
+			if r.URL.Query().Get("scope") != "repository:team/web:pull" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"access_token": "t0ken"}`)
+		case r.Header.Get("Authorization") == "Bearer t0ken":
+			fmt.Fprint(w, `{"tags": ["1.0.0"]}`)
+		default:
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/service/token",service="harbor-registry",scope="repository:team/web:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	tags, err := testClient(server, "ci", "secret").Tags("team/web")
+	if err != nil || !slices.Equal(tags, []string{"1.0.0"}) {
+		t.Errorf("Tags() = %q, %v", tags, err)
+	}
+}
+
+func TestTagsReportsHTTPErrors(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"errors": [{"code": "NAME_UNKNOWN"}]}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	if _, err := testClient(server, "", "").Tags("team/missing"); err == nil || !strings.Contains(err.Error(), "HTTP 404") || !strings.Contains(err.Error(), "NAME_UNKNOWN") {
+		t.Errorf("Tags() error = %v, want the HTTP status and body", err)
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	if got, ok := nextLink(`</v2/app/tags/list?last=1.2&n=100>; rel="next"`); !ok || got != "/v2/app/tags/list?last=1.2&n=100" {
+		t.Errorf("nextLink() = %q, %v", got, ok)
+	}
+	if _, ok := nextLink(`</v2/app/tags/list>; rel="prev"`); ok {
+		t.Errorf("nextLink(prev) found a next page")
+	}
+	if _, ok := nextLink(""); ok {
+		t.Errorf("nextLink(empty) found a next page")
+	}
+}
+
+func TestParseBearerChallenge(t *testing.T) {
+	params, ok := ParseBearerChallenge(`Bearer realm="https://harbor/service/token",service="harbor-registry",scope="repository:app:pull,push"`)
+	want := map[string]string{"realm": "https://harbor/service/token", "service": "harbor-registry", "scope": "repository:app:pull,push"}
+	if !ok || !maps.Equal(params, want) {
+		t.Errorf("ParseBearerChallenge() = %v, %v, want %v", params, ok, want)
+	}
+	for _, header := range []string{`Basic realm="Harbor"`, `Bearer service="harbor-registry"`, `Bearer realm="unterminated`, ""} {
+		if _, ok := ParseBearerChallenge(header); ok {
+			t.Errorf("ParseBearerChallenge(%q) accepted", header)
+		}
+	}
+}
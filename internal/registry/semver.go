@@ -0,0 +1,63 @@
+package registry
+
+import (
+	"strconv"
+	"strings"
+)
+
+// version is a parsed MAJOR.MINOR.PATCH tag, optionally v-prefixed
+type version struct {
+	numbers    [3]int
+	prerelease bool
+}
+
+// parseVersion parses a semver tag such as 1.2.3, v1.2.3-rc.1, or 1.2.3+build.5; build
+// metadata is ignored and anything else is not a version
+func parseVersion(tag string) (version, bool) {
+	core := strings.TrimPrefix(tag, "v")
+	core, _, _ = strings.Cut(core, "+")
+	core, pre, hasPre := strings.Cut(core, "-")
+	if hasPre && pre == "" {
+		return version{}, false
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return version{}, false
+	}
+	v := version{prerelease: hasPre}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || (len(part) > 1 && part[0] == '0') {
+			return version{}, false
+		}
+		v.numbers[i] = n
+	}
+	return v, true
+}
+
+// less reports whether v is an older release than other
+func (v version) less(other version) bool {
+	for i := range v.numbers {
+		if v.numbers[i] != other.numbers[i] {
+			return v.numbers[i] < other.numbers[i]
+		}
+	}
+	return false
+}
+
+// LatestSemver returns the highest semver release tag, skipping prereleases and tags such as
+// latest or commit SHAs that are not versions; ok is false when no tag is a release
+func LatestSemver(tags []string) (latest string, ok bool) {
+	var best version
+	for _, tag := range tags {
+		v, valid := parseVersion(tag)
+		if !valid || v.prerelease {
+			continue
+		}
+		if !ok || best.less(v) {
+			best, latest, ok = v, tag, true
+		}
+	}
+	return latest, ok
+}
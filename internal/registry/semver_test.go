@@ -0,0 +1,32 @@
+package registry
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	valid := map[string]version{
+		"1.2.3":         {numbers: [3]int{1, 2, 3}},
+		"v10.0.1":       {numbers: [3]int{10, 0, 1}},
+		"1.2.3-rc.1":    {numbers: [3]int{1, 2, 3}, prerelease: true},
+		"1.2.3+build.5": {numbers: [3]int{1, 2, 3}},
+	}
+	for tag, want := range valid {
+		if got, ok := parseVersion(tag); !ok || got != want {
+			t.Errorf("parseVersion(%q) = %+v, %v, want %+v", tag, got, ok, want)
+		}
+	}
+	for _, tag := range []string{"latest", "1.2", "1.2.3.4", "01.2.3", "1.2.3-", "abc1234", "1.-2.3"} {
+		if _, ok := parseVersion(tag); ok {
+			t.Errorf("parseVersion(%q) accepted a non-version", tag)
+		}
+	}
+}
+
+func TestLatestSemver(t *testing.T) {
+	tags := []string{"latest", "1.9.0", "v1.10.0", "1.10.1-rc.1", "abc1234", "1.2.30"}
+	if got, ok := LatestSemver(tags); !ok || got != "v1.10.0" {
+		t.Errorf("LatestSemver() = %q, %v, want v1.10.0", got, ok)
+	}
+	if got, ok := LatestSemver([]string{"latest", "2.0.0-beta"}); ok {
+		t.Errorf("LatestSemver() = %q, want no release", got)
+	}
+}
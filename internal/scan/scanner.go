@@ -0,0 +1,78 @@
+package scan
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"sbi-deployment/internal/logging"
+	"sbi-deployment/internal/utils"
+)
+
+// Scanner runs a vulnerability scanner against container images
+type Scanner struct {
+	command  string
+	severity string
+	log      *logging.Logger
+}
+
+// New creates a new Scanner, defaulting to trivy at CRITICAL severity
+func New(command, severity string) *Scanner {
+	if command == "" {
+		command = "trivy"
+	}
+	if severity == "" {
+		severity = "CRITICAL"
+	}
+	return &Scanner{
+		command:  command,
+		severity: severity,
+		log:      logging.Default(),
+	}
+}
+
+// WithLogger returns a copy of the scanner that writes its output through log
+func (s *Scanner) WithLogger(log *logging.Logger) *Scanner {
+	clone := *s
+	clone.log = log
+	return &clone
+}
+
+// Command returns the scanner executable name
+func (s *Scanner) Command() string {
+	return s.command
+}
+
+// Args builds the scanner arguments; a non-zero exit signals findings at the configured severity
+func (s *Scanner) Args(image string) []string {
+	return []string{"image", "--exit-code", "1", "--severity", s.severity, image}
+}
+
+// Check verifies that the scanner is installed
+func (s *Scanner) Check() error {
+	if _, err := exec.LookPath(s.command); err != nil {
+		return fmt.Errorf("image scanner %s not found in PATH; install it or disable SCAN_BEFORE_PUSH", s.command)
+	}
+	return nil
+}
+
+// Scan scans an image and returns an error if vulnerabilities at the configured severity are found
+func (s *Scanner) Scan(image string) error {
+	if err := s.Check(); err != nil {
+		return err
+	}
+
+	s.log.Infof("Scanning %s for %s vulnerabilities...", image, s.severity)
+	cmd := utils.Command(s.command, s.Args(image)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return fmt.Errorf("image %s has %s vulnerabilities, refusing to push", image, s.severity)
+		}
+		return fmt.Errorf("failed to run %s: %w", s.command, err)
+	}
+
+	s.log.Debugf("No %s vulnerabilities found in %s", s.severity, image)
+	return nil
+}
@@ -0,0 +1,46 @@
+package scan
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"sbi-deployment/internal/testutil"
+)
+
+func TestNewDefaults(t *testing.T) {
+	s := New("", "")
+	if s.Command() != "trivy" {
+		t.Errorf("Command() = %q, want trivy", s.Command())
+	}
+	want := []string{"image", "--exit-code", "1", "--severity", "CRITICAL", "harbor.example.com/web:v1"}
+	if got := s.Args("harbor.example.com/web:v1"); !slices.Equal(got, want) {
+		t.Errorf("Args() = %q, want %q", got, want)
+	}
+}
+
+func TestScan(t *testing.T) {
+	log := testutil.FakeTools(t, map[string]string{"grype-wrapper": "exit 0"})
+	if err := New("grype-wrapper", "HIGH,CRITICAL").Scan("nexus.example.com/web:v1"); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	want := []string{"grype-wrapper image --exit-code 1 --severity HIGH,CRITICAL nexus.example.com/web:v1"}
+	if got := testutil.Calls(t, log); !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestScanFindings(t *testing.T) {
+	testutil.FakeTools(t, map[string]string{"trivy": "exit 1"})
+	err := New("", "").Scan("nexus.example.com/web:v1")
+	if err == nil || !strings.Contains(err.Error(), "refusing to push") {
+		t.Errorf("Scan() error = %v, want findings to block the push", err)
+	}
+}
+
+func TestScanMissingScanner(t *testing.T) {
+	err := New("no-such-scanner", "").Scan("nexus.example.com/web:v1")
+	if err == nil || !strings.Contains(err.Error(), "not found in PATH") {
+		t.Errorf("Scan() error = %v, want a missing scanner error", err)
+	}
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/deploy"
+	"sbi-deployment/internal/logging"
+)
+
+// SecretHeader is the request header carrying the shared deploy secret
+const SecretHeader = "X-Deploy-Secret"
+
+// maxRequestBytes caps the POST /deploy body; a tag and an image name fit easily
+const maxRequestBytes = 64 << 10
+
+// readTimeout bounds reading a request, so a slow or stalled client cannot hold a connection open.
+// Responses have no write timeout: they are sent once the deploy finishes, which can take many minutes.
+const readTimeout = 30 * time.Second
+
+// Deployer is the subset of deploy.Deployer used by the server
+type Deployer interface {
+	Deploy(imageTag, imageName string, credentials *config.Credentials) (*deploy.Summary, error)
+}
+
+// Server exposes deployments over HTTP
+type Server struct {
+	deployer    Deployer
+	credentials *config.Credentials
+	secret      string
+	log         *logging.Logger
+	mu          sync.Mutex
+}
+
+// deployRequest is the JSON body accepted by POST /deploy
+type deployRequest struct {
+	Tag   string `json:"tag"`
+	Image string `json:"image"`
+}
+
+// errorResponse is the JSON body returned when a request fails
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// New creates a new deploy server
+func New(deployer Deployer, credentials *config.Credentials, secret string) *Server {
+	return &Server{
+		deployer:    deployer,
+		credentials: credentials,
+		secret:      secret,
+		log:         logging.Default(),
+	}
+}
+
+// WithLogger returns a copy of the server that writes its output through log
+func (s *Server) WithLogger(log *logging.Logger) *Server {
+	return &Server{deployer: s.deployer, credentials: s.credentials, secret: s.secret, log: log}
+}
+
+// Handler returns the HTTP handler for the server routes
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/deploy", s.handleDeploy)
+	return mux
+}
+
+// ListenAndServe starts the HTTP server on the given address
+func (s *Server) ListenAndServe(addr string) error {
+	if s.secret == "" {
+		return fmt.Errorf("SERVER_SECRET must be set to run the deploy server")
+	}
+	s.log.Infof("Deploy server listening on %s", addr)
+	return s.httpServer(addr).ListenAndServe()
+}
+
+// httpServer returns the HTTP server for addr, with timeouts for reading requests
+func (s *Server) httpServer(addr string) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       readTimeout,
+		IdleTimeout:       2 * time.Minute,
+	}
+}
+
+// handleDeploy runs a deployment for a POST /deploy request
+func (s *Server) handleDeploy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get(SecretHeader)), []byte(s.secret)) != 1 {
+		s.writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "invalid or missing deploy secret"})
+		return
+	}
+
+	var req deployRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			s.writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{Error: fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit)})
+			return
+		}
+		s.writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+	if req.Tag == "" {
+		s.writeJSON(w, http.StatusBadRequest, errorResponse{Error: "tag is required"})
+		return
+	}
+
+	// Deploys are serialized so concurrent requests queue behind each other
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.log.Infof("Received deploy request for image %q tag %s", req.Image, req.Tag)
+	summary, err := s.deployer.Deploy(req.Tag, req.Image, s.credentials)
+	if err != nil {
+		s.log.Errorf("Deploy request failed: %v", err)
+		s.writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, summary)
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.log.Warnf("Failed to write response: %v", err)
+	}
+}
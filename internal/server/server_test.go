@@ -0,0 +1,164 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"sbi-deployment/internal/config"
+	"sbi-deployment/internal/deploy"
+)
+
+// fakeDeployer records the last deploy request
+type fakeDeployer struct {
+	tag, image string
+	err        error
+}
+
+func (f *fakeDeployer) Deploy(imageTag, imageName string, _ *config.Credentials) (*deploy.Summary, error) {
+	f.tag, f.image = imageTag, imageName
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &deploy.Summary{ImageName: imageName, TargetTag: imageTag}, nil
+}
+
+func post(t *testing.T, s *Server, secret, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(body))
+	if secret != "" {
+		req.Header.Set(SecretHeader, secret)
+	}
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandleDeploy(t *testing.T) {
+	fake := &fakeDeployer{}
+	rec := post(t, New(fake, &config.Credentials{}, "s3cret"), "s3cret", `{"tag":"v1","image":"web"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if fake.tag != "v1" || fake.image != "web" {
+		t.Errorf("Deploy called with tag %q image %q", fake.tag, fake.image)
+	}
+	var summary deploy.Summary
+	if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil || summary.TargetTag != "v1" {
+		t.Errorf("response = %s (%v), want the summary", rec.Body, err)
+	}
+}
+
+func TestHandleDeployRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		body   string
+		status int
+	}{
+		{name: "missing secret", body: `{"tag":"v1"}`, status: http.StatusUnauthorized},
+		{name: "wrong secret", secret: "nope", body: `{"tag":"v1"}`, status: http.StatusUnauthorized},
+		{name: "missing tag", secret: "s3cret", body: `{"image":"web"}`, status: http.StatusBadRequest},
+		{name: "unknown field", secret: "s3cret", body: `{"tag":"v1","namespace":"prod"}`, status: http.StatusBadRequest},
+		{name: "too large", secret: "s3cret", body: `{"tag":"` + strings.Repeat("a", maxRequestBytes) + `"}`, status: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeDeployer{}
+			rec := post(t, New(fake, &config.Credentials{}, "s3cret"), tt.secret, tt.body)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if fake.tag != "" {
+				t.Errorf("Deploy was called for a rejected request")
+			}
+		})
+	}
+}
+
+func TestHandleDeployMethod(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(&fakeDeployer{}, &config.Credentials{}, "s3cret").Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/deploy", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405", rec.Code)
+	}
+}
+
+func TestHandleDeployFailure(t *testing.T) {
+	fake := &fakeDeployer{err: errors.New("helm upgrade failed")}
+	rec := post(t, New(fake, &config.Credentials{}, "s3cret").WithLogger(nil), "s3cret", `{"tag":"v1"}`)
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "helm upgrade failed") {
+		t.Errorf("status = %d body %s, want 500 with the deploy error", rec.Code, rec.Body)
+	}
+}
+
+// blockingDeployer holds each deploy until release is closed and tracks how many run at once
+type blockingDeployer struct {
+	started chan string
+	release chan struct{}
+	mu      sync.Mutex
+	running int
+	maxSeen int
+}
+
+func (b *blockingDeployer) Deploy(imageTag, imageName string, _ *config.Credentials) (*deploy.Summary, error) {
+	b.mu.Lock()
+	b.running++
+	b.maxSeen = max(b.maxSeen, b.running)
+	b.mu.Unlock()
+	b.started <- imageTag
+	<-b.release
+	b.mu.Lock()
+	b.running--
+	b.mu.Unlock()
+	return &deploy.Summary{ImageName: imageName, TargetTag: imageTag}, nil
+}
+
+func TestHandleDeployQueuesConcurrentRequests(t *testing.T) {
+	fake := &blockingDeployer{started: make(chan string, 2), release: make(chan struct{})}
+	s := New(fake, &config.Credentials{}, "s3cret")
+	codes := make(chan int, 2)
+	send := func(tag string) {
+		go func() { codes <- post(t, s, "s3cret", `{"tag":"`+tag+`"}`).Code }()
+	}
+
+	send("v1")
+	if tag := <-fake.started; tag != "v1" {
+		t.Fatalf("first deploy tag = %q, want v1", tag)
+	}
+	send("v2")
+	select {
+	case tag := <-fake.started:
+		t.Fatalf("deploy of %s started while v1 was still running", tag)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(fake.release)
+	if tag := <-fake.started; tag != "v2" {
+		t.Errorf("second deploy tag = %q, want v2", tag)
+	}
+	for range 2 {
+		if code := <-codes; code != http.StatusOK {
+			t.Errorf("status = %d, want 200", code)
+		}
+	}
+	if fake.maxSeen != 1 {
+		t.Errorf("%d deploys ran at once, want 1", fake.maxSeen)
+	}
+}
+
+func TestHTTPServerTimeouts(t *testing.T) {
+	srv := New(&fakeDeployer{}, &config.Credentials{}, "s3cret").httpServer(":8080")
+	if srv.Addr != ":8080" || srv.ReadHeaderTimeout <= 0 || srv.ReadTimeout != readTimeout {
+		t.Errorf("httpServer() addr %q, header timeout %v, read timeout %v; want :8080 with read timeouts set",
+			srv.Addr, srv.ReadHeaderTimeout, srv.ReadTimeout)
+	}
+	if srv.WriteTimeout != 0 {
+		t.Errorf("httpServer() write timeout = %v, want none so long deploys can respond", srv.WriteTimeout)
+	}
+}
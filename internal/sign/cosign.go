@@ -0,0 +1,71 @@
+package sign
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"sbi-deployment/internal/logging"
+	"sbi-deployment/internal/utils"
+)
+
+// Signer signs pushed images with cosign
+type Signer struct {
+	keyPath string
+	log     *logging.Logger
+}
+
+// New creates a new Signer; an empty key path selects keyless (OIDC) signing
+func New(keyPath string) *Signer {
+	return &Signer{
+		keyPath: keyPath,
+		log:     logging.Default(),
+	}
+}
+
+// WithLogger returns a copy of the signer that writes its output through log
+func (s *Signer) WithLogger(log *logging.Logger) *Signer {
+	clone := *s
+	clone.log = log
+	return &clone
+}
+
+// Args builds the cosign sign arguments for an image reference
+func (s *Signer) Args(image string) []string {
+	args := []string{"sign", "--yes"}
+	if s.keyPath != "" {
+		args = append(args, "--key", s.keyPath)
+	}
+	return append(args, image)
+}
+
+// Check verifies that cosign is installed
+func (s *Signer) Check() error {
+	if _, err := exec.LookPath("cosign"); err != nil {
+		return fmt.Errorf("cosign not found in PATH; install it or disable SIGN_IMAGE")
+	}
+	return nil
+}
+
+// Sign signs an image reference, preferably pinned by digest
+func (s *Signer) Sign(image string) error {
+	if err := s.Check(); err != nil {
+		return err
+	}
+
+	if s.keyPath != "" {
+		s.log.Infof("Signing %s with key %s...", image, s.keyPath)
+	} else {
+		s.log.Infof("Signing %s keylessly...", image)
+	}
+
+	cmd := utils.Command("cosign", s.Args(image)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to sign image %s: %w", image, err)
+	}
+
+	s.log.Debugf("Successfully signed %s", image)
+	return nil
+}
@@ -0,0 +1,53 @@
+package sign
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyPath string
+		want    []string
+	}{
+		{"keyless", "", []string{"sign", "--yes", "harbor.example.com/web@sha256:2222"}},
+		{"key", "cosign.key", []string{"sign", "--yes", "--key", "cosign.key", "harbor.example.com/web@sha256:2222"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New(tt.keyPath).Args("harbor.example.com/web@sha256:2222"); !slices.Equal(got, tt.want) {
+				t.Errorf("Args() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "calls.log")
+	script := "#!/bin/sh\necho \"cosign $*\" >> " + log + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "cosign"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	if err := New("cosign.key").Sign("harbor.example.com/web@sha256:2222"); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "cosign sign --yes --key cosign.key harbor.example.com/web@sha256:2222" {
+		t.Errorf("cosign called with %q", got)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if err := New("").Sign("harbor.example.com/web:v1"); err == nil || !strings.Contains(err.Error(), "SIGN_IMAGE") {
+		t.Errorf("Sign() error = %v, want a missing cosign error", err)
+	}
+}
@@ -0,0 +1,38 @@
+// Package testutil holds helpers shared by the package tests
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// FakeTools puts a shell script named after each key of scripts first on PATH; every call is
+// recorded as "<tool> <args>" in the returned log file before the script body runs
+func FakeTools(t *testing.T, scripts map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "calls.log")
+	for name, body := range scripts {
+		script := "#!/bin/sh\necho \"" + name + " $*\" >> " + log + "\n" + body + "\n"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return log
+}
+
+// Calls returns the tool invocations recorded by FakeTools
+func Calls(t *testing.T, log string) []string {
+	t.Helper()
+	data, err := os.ReadFile(log)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
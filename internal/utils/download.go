@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"sbi-deployment/internal/logging"
+)
+
+// DownloadOptions bounds how long each download attempt may take and how often it is retried
+type DownloadOptions struct {
+	Timeout time.Duration   // per attempt; 0 waits indefinitely
+	Retries int             // extra attempts after the first failure
+	Command string          // downloader binary, curl when empty
+	Log     *logging.Logger // retry messages; the default logger when nil
+}
+
+// DownloadArgs builds the curl arguments that fetch url into dest, failing on HTTP errors
+func DownloadArgs(url, dest string) []string {
+	return []string{"-fsSL", "-o", dest, url}
+}
+
+// Download fetches url into dest, killing attempts that exceed opts.Timeout and retrying failures
+func Download(url, dest string, opts DownloadOptions) error {
+	name := opts.Command
+	if name == "" {
+		name = "curl"
+	}
+	attempts := opts.Retries + 1
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = downloadOnce(name, url, dest, opts.Timeout); err == nil {
+			return nil
+		}
+		if attempt < attempts {
+			opts.Log.Warnf("Download of %s failed (attempt %d/%d): %v, retrying...", url, attempt, attempts, err)
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+	}
+	return fmt.Errorf("download of %s failed after %d attempts: %w", url, attempts, err)
+}
+
+// DownloadTemp fetches url into a new file in the temp directory, named after pattern as in
+// os.CreateTemp, so concurrent setups never share a path; the caller removes the file
+func DownloadTemp(url, pattern string, opts DownloadOptions) (string, error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	path := file.Name()
+	file.Close()
+
+	if err := Download(url, path, opts); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// downloadOnce runs a single download attempt under timeout
+func downloadOnce(name, url, dest string, timeout time.Duration) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := CommandContext(ctx, name, DownloadArgs(url, dest)...)
+	// Don't wait on output pipes held open by children of a killed downloader
+	cmd.WaitDelay = time.Second
+	output, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
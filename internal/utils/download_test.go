@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDownloader writes a downloader script that runs body with the curl arguments and returns its path
+func fakeDownloader(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "curl")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDownloadRetries(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "failed-once")
+	// $3 is the destination from DownloadArgs: -fsSL -o dest url
+	downloader := fakeDownloader(t, `[ -f `+marker+` ] || { touch `+marker+`; echo 'curl: (56) connection reset' >&2; exit 56; }
+echo binary > "$3"`)
+	dest := filepath.Join(t.TempDir(), "helm.tar.gz")
+	if err := Download("https://get.helm.sh/helm.tar.gz", dest, DownloadOptions{Retries: 1, Command: downloader}); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if data, err := os.ReadFile(dest); err != nil || string(data) != "binary\n" {
+		t.Errorf("downloaded %q, %v", data, err)
+	}
+}
+
+func TestDownloadGivesUp(t *testing.T) {
+	downloader := fakeDownloader(t, "echo 'curl: (22) 404' >&2; exit 22")
+	err := Download("https://get.helm.sh/missing", filepath.Join(t.TempDir(), "out"), DownloadOptions{Command: downloader})
+	if err == nil || !strings.Contains(err.Error(), "failed after 1 attempts") || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Download() error = %v, want the curl output after one attempt", err)
+	}
+}
+
+func TestDownloadTimeout(t *testing.T) {
+	downloader := fakeDownloader(t, "sleep 5")
+	start := time.Now()
+	err := Download("https://get.helm.sh/slow", filepath.Join(t.TempDir(), "out"), DownloadOptions{Timeout: 100 * time.Millisecond, Command: downloader})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("Download() error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Download() took %s, want the attempt killed", elapsed)
+	}
+}
+
+func TestDownloadTemp(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	downloader := fakeDownloader(t, `echo binary > "$3"`)
+	opts := DownloadOptions{Command: downloader}
+	first, err := DownloadTemp("https://dl.k8s.io/kubectl", "kubectl-*", opts)
+	if err != nil {
+		t.Fatalf("DownloadTemp() error = %v", err)
+	}
+	second, err := DownloadTemp("https://dl.k8s.io/kubectl", "kubectl-*", opts)
+	if err != nil {
+		t.Fatalf("DownloadTemp() error = %v", err)
+	}
+	if first == second {
+		t.Errorf("DownloadTemp() returned %s twice, want unique paths", first)
+	}
+	if !strings.HasPrefix(filepath.Base(first), "kubectl-") {
+		t.Errorf("DownloadTemp() = %s, want it named after the pattern", first)
+	}
+	if data, err := os.ReadFile(first); err != nil || string(data) != "binary\n" {
+		t.Errorf("downloaded %q, %v", data, err)
+	}
+}
+
+func TestDownloadTempRemovesFileOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	downloader := fakeDownloader(t, "exit 22")
+	if path, err := DownloadTemp("https://dl.k8s.io/missing", "kubectl-*", DownloadOptions{Command: downloader}); err == nil {
+		t.Fatalf("DownloadTemp() = %s, want an error", path)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("temp dir holds %d entries after a failed download, want none", len(entries))
+	}
+}
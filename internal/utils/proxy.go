@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// proxySettings holds the configured HTTP_PROXY, HTTPS_PROXY and NO_PROXY for in-process HTTP clients
+var proxySettings struct {
+	http, https, noProxy string
+}
+
+// proxyValue returns the configured value, or the process environment's in either casing
+func proxyValue(configured, name string) string {
+	if configured != "" {
+		return configured
+	}
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return os.Getenv(strings.ToLower(name))
+}
+
+// Proxy is an http.Transport Proxy function that routes requests through the same proxies
+// spawned commands get: HTTPS_PROXY for https URLs, HTTP_PROXY otherwise, none for NO_PROXY
+// hosts and loopback addresses. Configured values take precedence over the environment.
+func Proxy(req *http.Request) (*url.URL, error) {
+	proxy := proxyValue(proxySettings.http, "HTTP_PROXY")
+	if req.URL.Scheme == "https" {
+		proxy = proxyValue(proxySettings.https, "HTTPS_PROXY")
+	}
+	if proxy == "" || bypassProxy(req.URL, proxyValue(proxySettings.noProxy, "NO_PROXY")) {
+		return nil, nil
+	}
+	// Like curl, a proxy without a scheme is an http:// proxy
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	return url.Parse(proxy)
+}
+
+// ProxyTransport returns a copy of http.DefaultTransport whose requests go through Proxy
+func ProxyTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = Proxy
+	return transport
+}
+
+// bypassProxy reports whether NO_PROXY (comma-separated hosts, .domains, host:port pairs, CIDRs,
+// or *) or a loopback address exempts target from the proxy
+func bypassProxy(target *url.URL, noProxy string) bool {
+	host, port := strings.ToLower(target.Hostname()), target.Port()
+	ip := net.ParseIP(host)
+	if host == "localhost" || ip != nil && ip.IsLoopback() {
+		return true
+	}
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+			continue
+		case entry == "*":
+			return true
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			if p != port {
+				continue
+			}
+			entry = h
+		}
+		entry = strings.TrimPrefix(entry, ".")
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
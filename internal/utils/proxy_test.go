@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestProxy(t *testing.T) {
+	t.Cleanup(func() { SetProxyEnv("", "", "") })
+	for _, name := range []string{"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy", "NO_PROXY", "no_proxy"} {
+		t.Setenv(name, "")
+	}
+
+	SetProxyEnv("http://proxy.example.com:3128", "secure-proxy.example.com:3129", "internal.example.com,.svc,10.0.0.0/8,registry.example.com:5000")
+	for url, want := range map[string]string{
+		"http://nexus.example.com/v2/":          "http://proxy.example.com:3128",
+		"https://nexus.example.com/v2/":         "http://secure-proxy.example.com:3129",
+		"https://internal.example.com/v2/":      "",
+		"https://harbor.internal.example.com/":  "",
+		"https://web.prod.svc/":                 "",
+		"https://10.1.2.3:8443/v2/":             "",
+		"https://registry.example.com:5000/v2/": "",
+		"https://registry.example.com:8443/v2/": "http://secure-proxy.example.com:3129",
+		"https://127.0.0.1:8080/v2/":            "",
+		"https://notinternal.example.com.evil/": "http://secure-proxy.example.com:3129",
+	} {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proxy, err := Proxy(req)
+		got := ""
+		if proxy != nil {
+			got = proxy.String()
+		}
+		if err != nil || got != want {
+			t.Errorf("Proxy(%s) = %q, %v, want %q", url, got, err, want)
+		}
+	}
+
+	// Without configured proxies the environment's apply, as they do for spawned commands
+	SetProxyEnv("", "", "")
+	t.Setenv("https_proxy", "http://env-proxy.example.com:3128")
+	req, _ := http.NewRequest(http.MethodGet, "https://nexus.example.com/v2/", nil)
+	if proxy, err := Proxy(req); err != nil || proxy == nil || proxy.Host != "env-proxy.example.com:3128" {
+		t.Errorf("Proxy() = %v, %v, want the environment's proxy", proxy, err)
+	}
+}
@@ -1,11 +1,54 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sbi-deployment/internal/logging"
 )
 
+// proxyEnv holds the proxy variables injected into every spawned command
+var proxyEnv []string
+
+// SetProxyEnv configures the proxy variables passed to commands created with Command and used by Proxy
+func SetProxyEnv(httpProxy, httpsProxy, noProxy string) {
+	proxySettings.http, proxySettings.https, proxySettings.noProxy = httpProxy, httpsProxy, noProxy
+	proxyEnv = nil
+	for key, value := range map[string]string{
+		"HTTP_PROXY":  httpProxy,
+		"HTTPS_PROXY": httpsProxy,
+		"NO_PROXY":    noProxy,
+	} {
+		if value != "" {
+			// Tools disagree on casing, so set both forms
+			proxyEnv = append(proxyEnv, key+"="+value, strings.ToLower(key)+"="+value)
+		}
+	}
+}
+
+// Command creates a command that inherits the process environment plus any configured proxy variables
+func Command(name string, args ...string) *exec.Cmd {
+	return withProxyEnv(exec.Command(name, args...))
+}
+
+// CommandContext is Command with a context that kills the process when it is done
+func CommandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	return withProxyEnv(exec.CommandContext(ctx, name, args...))
+}
+
+// withProxyEnv adds the configured proxy variables to cmd's environment
+func withProxyEnv(cmd *exec.Cmd) *exec.Cmd {
+	if len(proxyEnv) > 0 {
+		cmd.Env = append(os.Environ(), proxyEnv...)
+	}
+	return cmd
+}
+
 // CheckCommand verifies if a command is available in the system
 func CheckCommand(command string) error {
 	_, err := exec.LookPath(command)
@@ -17,7 +60,7 @@ func CheckCommand(command string) error {
 
 // RunCommand executes a shell command and returns the output
 func RunCommand(command string, args ...string) (string, error) {
-	cmd := exec.Command(command, args...)
+	cmd := Command(command, args...)
 	output, err := cmd.CombinedOutput()
 	return string(output), err
 }
@@ -37,9 +80,9 @@ func CreateDir(dir string) error {
 }
 
 // InstallPackages installs required system packages
-func InstallPackages(packages []string) error {
-	fmt.Println("Installing required packages...")
-	
+func InstallPackages(packages []string, log *logging.Logger) error {
+	log.Infof("Installing required packages...")
+
 	// Update package list
 	if err := runCommandWithSudo("apt-get", "update"); err != nil {
 		return fmt.Errorf("failed to update package list: %w", err)
@@ -55,25 +98,31 @@ func InstallPackages(packages []string) error {
 }
 
 // InstallHelm installs Helm binary
-func InstallHelm() error {
-	fmt.Println("Installing Helm...")
-	
+func InstallHelm(dl DownloadOptions) error {
+	dl.Log.Infof("Installing Helm...")
+
 	// Download Helm
-	downloadCmd := exec.Command("curl", "-fsSL", "-o", "/tmp/helm.tar.gz", 
-		"https://get.helm.sh/helm-v3.12.0-linux-amd64.tar.gz")
-	if err := downloadCmd.Run(); err != nil {
+	archive, err := DownloadTemp("https://get.helm.sh/helm-v3.12.0-linux-amd64.tar.gz", "helm-*.tar.gz", dl)
+	if err != nil {
 		return fmt.Errorf("failed to download Helm: %w", err)
 	}
+	defer os.Remove(archive)
+
+	// Extract into a private directory
+	extractDir, err := os.MkdirTemp("", "helm-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(extractDir)
 
-	// Extract and install
-	extractCmd := exec.Command("tar", "-zxvf", "/tmp/helm.tar.gz", 
-		"-C", "/tmp", "--strip-components=1", "linux-amd64/helm")
+	extractCmd := Command("tar", "-zxvf", archive,
+		"-C", extractDir, "--strip-components=1", "linux-amd64/helm")
 	if err := extractCmd.Run(); err != nil {
 		return fmt.Errorf("failed to extract Helm: %w", err)
 	}
 
 	// Move to /usr/local/bin
-	if err := runCommandWithSudo("mv", "/tmp/helm", "/usr/local/bin/helm"); err != nil {
+	if err := runCommandWithSudo("mv", filepath.Join(extractDir, "helm"), "/usr/local/bin/helm"); err != nil {
 		return fmt.Errorf("failed to install Helm: %w", err)
 	}
 
@@ -86,20 +135,19 @@ func InstallHelm() error {
 }
 
 // InstallKubectl installs kubectl binary
-func InstallKubectl() error {
-	fmt.Println("Installing kubectl...")
-	
+func InstallKubectl(dl DownloadOptions) error {
+	dl.Log.Infof("Installing kubectl...")
+
 	// Download kubectl
-	downloadCmd := exec.Command("curl", "-LO", 
-		"https://dl.k8s.io/release/v1.27.0/bin/linux/amd64/kubectl")
-	downloadCmd.Dir = "/tmp"
-	if err := downloadCmd.Run(); err != nil {
+	binary, err := DownloadTemp("https://dl.k8s.io/release/v1.27.0/bin/linux/amd64/kubectl", "kubectl-*", dl)
+	if err != nil {
 		return fmt.Errorf("failed to download kubectl: %w", err)
 	}
+	defer os.Remove(binary)
 
 	// Install kubectl
-	if err := runCommandWithSudo("install", "-o", "root", "-g", "root", "-m", "0755", 
-		"/tmp/kubectl", "/usr/local/bin/kubectl"); err != nil {
+	if err := runCommandWithSudo("install", "-o", "root", "-g", "root", "-m", "0755",
+		binary, "/usr/local/bin/kubectl"); err != nil {
 		return fmt.Errorf("failed to install kubectl: %w", err)
 	}
 
@@ -107,20 +155,20 @@ func InstallKubectl() error {
 }
 
 // AddUserToDockerGroup adds the current user to the docker group
-func AddUserToDockerGroup() error {
+func AddUserToDockerGroup(log *logging.Logger) error {
 	user := os.Getenv("USER")
 	if user == "" {
 		user = "runner" // Default for CI environments
 	}
 
-	fmt.Printf("Adding user %s to docker group...\n", user)
+	log.Infof("Adding user %s to docker group...", user)
 	return runCommandWithSudo("usermod", "-aG", "docker", user)
 }
 
 // runCommandWithSudo runs a command with sudo privileges
 func runCommandWithSudo(command string, args ...string) error {
 	fullArgs := append([]string{command}, args...)
-	cmd := exec.Command("sudo", fullArgs...)
+	cmd := Command("sudo", fullArgs...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
@@ -140,4 +188,88 @@ func GetCurrentUser() string {
 		return user
 	}
 	return "unknown"
-}
\ No newline at end of file
+}
+
+// CurrentGitBranch returns the checked-out git branch, preferring CI-provided branch variables
+func CurrentGitBranch() (string, error) {
+	for _, key := range []string{"GITHUB_HEAD_REF", "GITHUB_REF_NAME", "CI_COMMIT_REF_NAME", "BRANCH_NAME"} {
+		if branch := os.Getenv(key); branch != "" {
+			return branch, nil
+		}
+	}
+
+	output, err := RunCommand("git", "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to determine git branch: %w", err)
+	}
+	branch := strings.TrimSpace(output)
+	if branch == "" || branch == "HEAD" {
+		return "", fmt.Errorf("not on a git branch (detached HEAD)")
+	}
+	return branch, nil
+}
+
+// PreviewNamespace derives a DNS-1123 label of the form preview-<branch> from a branch name
+func PreviewNamespace(branch string) string {
+	var b strings.Builder
+	lastDash := false
+	for _, r := range strings.ToLower(branch) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			lastDash = false
+		} else if !lastDash {
+			b.WriteRune('-')
+			lastDash = true
+		}
+	}
+
+	namespace := "preview-" + strings.Trim(b.String(), "-")
+	if len(namespace) > 63 {
+		namespace = namespace[:63]
+	}
+	return strings.TrimRight(namespace, "-")
+}
+
+// dnsLabelPattern matches an RFC 1123 label such as a Kubernetes namespace
+var dnsLabelPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// IsDNSLabel reports whether name is a valid RFC 1123 DNS label of at most 63 characters
+func IsDNSLabel(name string) bool {
+	return len(name) <= 63 && dnsLabelPattern.MatchString(name)
+}
+
+// HumanSize formats a byte count as a human-readable MB/GB string
+func HumanSize(bytes int64) string {
+	const (
+		mb = 1024 * 1024
+		gb = 1024 * mb
+	)
+	if bytes >= gb {
+		return fmt.Sprintf("%.2f GB", float64(bytes)/gb)
+	}
+	return fmt.Sprintf("%.1f MB", float64(bytes)/mb)
+}
+
+// RenderTemplate replaces {{ key }} placeholders in text with the corresponding values
+func RenderTemplate(text string, vars map[string]string) string {
+	for key, value := range vars {
+		text = strings.ReplaceAll(text, "{{ "+key+" }}", value)
+		text = strings.ReplaceAll(text, "{{"+key+"}}", value)
+	}
+	return text
+}
+
+// GitShortSHA returns the abbreviated commit SHA, preferring CI-provided commit variables
+func GitShortSHA() (string, error) {
+	for _, key := range []string{"GITHUB_SHA", "CI_COMMIT_SHA", "GIT_COMMIT"} {
+		if sha := os.Getenv(key); len(sha) >= 7 {
+			return sha[:7], nil
+		}
+	}
+
+	output, err := RunCommand("git", "rev-parse", "--short=7", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to determine git commit: %w", err)
+	}
+	return strings.TrimSpace(output), nil
+}
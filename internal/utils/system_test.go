@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPreviewNamespace(t *testing.T) {
+	tests := []struct {
+		branch string
+		want   string
+	}{
+		{"feature/JIRA-123_login", "preview-feature-jira-123-login"},
+		{"main", "preview-main"},
+		{"--fix--", "preview-fix"},
+		{strings.Repeat("a", 70), "preview-" + strings.Repeat("a", 55)},
+		{"feature/" + strings.Repeat("b", 46) + "/x", "preview-feature-" + strings.Repeat("b", 46)},
+	}
+	for _, tt := range tests {
+		got := PreviewNamespace(tt.branch)
+		if got != tt.want {
+			t.Errorf("PreviewNamespace(%q) = %q, want %q", tt.branch, got, tt.want)
+		}
+		if !IsDNSLabel(got) {
+			t.Errorf("PreviewNamespace(%q) = %q is not a DNS label", tt.branch, got)
+		}
+	}
+}
+
+func TestCurrentGitBranchPrefersCIVariables(t *testing.T) {
+	for _, key := range []string{"GITHUB_HEAD_REF", "GITHUB_REF_NAME", "CI_COMMIT_REF_NAME", "BRANCH_NAME"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("CI_COMMIT_REF_NAME", "feature/gitlab")
+	t.Setenv("BRANCH_NAME", "feature/jenkins")
+
+	got, err := CurrentGitBranch()
+	if err != nil || got != "feature/gitlab" {
+		t.Errorf("CurrentGitBranch() = %q, %v; want feature/gitlab", got, err)
+	}
+
+	t.Setenv("GITHUB_HEAD_REF", "feature/pr")
+	if got, _ := CurrentGitBranch(); got != "feature/pr" {
+		t.Errorf("CurrentGitBranch() = %q, want the pull request head branch", got)
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, "0.0 MB"},
+		{52428800, "50.0 MB"},
+		{1610612736, "1.50 GB"},
+	}
+	for _, tt := range tests {
+		if got := HumanSize(tt.bytes); got != tt.want {
+			t.Errorf("HumanSize(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}
+
+func TestCommandProxyEnv(t *testing.T) {
+	t.Cleanup(func() { SetProxyEnv("", "", "") })
+	t.Setenv("HTTPS_PROXY", "")
+
+	SetProxyEnv("http://proxy.example.com:3128", "", "localhost,.svc")
+	output, err := Command("sh", "-c", `printf '%s|%s|%s|%s' "$HTTP_PROXY" "$http_proxy" "$NO_PROXY" "$HTTPS_PROXY"`).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "http://proxy.example.com:3128|http://proxy.example.com:3128|localhost,.svc|"; string(output) != want {
+		t.Errorf("proxy env = %q, want %q", output, want)
+	}
+
+	SetProxyEnv("", "", "")
+	if cmd := Command("true"); cmd.Env != nil {
+		t.Errorf("Command() set an environment without proxy settings: %q", cmd.Env)
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	vars := map[string]string{"git_sha": "abc1234"}
+	tests := map[string]string{
+		"-{{ git_sha }}":    "-abc1234",
+		"-{{git_sha}}":      "-abc1234",
+		"-{{ unknown }}":    "-{{ unknown }}",
+		"-rc":               "-rc",
+		"{{ git_sha }}-hot": "abc1234-hot",
+	}
+	for text, want := range tests {
+		if got := RenderTemplate(text, vars); got != want {
+			t.Errorf("RenderTemplate(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestGitShortSHAPrefersCIVariables(t *testing.T) {
+	t.Setenv("GITHUB_SHA", "")
+	t.Setenv("CI_COMMIT_SHA", "0123456789abcdef")
+	t.Setenv("GIT_COMMIT", "fedcba9876543210")
+	if got, err := GitShortSHA(); err != nil || got != "0123456" {
+		t.Errorf("GitShortSHA() = %q, %v; want the CI_COMMIT_SHA prefix", got, err)
+	}
+}
+
+func TestIsDNSLabel(t *testing.T) {
+	valid := []string{"prod", "web-prod", "a", "team1-preview-42", strings.Repeat("a", 63)}
+	invalid := []string{"", "Prod", "web_prod", "-web", "web-", "web.prod", "{{ env }}-web", strings.Repeat("a", 64)}
+	for _, name := range valid {
+		if !IsDNSLabel(name) {
+			t.Errorf("IsDNSLabel(%q) = false", name)
+		}
+	}
+	for _, name := range invalid {
+		if IsDNSLabel(name) {
+			t.Errorf("IsDNSLabel(%q) = true", name)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client reads secrets from the Vault HTTP API using a token
+type Client struct {
+	addr       string
+	token      string
+	httpClient *http.Client
+}
+
+// New creates a new Vault client for the given address and token
+func New(addr, token string) *Client {
+	return &Client{
+		addr:       strings.TrimSuffix(addr, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// secretResponse is the subset of a Vault read response we use.
+// KV v2 nests the secret under data.data; KV v1 returns it directly under data.
+type secretResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []string               `json:"errors"`
+}
+
+// ReadSecret returns the string fields of the secret at path (e.g. secret/data/sbi/registry)
+func (c *Client) ReadSecret(path string) (map[string]string, error) {
+	req, err := http.NewRequest(http.MethodGet, c.addr+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Vault secret %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Vault response: %w", err)
+	}
+	secret, err := parseSecret(body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned HTTP %d for %s: %s", resp.StatusCode, path, strings.Join(secret.Errors, "; "))
+	}
+	return secret.fields(), nil
+}
+
+// parseSecret decodes a Vault read response body
+func parseSecret(body []byte) (*secretResponse, error) {
+	var secret secretResponse
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("failed to parse Vault response: %w", err)
+	}
+	return &secret, nil
+}
+
+// fields flattens the secret's string values, unwrapping the KV v2 envelope
+func (s *secretResponse) fields() map[string]string {
+	data := s.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nested
+		}
+	}
+
+	fields := make(map[string]string, len(data))
+	for key, value := range data {
+		if str, ok := value.(string); ok {
+			fields[key] = str
+		}
+	}
+	return fields
+}
@@ -0,0 +1,53 @@
+package vault
+
+import (
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors": ["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/sbi/registry":
+			w.Write([]byte(`{"data": {"data": {"harbor_username": "ci", "harbor_password": "secret", "port": 443}, "metadata": {"version": 3}}}`))
+		case "/v1/kv/sbi/registry":
+			w.Write([]byte(`{"data": {"nexus_username": "reader"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors": []}`))
+		}
+	}))
+	defer server.Close()
+
+	client := New(server.URL+"/", "s.token")
+	fields, err := client.ReadSecret("/secret/data/sbi/registry")
+	if err != nil {
+		t.Fatalf("ReadSecret() KV v2 error = %v", err)
+	}
+	if want := map[string]string{"harbor_username": "ci", "harbor_password": "secret"}; !maps.Equal(fields, want) {
+		t.Errorf("ReadSecret() KV v2 = %v, want %v", fields, want)
+	}
+
+	if fields, err = client.ReadSecret("kv/sbi/registry"); err != nil || fields["nexus_username"] != "reader" {
+		t.Errorf("ReadSecret() KV v1 = %v, %v", fields, err)
+	}
+
+	_, err = New(server.URL, "wrong").ReadSecret("secret/data/sbi/registry")
+	if err == nil || !strings.Contains(err.Error(), "HTTP 403") || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("ReadSecret() with a bad token error = %v", err)
+	}
+}
+
+func TestParseSecretRejectsInvalidJSON(t *testing.T) {
+	if _, err := parseSecret([]byte("<html>")); err == nil {
+		t.Errorf("parseSecret() accepted a non-JSON body")
+	}
+}
@@ -5,62 +5,281 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"sbi-deployment/internal/config"
 	"sbi-deployment/internal/deploy"
+	"sbi-deployment/internal/docker"
+	"sbi-deployment/internal/helm"
+	"sbi-deployment/internal/logging"
+	"sbi-deployment/internal/server"
+	"sbi-deployment/internal/utils"
 )
 
 const version = "1.0.0"
 
+// setFlags collects repeatable --set key=value flags
+type setFlags map[string]string
+
+func (s setFlags) String() string {
+	pairs := make([]string, 0, len(s))
+	for key, value := range s {
+		pairs = append(pairs, key+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (s setFlags) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	s[parts[0]] = parts[1]
+	return nil
+}
+
+// configFlags collects repeatable --config paths, merged in order
+type configFlags []string
+
+func (c *configFlags) String() string {
+	return strings.Join(*c, ",")
+}
+
+func (c *configFlags) Set(value string) error {
+	*c = append(*c, value)
+	return nil
+}
+
+// defaultConfigFile is used when no --config is given
+const defaultConfigFile = "./deployment.conf"
+
 func main() {
 	var (
-		imageTag     = flag.String("tag", "latest", "Image tag to deploy")
-		imageName    = flag.String("image", "", "Image name to deploy (default: derived from release name)")
-		configFile   = flag.String("config", "./deployment.conf", "Configuration file path")
+		imageTag     = flag.String("tag", "latest", "Image tag or sha256:<digest> to deploy; latest-semver deploys the newest semver release in Nexus")
+		imageName    = flag.String("image", "", "Image name to deploy, optionally with its tag as name:tag, which overrides --tag (default: derived from release name)")
 		showVersion  = flag.Bool("version", false, "Show version")
 		setupEnv     = flag.Bool("setup", false, "Run environment setup")
-		verbose      = flag.Bool("verbose", false, "Enable verbose logging")
+		verbose      = flag.Bool("verbose", false, "Enable verbose logging (alias for --log-level=debug)")
 		dryRun       = flag.Bool("dry-run", false, "Show what would be done without executing")
+		listenAddr   = flag.String("listen", ":8080", "Listen address for the serve subcommand")
+		watchWindow  = flag.Duration("repeat-until-healthy", 0, "Keep polling pod readiness for this long after deploy (e.g. 5m)")
+		watchLogs    = flag.Duration("watch-logs", 0, "Stream the release's pod logs for this long after a healthy rollout (e.g. 30s)")
+		watchEvery   = flag.Duration("watch-interval", 10*time.Second, "Interval between pod readiness polls in watch mode")
+		auditFile    = flag.String("audit", "", "Write a JSON audit log of intended commands to this file instead of executing")
+		branchNS     = flag.Bool("set-namespace-from-branch", false, "Deploy to a preview-<branch> namespace derived from the current git branch")
+		digestOut    = flag.String("image-digest-out", "", "Write the pushed Harbor image digest to this file")
+		outputEnv    = flag.String("output-env", "", "Append DEPLOYED_IMAGE, DEPLOYED_REVISION, ... KEY=value lines to this file (e.g. $GITHUB_ENV)")
+		noWait       = flag.Bool("no-wait", false, "Submit the helm upgrade without --wait/--atomic and skip the rollout checks (same as WAIT=false)")
+		skipSync     = flag.Bool("skip-sync", false, "Skip the Nexus to Harbor image sync and deploy an image already in Harbor")
+		environment  = flag.String("env", "", "Environment name; credentials are read from e.g. NEXUS_USERNAME_<ENV> first")
+		parallel     = flag.Bool("parallel-phases", false, "Pull a repository/OCI chart while the image sync runs")
+		kubectlDiff  = flag.Bool("kubectl-diff", false, "Print a kubectl diff of the rendered chart against the cluster before the upgrade (same as KUBECTL_DIFF=true)")
+		chartVersion = flag.String("chart-version", "", "Chart version to deploy for repository or OCI charts (overrides CHART_VERSION)")
+		cpuRequest   = flag.String("cpu-request", "", "Override resources.requests.cpu (e.g. 250m)")
+		cpuLimit     = flag.String("cpu-limit", "", "Override resources.limits.cpu (e.g. 1)")
+		memRequest   = flag.String("mem-request", "", "Override resources.requests.memory (e.g. 256Mi)")
+		memLimit     = flag.String("mem-limit", "", "Override resources.limits.memory (e.g. 512Mi)")
+		explain      = flag.Bool("explain", false, "Log how the image name, chart path, target image, and other derived values were chosen")
+		profile      = flag.String("profile", "", "Config [profile] section to apply over the top-level keys (e.g. staging)")
+		timeoutScale = flag.Float64("timeout-multiplier", 1, "Scale the helm, sync, and health watch timeouts (e.g. 2.5 for slow clusters)")
+		valuesCM     = flag.String("values-from-configmap", "", "Base helm values from a ConfigMap key in the release namespace, as <configmap>/<key> (overrides VALUES_CONFIGMAP)")
+		valuesJSON   = flag.String("values-json", "", `Inline JSON object of helm value overrides, applied as --set pairs (e.g. '{"replicaCount":3}')`)
+		maxClusters  = flag.Int("max-parallel-clusters", 0, "Deploy to at most this many KUBECONFIGS clusters at once (overrides MAX_PARALLEL_CLUSTERS)")
 	)
+	var logLevel string
+	flag.StringVar(&logLevel, "log-level", "info", "Log level: debug, info, warn or error")
+	flag.StringVar(&logLevel, "v", "info", "Shorthand for --log-level")
+	var configFiles configFlags
+	flag.Var(&configFiles, "config", "Configuration file path (repeatable; later files override earlier ones, default "+defaultConfigFile+")")
+	setValues := setFlags{}
+	flag.Var(setValues, "set", "Helm value override key=value (repeatable, overrides HELM_SET)")
 	flag.Parse()
+	if len(configFiles) == 0 {
+		configFiles = configFlags{defaultConfigFile}
+	}
 
 	if *showVersion {
 		fmt.Printf("SBI Deployment CLI v%s\n", version)
 		return
 	}
 
+	level, err := logging.ParseLevel(logLevel)
+	if err != nil {
+		log.Fatalf("Invalid log level: %v", err)
+	}
 	if *verbose {
+		level = logging.Debug
+	}
+	logging.SetLevel(level)
+	if level == logging.Debug {
 		log.SetOutput(os.Stdout)
 	}
 
+	// Subcommands that manage existing releases don't need a full configuration
+	switch flag.Arg(0) {
+	case "force-rollback":
+		runForceRollback(*profile, configFiles, flag.Args()[1:])
+		return
+	case "rollback-all":
+		runRollbackAll(*profile, configFiles, flag.Args()[1:])
+		return
+	case "config-diff":
+		runConfigDiff(*profile, flag.Args()[1:])
+		return
+	case "config-checksum":
+		runConfigChecksum(*profile, configFiles)
+		return
+	case "init":
+		runInit(configFiles[len(configFiles)-1], flag.Args()[1:])
+		return
+	case "doctor":
+		runDoctor(*profile, configFiles)
+		return
+	}
+
 	// Load configuration
-	cfg, err := config.LoadConfig(*configFile)
+	cfg, err := config.LoadConfig(*profile, configFiles...)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	deployer := deploy.New(cfg, *verbose, *dryRun)
+	// Catch config edits that skipped review, before command line overrides are applied
+	if _, err := cfg.VerifyChecksum(config.ChecksumPath(configFiles[len(configFiles)-1])); err != nil {
+		if cfg.StrictConfigChecksum {
+			log.Fatalf("Config checksum check failed: %v", err)
+		}
+		logging.Warnf("%v", err)
+	}
+
+	if *chartVersion != "" {
+		cfg.ChartVersion = *chartVersion
+	}
+	if *valuesCM != "" {
+		if _, _, err := helm.ParseConfigMapRef(*valuesCM); err != nil {
+			log.Fatalf("Invalid --values-from-configmap: %v", err)
+		}
+		if len(cfg.Kubeconfigs) > 0 {
+			log.Fatalf("--values-from-configmap cannot be combined with KUBECONFIGS")
+		}
+		cfg.ValuesConfigMap = *valuesCM
+	}
+	if *branchNS {
+		branch, err := utils.CurrentGitBranch()
+		if err != nil {
+			log.Fatalf("Failed to derive namespace from branch: %v", err)
+		}
+		cfg.Namespace = utils.PreviewNamespace(branch)
+		logging.Infof("Using preview namespace %s for branch %s", cfg.Namespace, branch)
+	}
+	if err := cfg.DefaultNamespace(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	cfg.Environment = *environment
+	// Explicit --set values win over --values-json, which wins over the resource convenience flags
+	cfg.MergeSetValues(helm.Resources{
+		CPURequest:    *cpuRequest,
+		CPULimit:      *cpuLimit,
+		MemoryRequest: *memRequest,
+		MemoryLimit:   *memLimit,
+	}.SetValues())
+	if *valuesJSON != "" {
+		jsonValues, err := helm.JSONToSetValues(*valuesJSON)
+		if err != nil {
+			log.Fatalf("Invalid --values-json: %v", err)
+		}
+		cfg.MergeSetValues(jsonValues)
+	}
+	cfg.MergeSetValues(setValues)
+	if *parallel {
+		cfg.ParallelPhases = true
+	}
+	if *kubectlDiff {
+		cfg.KubectlDiff = true
+	}
+	if *skipSync {
+		cfg.SkipSync = true
+	}
+	if *noWait {
+		cfg.Wait = false
+	}
+	cfg.AuditFile = *auditFile
+	cfg.Explain = *explain
+	cfg.DigestOutFile = *digestOut
+	cfg.OutputEnvFile = *outputEnv
+	cfg.WatchWindow = *watchWindow
+	cfg.WatchInterval = *watchEvery
+	cfg.WatchLogs = *watchLogs
+	if *maxClusters != 0 {
+		if *maxClusters < 1 {
+			log.Fatalf("Invalid --max-parallel-clusters: must be at least 1, got %d", *maxClusters)
+		}
+		cfg.MaxParallelClusters = *maxClusters
+	}
+	if *timeoutScale != 1 {
+		if err := cfg.ScaleTimeouts(*timeoutScale); err != nil {
+			log.Fatalf("Invalid --timeout-multiplier: %v", err)
+		}
+		logging.Infof("Scaled timeouts by %g (helm timeout %ds)", *timeoutScale, cfg.Timeout)
+	}
+
+	// --image=team/app:1.2.3 carries its own tag, which wins over --tag
+	name, tag, err := docker.SplitImageArg(*imageName)
+	if err != nil {
+		log.Fatalf("Invalid --image: %v", err)
+	}
+	if name != *imageName {
+		if tag == "" {
+			log.Fatalf("Invalid --image %q: missing tag after ':' or '@'", *imageName)
+		}
+		tagSet := false
+		flag.Visit(func(f *flag.Flag) { tagSet = tagSet || f.Name == "tag" })
+		if tagSet && *imageTag != tag {
+			logging.Warnf("Using tag %s from --image, ignoring --tag=%s", tag, *imageTag)
+		}
+		*imageName, *imageTag = name, tag
+	}
+
+	deployer := deploy.New(cfg, *dryRun)
 
 	if *setupEnv {
-		log.Println("Setting up environment...")
+		logging.Infof("Setting up environment...")
 		if err := deployer.SetupEnvironment(); err != nil {
 			log.Fatalf("Environment setup failed: %v", err)
 		}
-		log.Println("Environment setup completed successfully")
+		logging.Infof("Environment setup completed successfully")
 		return
 	}
 
 	// Get credentials from environment or prompt
-	credentials, err := deployer.GetCredentials()
+	credentials, err := deployer.GetCredentials(*imageTag)
 	if err != nil {
 		log.Fatalf("Failed to get credentials: %v", err)
 	}
 
+	if flag.Arg(0) == "serve" {
+		srv := server.New(deployer, credentials, cfg.ServerSecret)
+		if err := srv.ListenAndServe(*listenAddr); err != nil {
+			log.Fatalf("Deploy server failed: %v", err)
+		}
+		return
+	}
+
+	if flag.Arg(0) == "canary" {
+		runCanary(deployer, *imageTag, *imageName, credentials, flag.Args()[1:])
+		return
+	}
+
+	if flag.Arg(0) == "blue-green" {
+		runBlueGreen(deployer, *imageTag, *imageName, credentials)
+		return
+	}
+
 	// Run deployment
-	log.Printf("Starting deployment for image tag: %s", *imageTag)
-	if err := deployer.Deploy(*imageTag, *imageName, credentials); err != nil {
+	logging.Infof("Starting deployment for image tag: %s", *imageTag)
+	if _, err := deployer.Deploy(*imageTag, *imageName, credentials); err != nil {
 		log.Fatalf("Deployment failed: %v", err)
 	}
 
-	log.Println("Deployment completed successfully")
-}
\ No newline at end of file
+	logging.Infof("Deployment completed successfully")
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestSetFlags(t *testing.T) {
+	values := setFlags{}
+	for _, arg := range []string{"replicaCount=3", "env=a=b", "replicaCount=4"} {
+		if err := values.Set(arg); err != nil {
+			t.Fatalf("Set(%q) error = %v", arg, err)
+		}
+	}
+	if want := (setFlags{"replicaCount": "4", "env": "a=b"}); !maps.Equal(values, want) {
+		t.Errorf("values = %v, want %v", values, want)
+	}
+
+	for _, arg := range []string{"replicaCount", "=3"} {
+		if err := values.Set(arg); err == nil {
+			t.Errorf("Set(%q) accepted a malformed override", arg)
+		}
+	}
+}
+
+func TestConfigFlags(t *testing.T) {
+	var files configFlags
+	for _, path := range []string{"base.conf", "prod.conf"} {
+		if err := files.Set(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !slices.Equal(files, configFlags{"base.conf", "prod.conf"}) || files.String() != "base.conf,prod.conf" {
+		t.Errorf("files = %q, want both paths in order", files)
+	}
+}